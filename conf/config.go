@@ -131,6 +131,7 @@ func (this *TopConfig) getPropBool(propname string) bool {
 
 // 初始化命令行参数
 func (this *TopConfig) InitParse() {
+	parseCmdLine()
 	if this.globalProp == nil {
 		this.globalProp = make(map[string]string)
 	}
@@ -167,24 +168,37 @@ func (this *TopConfig) InitParse() {
 // 命令行参数列表
 var cmdLineArgv map[string]string
 
-// 读取命令行参数
+// 命令行参数的值
+var (
+	daemonflag    string
+	processflag   string
+	logpathflag   string
+	serverversion string
+
+	parseCmdLineOnce sync.Once
+)
+
+// 注册命令行参数
 func init() {
-	if cmdLineArgv == nil {
-		cmdLineArgv = make(map[string]string)
-	}
-	var daemonflag string
 	flag.StringVar(&daemonflag, "d", "", "as a daemon true or false")
-
-	var processflag string
 	flag.StringVar(&processflag, "p", "", "process id as gate001")
-
-	var logpathflag string
 	flag.StringVar(&logpathflag, "l", "", "log path as /log/")
-
-	var serverversion string
 	flag.StringVar(&serverversion, "v", "", "server version as [0-9]{14}")
+}
+
+// 读取命令行参数，在第一次加载配置时执行，而不是在包初始化时执行，
+// 使引用了本包的程序（例如测试程序）可以在解析前注册自己的命令行参数
+func parseCmdLine() {
+	parseCmdLineOnce.Do(doParseCmdLine)
+}
 
-	flag.Parse()
+func doParseCmdLine() {
+	if cmdLineArgv == nil {
+		cmdLineArgv = make(map[string]string)
+	}
+	if !flag.Parsed() {
+		flag.Parse()
+	}
 
 	if len(daemonflag) > 0 {
 		if daemonflag == "true" {
@@ -25,4 +25,42 @@ var (
 	MsgThreadNum ConfigKey = "msgthreadnum"
 	// ROC的绑定是否使用异步方式同步到别的module中，会与ROC调用有异步问题 bool
 	AsynchronousSyncRocbind ConfigKey = "asynchronous_sync_rocbind"
+	// ROC阻塞调用的默认超时时间，单位毫秒，不大于0时不超时。ROC对象在处理调用时
+	// 阻塞调用自身或形成循环的阻塞调用时，只能在超时后结束		int
+	ROCCallTimeout ConfigKey = "roc_call_timeout"
+	// ROC调用处理协程数量，不大于0时为16		int
+	ROCWorkerNum ConfigKey = "roc_worker_num"
+	// ROC对象快照保存的本地目录，设置后启用ROC对象的持久化		string
+	ROCPersistPath ConfigKey = "roc_persist_path"
+	// ROC对象定时快照的间隔，单位秒，不大于0时只在模块停止时快照		int
+	ROCSnapshotInterval ConfigKey = "roc_snapshot_interval"
+	// ROC对象快照等待对象邮箱的超时时间，单位毫秒，不大于0时为10000，
+	// 超时后保存已取得状态的对象		int
+	ROCSnapshotTimeout ConfigKey = "roc_snapshot_timeout"
+	// 查询后确认不存在的ROC对象的缓存时间，单位毫秒，不大于0时为1000		int
+	ROCLocateMissTTL ConfigKey = "roc_locate_miss_ttl"
+	// 无返回值的ROC调用查询目标对象位置的超时时间，单位毫秒，不大于0时为5000，
+	// 配置了 ROCCallTimeout 且更短时使用 ROCCallTimeout		int
+	ROCLocateTimeout ConfigKey = "roc_locate_timeout"
+	// ROC流式调用中调用方的接收窗口，即被调用方最多可以发送的未确认帧数，
+	// 不大于0时为16		int
+	ROCStreamWindow ConfigKey = "roc_stream_window"
+	// 按幂等键去重ROC请求时保存执行结果的时间，单位毫秒，不大于0时为60000		int
+	ROCDedupWindow ConfigKey = "roc_dedup_window"
+	// 按幂等键去重ROC请求时最多保存的执行结果数量，不大于0时为10000		int
+	ROCDedupSize ConfigKey = "roc_dedup_size"
+	// 按需激活的ROC对象空闲多久后被停用，单位秒，不大于0时不停用，
+	// 见 roc.ROC.SetObjFactory		int
+	ROCIdleTTL ConfigKey = "roc_idle_ttl"
+	// ROC对象一致性哈希放置中每个模块的虚拟节点数，不大于0时为160		int
+	ROCPlacementReplicas ConfigKey = "roc_placement_replicas"
+	// ROC管理接口的HTTP监听地址，如 127.0.0.1:8080 ，设置后可以通过 /roc/stats
+	// 查询子网中ROC对象的统计，通过 /metrics 获取指标		string
+	ROCAdminAddr ConfigKey = "roc_admin_addr"
+	// ROC调用参数及返回值的最大字节数，超过单个消息大小上限的参数及返回值将被分片
+	// 传输，不大于0时为64MiB		int
+	ROCMaxPayloadSize ConfigKey = "roc_max_payload_size"
+	// 调用链追踪片段输出的文件，设置后启用调用链追踪，同一进程的模块共用第一个
+	// 设置的文件		string
+	TraceFile ConfigKey = "trace_file"
 )
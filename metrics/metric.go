@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 默认的直方图桶上界，单位秒，适用于调用耗时
+var DefBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1,
+	.25, .5, 1, 2.5, 5, 10}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// 单调递增的计数器
+type Counter struct {
+	value uint64
+}
+
+func (this *Counter) Kind() Kind {
+	return KindCounter
+}
+
+// 计数加一
+func (this *Counter) Inc() {
+	atomic.AddUint64(&this.value, 1)
+}
+
+// 计数增加 n
+func (this *Counter) Add(n uint64) {
+	atomic.AddUint64(&this.value, n)
+}
+
+// 获取当前计数
+func (this *Counter) Value() uint64 {
+	return atomic.LoadUint64(&this.value)
+}
+
+func (this *Counter) writeText(w io.Writer, name string, labels Labels) {
+	fmt.Fprintf(w, "%s%s %d\n", name, labels, this.Value())
+}
+
+// 可增可减的仪表
+type Gauge struct {
+	value int64
+}
+
+func (this *Gauge) Kind() Kind {
+	return KindGauge
+}
+
+// 增加 n ， n 可以为负数
+func (this *Gauge) Add(n int64) {
+	atomic.AddInt64(&this.value, n)
+}
+
+// 设置当前值
+func (this *Gauge) Set(n int64) {
+	atomic.StoreInt64(&this.value, n)
+}
+
+// 获取当前值
+func (this *Gauge) Value() int64 {
+	return atomic.LoadInt64(&this.value)
+}
+
+func (this *Gauge) writeText(w io.Writer, name string, labels Labels) {
+	fmt.Fprintf(w, "%s%s %d\n", name, labels, this.Value())
+}
+
+// 在导出时取值的仪表
+type gaugeFunc struct {
+	f func() float64
+}
+
+func (this *gaugeFunc) Kind() Kind {
+	return KindGauge
+}
+
+func (this *gaugeFunc) writeText(w io.Writer, name string, labels Labels) {
+	fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(this.f()))
+}
+
+// 直方图，记录样本落入各个桶的数量及样本总和
+type Histogram struct {
+	buckets []float64
+	// 每个桶的样本数，最后一个为 +Inf 桶
+	counts []uint64
+	count  uint64
+	sum    float64
+	mutex  sync.Mutex
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	b := make([]float64, len(buckets))
+	copy(b, buckets)
+	sort.Float64s(b)
+	return &Histogram{
+		buckets: b,
+		counts:  make([]uint64, len(b)+1),
+	}
+}
+
+func (this *Histogram) Kind() Kind {
+	return KindHistogram
+}
+
+// 记录一个样本
+func (this *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(this.buckets, v)
+	this.mutex.Lock()
+	this.counts[i]++
+	this.count++
+	this.sum += v
+	this.mutex.Unlock()
+}
+
+// 以秒为单位记录一个耗时
+func (this *Histogram) ObserveDuration(d time.Duration) {
+	this.Observe(d.Seconds())
+}
+
+// 获取样本数量及样本总和
+func (this *Histogram) Snapshot() (count uint64, sum float64) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.count, this.sum
+}
+
+func (this *Histogram) writeText(w io.Writer, name string, labels Labels) {
+	this.mutex.Lock()
+	counts := make([]uint64, len(this.counts))
+	copy(counts, this.counts)
+	count, sum := this.count, this.sum
+	this.mutex.Unlock()
+
+	bucketLabels := make(Labels, len(labels)+1)
+	for k, v := range labels {
+		bucketLabels[k] = v
+	}
+	var cumulative uint64
+	for i, c := range counts {
+		cumulative += c
+		le := math.Inf(1)
+		if i < len(this.buckets) {
+			le = this.buckets[i]
+		}
+		bucketLabels["le"] = formatFloat(le)
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, bucketLabels, cumulative)
+	}
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, labels, formatFloat(sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels, count)
+}
@@ -0,0 +1,232 @@
+/*
+进程内的指标注册表，框架及用户代码可以在其中注册计数器、仪表及直方图，
+并以 Prometheus 文本格式导出。
+*/
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 指标的标签
+type Labels map[string]string
+
+// 标签按键排序后的文本描述，同时作为同名指标中区分不同标签的键
+func (this Labels) String() string {
+	if len(this) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(this))
+	for k := range this {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		v := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).
+			Replace(this[k])
+		pairs[i] = fmt.Sprintf(`%s="%s"`, k, v)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// 指标的类型
+type Kind string
+
+const (
+	KindCounter   Kind = "counter"
+	KindGauge     Kind = "gauge"
+	KindHistogram Kind = "histogram"
+)
+
+// 一个指标
+type Metric interface {
+	Kind() Kind
+	// 以 Prometheus 文本格式写出该指标的所有样本
+	writeText(w io.Writer, name string, labels Labels)
+}
+
+// 同名的一组指标
+type family struct {
+	name    string
+	help    string
+	kind    Kind
+	metrics map[string]*entry
+}
+
+type entry struct {
+	labels Labels
+	metric Metric
+}
+
+// 指标注册表
+type Registry struct {
+	families map[string]*family
+	mutex    sync.RWMutex
+}
+
+var _gRegistry = NewRegistry()
+
+// 获取本进程默认的指标注册表
+func GetRegistry() *Registry {
+	return _gRegistry
+}
+
+// 构造一个空的指标注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		families: make(map[string]*family),
+	}
+}
+
+// 获取或注册目标指标，同名的指标必须具有相同的类型
+func (this *Registry) getOrRegister(name string, help string, kind Kind,
+	labels Labels, newMetric func() Metric) Metric {
+	key := labels.String()
+	this.mutex.RLock()
+	if f, ok := this.families[name]; ok {
+		if e, ok := f.metrics[key]; ok {
+			this.mutex.RUnlock()
+			return e.metric
+		}
+	}
+	this.mutex.RUnlock()
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	f, ok := this.families[name]
+	if !ok {
+		f = &family{
+			name:    name,
+			help:    help,
+			kind:    kind,
+			metrics: make(map[string]*entry),
+		}
+		this.families[name] = f
+	}
+	if f.kind != kind {
+		panic(fmt.Sprintf("metrics: %s registered as %s, not %s",
+			name, f.kind, kind))
+	}
+	if e, ok := f.metrics[key]; ok {
+		return e.metric
+	}
+	copied := make(Labels, len(labels))
+	for k, v := range labels {
+		copied[k] = v
+	}
+	m := newMetric()
+	f.metrics[key] = &entry{
+		labels: copied,
+		metric: m,
+	}
+	return m
+}
+
+// 获取或注册一个计数器
+func (this *Registry) Counter(name string, help string,
+	labels Labels) *Counter {
+	return this.getOrRegister(name, help, KindCounter, labels,
+		func() Metric {
+			return &Counter{}
+		}).(*Counter)
+}
+
+// 获取或注册一个仪表
+func (this *Registry) Gauge(name string, help string, labels Labels) *Gauge {
+	return this.getOrRegister(name, help, KindGauge, labels,
+		func() Metric {
+			return &Gauge{}
+		}).(*Gauge)
+}
+
+// 获取或注册一个在导出时通过 f 取值的仪表，已注册时不会替换原本的 f
+func (this *Registry) GaugeFunc(name string, help string, labels Labels,
+	f func() float64) {
+	this.getOrRegister(name, help, KindGauge, labels, func() Metric {
+		return &gaugeFunc{
+			f: f,
+		}
+	})
+}
+
+// 获取或注册一个直方图，buckets 为递增的桶上界，为 nil 时使用 DefBuckets
+func (this *Registry) Histogram(name string, help string, labels Labels,
+	buckets []float64) *Histogram {
+	return this.getOrRegister(name, help, KindHistogram, labels,
+		func() Metric {
+			return newHistogram(buckets)
+		}).(*Histogram)
+}
+
+// 遍历所有指标
+func (this *Registry) Range(f func(name string, labels Labels,
+	m Metric) bool) {
+	this.mutex.RLock()
+	entries := make([]struct {
+		name string
+		e    *entry
+	}, 0)
+	for name, family := range this.families {
+		for _, e := range family.metrics {
+			entries = append(entries, struct {
+				name string
+				e    *entry
+			}{name, e})
+		}
+	}
+	this.mutex.RUnlock()
+	for _, v := range entries {
+		if !f(v.name, v.e.labels, v.e.metric) {
+			return
+		}
+	}
+}
+
+// 以 Prometheus 文本格式写出所有指标
+func (this *Registry) WriteText(w io.Writer) {
+	this.mutex.RLock()
+	names := make([]string, 0, len(this.families))
+	for name := range this.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	type item struct {
+		key string
+		e   *entry
+	}
+	families := make([]*family, len(names))
+	items := make([][]item, len(names))
+	for i, name := range names {
+		f := this.families[name]
+		families[i] = f
+		for key, e := range f.metrics {
+			items[i] = append(items[i], item{key, e})
+		}
+		sort.Slice(items[i], func(a, b int) bool {
+			return items[i][a].key < items[i][b].key
+		})
+	}
+	this.mutex.RUnlock()
+
+	for i, f := range families {
+		if f.help != "" {
+			fmt.Fprintf(w, "# HELP %s %s\n", f.name, f.help)
+		}
+		fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+		for _, it := range items[i] {
+			it.e.metric.writeText(w, f.name, it.e.labels)
+		}
+	}
+}
+
+// 实现 http.Handler ，以 Prometheus 文本格式返回所有指标
+func (this *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	this.WriteText(w)
+}
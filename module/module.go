@@ -25,13 +25,16 @@
 package module
 
 import (
+	"context"
 	"time"
 
 	"github.com/liasece/micserver/base"
 	"github.com/liasece/micserver/conf"
 	"github.com/liasece/micserver/log"
 	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/roc/persist"
 	"github.com/liasece/micserver/server"
+	"github.com/liasece/micserver/trace"
 	"github.com/liasece/micserver/util"
 	"github.com/liasece/micserver/util/hash"
 	"github.com/liasece/micserver/util/monitor"
@@ -51,6 +54,8 @@ type IModule interface {
 	GetConfiger() *conf.ModuleConfig
 	ROCCallNR(callpath *roc.ROCPath, callarg []byte) error
 	ROCCallBlock(callpath *roc.ROCPath, callarg []byte) ([]byte, error)
+	ROCCallContext(ctx context.Context, callpath *roc.ROCPath,
+		callarg []byte) ([]byte, error)
 }
 
 // 基础模块
@@ -96,7 +101,41 @@ func (this *BaseModule) InitModule(configer conf.ModuleConfig) {
 		this.Server.InitGate(gateaddr)
 	}
 
+	// ROC对象持久化初始化
+	if path := this.configer.GetString(conf.ROCPersistPath); path != "" {
+		storage, err := persist.NewFileStorage(path)
+		if err != nil {
+			this.Error("[BaseModule.InitModule] NewFileStorage(%s) err:%s",
+				path, err.Error())
+		} else {
+			this.Server.SetROCStorage(storage)
+		}
+	}
+
+	// 调用链追踪初始化
+	if path := this.configer.GetString(conf.TraceFile); path != "" &&
+		trace.GetExporter() == nil {
+		exporter, err := trace.NewFileExporter(path)
+		if err != nil {
+			this.Error("[BaseModule.InitModule] NewFileExporter(%s) err:%s",
+				path, err.Error())
+		} else {
+			trace.SetExporter(exporter)
+		}
+	}
+
+	// ROC管理接口初始化
+	if addr := this.configer.GetString(conf.ROCAdminAddr); addr != "" {
+		if err := this.Server.StartROCAdmin(addr); err != nil {
+			this.Error("[BaseModule.InitModule] StartROCAdmin(%s) err:%s",
+				addr, err.Error())
+		}
+	}
+
 	this.RegTimer(time.Second*5, 0, false, this.watchLoadToLog)
+	if this.configer.GetInt64(conf.ROCIdleTTL) > 0 {
+		this.RegTimer(time.Second, 0, false, this.deactivateIdleROCObj)
+	}
 }
 
 // 在初始化完成后调用
@@ -105,6 +144,41 @@ func (this *BaseModule) AfterInitModule() {
 		this.GetModuleID())
 }
 
+// 连接子网中的其他模块，连接前从持久化存储中恢复本模块的ROC对象，
+// 恢复完成后才会向其他模块发送ROC对象绑定信息
+func (this *BaseModule) BindSubnet(subnetAddrMap map[string]string) {
+	if this.Server.GetROCStorage() != nil {
+		if err := this.Server.RestoreROCObj(); err != nil {
+			this.Error("[BaseModule.BindSubnet] RestoreROCObj err:%s",
+				err.Error())
+		}
+		if err := this.Server.RestoreROCReminder(); err != nil {
+			this.Error("[BaseModule.BindSubnet] RestoreROCReminder err:%s",
+				err.Error())
+		}
+		if interval := this.configer.GetInt64(
+			conf.ROCSnapshotInterval); interval > 0 {
+			this.RegTimer(time.Duration(interval)*time.Second, 0, false,
+				this.snapshotROCObj)
+		}
+	}
+	this.Server.BindSubnet(subnetAddrMap)
+}
+
+// 定时保存ROC对象快照
+func (this *BaseModule) snapshotROCObj(dt time.Duration) bool {
+	if err := this.Server.SnapshotROCObj(); err != nil {
+		this.Error("[BaseModule] SnapshotROCObj err:%s", err.Error())
+	}
+	return true
+}
+
+// 定时停用空闲的按需激活的ROC对象
+func (this *BaseModule) deactivateIdleROCObj(dt time.Duration) bool {
+	this.Server.DeactivateIdleROCObj()
+	return true
+}
+
 // 获取模块的配置
 func (this *BaseModule) GetConfiger() *conf.ModuleConfig {
 	return this.configer
@@ -147,6 +221,11 @@ func (this *BaseModule) GenUniqueID() (string, error) {
 // 当模块被中止时调用
 func (this *BaseModule) KillModule() {
 	this.Syslog("[BaseModule] Killing module...")
+	// 停止前保存ROC对象快照
+	if err := this.Server.SnapshotROCObj(); err != nil {
+		this.Error("[BaseModule.KillModule] SnapshotROCObj err:%s",
+			err.Error())
+	}
 	this.Server.Stop()
 	this.hasKilledModule = true
 	this.KillRegister()
@@ -18,8 +18,28 @@ func AddModule(module base.IModule) {
 	_gModules.Store(module.GetModuleID(), module)
 }
 
+// 获取本进程中的目标 Module ，不存在时返回 nil
+func GetModule(moduleID string) base.IModule {
+	if vi, ok := _gModules.Load(moduleID); ok {
+		if v, ok := vi.(base.IModule); ok {
+			return v
+		}
+	}
+	return nil
+}
+
 // 判断目标 Module 是否在本进程中
 func HasModule(moduleID string) bool {
 	_, ok := _gModules.Load(moduleID)
 	return ok
 }
+
+// 遍历本进程中所有的 Module ，f 返回 false 时停止遍历
+func RangeModule(f func(module base.IModule) bool) {
+	_gModules.Range(func(ki, vi interface{}) bool {
+		if v, ok := vi.(base.IModule); ok {
+			return f(v)
+		}
+		return true
+	})
+}
@@ -0,0 +1,32 @@
+package roc
+
+import (
+	"github.com/liasece/micserver/util/hash"
+)
+
+// ROC对象工厂，按需激活对象时根据对象ID构造一个ROC对象
+type ObjFactory func(objID string) (IObj, error)
+
+// 按需激活的ROC对象可以实现的接口，对象被工厂构造后、注册前调用，
+// 可以在其中加载对象的状态，返回错误时激活失败，错误将返回给调用方
+type Activatable interface {
+	OnActivate() error
+}
+
+// 按需激活的ROC对象可以实现的接口，对象空闲超时被停用时、从ROC中删除前调用，
+// 可以在其中保存对象的状态
+type Deactivatable interface {
+	OnDeactivate()
+}
+
+// 按需激活的放置规则，从可以激活该类型对象的模块 candidates 中为对象选择激活的
+// 模块，candidates 按模块ID排序，返回空字符串时不激活
+type PlacementFunc func(objID string, candidates []string) string
+
+// 默认的放置规则，按对象ID的哈希值选择模块
+func PlaceByHash(objID string, candidates []string) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+	return candidates[hash.GetStringHash(objID)%uint32(len(candidates))]
+}
@@ -6,6 +6,7 @@ package roc
 import (
 	"math/rand"
 	"sync"
+	"time"
 )
 
 // ROC缓存分组数量
@@ -15,16 +16,40 @@ const (
 
 type catchServerInfo struct {
 	moduleid string
+	// 与该模块的连接已断开，在重新同步之前，该模块上的对象不会被查询到
+	suspect bool
+	// 最后收到的该模块ROC绑定信息的版本
+	epoch   uint64
+	version uint64
+}
+
+// 缓存的一个对象的位置
+type catchObjInfo struct {
+	server *catchServerInfo
+	// 对象所有权的围栏令牌，令牌越大的注册越新，为0表示注册时没有提供令牌
+	token uint64
+}
+
+// 判断目标模块以令牌 token 注册的对象能否替换当前的记录。同一模块的注册总是生效，
+// 不同模块之间令牌较大的注册生效，令牌相同时模块ID较小的注册生效，
+// 因此无论绑定信息以何种顺序到达，所有模块都会得到相同的结果
+func (this *catchObjInfo) acceptable(moduleid string, token uint64) bool {
+	if this.server.moduleid == moduleid || token > this.token {
+		return true
+	}
+	return token == this.token && moduleid < this.server.moduleid
 }
 
 type serverInfoMap map[string]*catchServerInfo
-type objIDToServerMap map[string]*catchServerInfo
+type objIDToServerMap map[string]*catchObjInfo
 
 // ROC缓存管理器
 type Cache struct {
 	catchServer serverInfoMap
 	catchType   map[ROCObjType]objIDToServerMap
-	mutex       sync.Mutex
+	// 查询后确认不存在的对象，及该结果的过期时间
+	missType map[ROCObjType]map[string]time.Time
+	mutex    sync.Mutex
 }
 
 var _gCache Cache
@@ -62,26 +87,77 @@ func (this *Cache) catchGetServerMust(moduleid string) *catchServerInfo {
 	}
 }
 
-// 添加目标对象
+// 按围栏令牌的规则记录对象的位置，调用方需要持有锁
+func (this *Cache) setNoLock(objType ROCObjType, objID string,
+	moduleid string, token uint64) bool {
+	m := this.catchGetTypeMust(objType)
+	if info, ok := m[objID]; ok && !info.acceptable(moduleid, token) {
+		return false
+	}
+	m[objID] = &catchObjInfo{
+		server: this.catchGetServerMust(moduleid),
+		token:  token,
+	}
+	delete(this.missType[objType], objID)
+	return true
+}
+
+// 添加目标对象，不携带令牌，不会替换其他模块携带令牌的注册
 func (this *Cache) Set(objType ROCObjType, objID string, moduleid string) {
+	this.SetToken(objType, objID, moduleid, 0)
+}
+
+// 同时添加多个
+func (this *Cache) SetM(objType ROCObjType, objIDs []string, moduleid string) {
+	this.SetMToken(objType, objIDs, nil, moduleid)
+}
+
+// 添加目标对象及其围栏令牌，与已记录的其他模块的注册冲突并且落败时返回 false
+func (this *Cache) SetToken(objType ROCObjType, objID string,
+	moduleid string, token uint64) bool {
 	this.mutex.Lock()
 	defer this.mutex.Unlock()
 
-	m := this.catchGetTypeMust(objType)
-	server := this.catchGetServerMust(moduleid)
-	m[objID] = server
+	return this.setNoLock(objType, objID, moduleid, token)
 }
 
-// 同时添加多个
-func (this *Cache) SetM(objType ROCObjType, objIDs []string, moduleid string) {
+// 同时添加多个，tokens 与 objIDs 一一对应，缺少的令牌视为0，
+// 返回冲突并且落败的对象ID
+func (this *Cache) SetMToken(objType ROCObjType, objIDs []string,
+	tokens []uint64, moduleid string) []string {
 	this.mutex.Lock()
 	defer this.mutex.Unlock()
 
-	m := this.catchGetTypeMust(objType)
-	server := this.catchGetServerMust(moduleid)
-	for _, v := range objIDs {
-		m[v] = server
+	var rejected []string
+	for i, v := range objIDs {
+		token := uint64(0)
+		if i < len(tokens) {
+			token = tokens[i]
+		}
+		if !this.setNoLock(objType, v, moduleid, token) {
+			rejected = append(rejected, v)
+		}
 	}
+	return rejected
+}
+
+// 将目标对象注册到目标模块，取得一个大于本进程所知的该对象任何注册、
+// 并且不小于 minToken 的令牌，该注册总是生效
+func (this *Cache) Acquire(objType ROCObjType, objID string,
+	moduleid string, minToken uint64) uint64 {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	token := minToken
+	if info, ok := this.catchGetTypeMust(objType)[objID]; ok &&
+		info.token >= token {
+		token = info.token + 1
+	}
+	if token == 0 {
+		token = 1
+	}
+	this.setNoLock(objType, objID, moduleid, token)
+	return token
 }
 
 // 删除目标对象
@@ -90,7 +166,7 @@ func (this *Cache) Del(objType ROCObjType, objID string, moduleid string) {
 	defer this.mutex.Unlock()
 
 	m := this.catchGetTypeMust(objType)
-	if info, ok := m[objID]; ok && info.moduleid == moduleid {
+	if info, ok := m[objID]; ok && info.server.moduleid == moduleid {
 		delete(m, objID)
 	}
 }
@@ -102,7 +178,7 @@ func (this *Cache) DelM(objType ROCObjType, objIDs []string, moduleid string) {
 
 	m := this.catchGetTypeMust(objType)
 	for _, v := range objIDs {
-		if info, ok := m[v]; ok && info.moduleid == moduleid {
+		if info, ok := m[v]; ok && info.server.moduleid == moduleid {
 			delete(m, v)
 		}
 	}
@@ -110,14 +186,134 @@ func (this *Cache) DelM(objType ROCObjType, objIDs []string, moduleid string) {
 
 // 获取缓存的目标对象在哪个模块上
 func (this *Cache) Get(objType ROCObjType, objID string) string {
+	moduleid, _ := this.GetToken(objType, objID)
+	return moduleid
+}
+
+// 获取缓存的目标对象在哪个模块上，以及该注册的围栏令牌
+func (this *Cache) GetToken(objType ROCObjType, objID string) (string,
+	uint64) {
 	this.mutex.Lock()
 	defer this.mutex.Unlock()
 
 	m := this.catchGetTypeMust(objType)
-	if v, ok := m[objID]; ok && v != nil {
-		return v.moduleid
+	if v, ok := m[objID]; ok && v != nil && !v.server.suspect {
+		return v.server.moduleid, v.token
 	}
-	return ""
+	return "", 0
+}
+
+// 记录目标对象不存在，在 ttl 时间内查询该对象位置时将直接得到不存在的结果，
+// 该对象被添加到缓存中时记录被清除
+func (this *Cache) SetMiss(objType ROCObjType, objID string,
+	ttl time.Duration) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if this.missType == nil {
+		this.missType = make(map[ROCObjType]map[string]time.Time)
+	}
+	m, ok := this.missType[objType]
+	if !ok {
+		m = make(map[string]time.Time)
+		this.missType[objType] = m
+	}
+	m[objID] = time.Now().Add(ttl)
+}
+
+// 目标对象是否在近期被确认不存在
+func (this *Cache) IsMiss(objType ROCObjType, objID string) bool {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	m := this.missType[objType]
+	expire, ok := m[objID]
+	if !ok {
+		return false
+	}
+	if time.Now().After(expire) {
+		delete(m, objID)
+		return false
+	}
+	return true
+}
+
+// 设置目标模块是否可疑，与目标模块的连接断开时，该模块上的对象将不会被查询到，
+// 直到重新同步完成
+func (this *Cache) SetModuleSuspect(moduleid string, suspect bool) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	this.catchGetServerMust(moduleid).suspect = suspect
+}
+
+// 获取最后收到的目标模块ROC绑定信息的版本
+func (this *Cache) GetModuleVersion(moduleid string) (uint64, uint64) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	server := this.catchGetServerMust(moduleid)
+	return server.epoch, server.version
+}
+
+// 记录收到的目标模块ROC绑定信息的版本
+func (this *Cache) SetModuleVersion(moduleid string, epoch uint64,
+	version uint64) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	server := this.catchGetServerMust(moduleid)
+	server.epoch = epoch
+	server.version = version
+}
+
+// 按顺序推进最后收到的目标模块ROC绑定信息的版本，已收到过的版本会被忽略，
+// 纪元不同或者版本不连续时返回 false ，此时需要与目标模块重新同步
+func (this *Cache) AdvanceModuleVersion(moduleid string, epoch uint64,
+	version uint64) bool {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	server := this.catchGetServerMust(moduleid)
+	if server.epoch != epoch || version > server.version+1 {
+		return false
+	}
+	if version == server.version+1 {
+		server.version = version
+	}
+	return true
+}
+
+// 删除目标模块上所有不在 keep 中的对象
+func (this *Cache) Retain(moduleid string,
+	keep map[ROCObjType]map[string]struct{}) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	for objType, m := range this.catchType {
+		keepIDs := keep[objType]
+		for id, info := range m {
+			if info.server.moduleid != moduleid {
+				continue
+			}
+			if _, ok := keepIDs[id]; !ok {
+				delete(m, id)
+			}
+		}
+	}
+}
+
+// 获取所有缓存了对象的类型
+func (this *Cache) GetTypes() []ROCObjType {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	res := make([]ROCObjType, 0, len(this.catchType))
+	for objType, m := range this.catchType {
+		if len(m) > 0 {
+			res = append(res, objType)
+		}
+	}
+	return res
 }
 
 // 遍历指定类型的ROC对象
@@ -130,14 +326,17 @@ func (this *Cache) RangeByType(objType ROCObjType,
 	this.mutex.Lock()
 	m := this.catchGetTypeMust(objType)
 	for id, v := range m {
-		if limitModuleIDs == nil || limitModuleIDs[v.moduleid] == true {
+		if v.server.suspect {
+			continue
+		}
+		if limitModuleIDs == nil || limitModuleIDs[v.server.moduleid] == true {
 			back[id] = v
 		}
 	}
 	this.mutex.Unlock()
 
 	for id, v := range back {
-		if !f(id, v.moduleid) {
+		if !f(id, v.server.moduleid) {
 			break
 		}
 	}
@@ -153,7 +352,10 @@ func (this *Cache) RandomObjIDByType(objType ROCObjType,
 	m := this.catchGetTypeMust(objType)
 	tmplist := make([]string, 0)
 	for id, v := range m {
-		if limitModuleIDs == nil || limitModuleIDs[v.moduleid] == true {
+		if v.server.suspect {
+			continue
+		}
+		if limitModuleIDs == nil || limitModuleIDs[v.server.moduleid] == true {
 			tmplist = append(tmplist, id)
 		}
 	}
@@ -0,0 +1,166 @@
+package roc
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ROC错误码，ROC调用的错误跨模块传递时，通过错误码还原错误的身份
+type ErrCode int32
+
+// 框架内置的错误码，用户自定义的错误码应不小于 ErrCodeUserBegin
+const (
+	ErrCodeOK              ErrCode = 0
+	ErrCodeUnknown         ErrCode = 1
+	ErrCodeUnregisterROC   ErrCode = 2
+	ErrCodeUnknowObj       ErrCode = 3
+	ErrCodeCallTimeout     ErrCode = 4
+	ErrCodeCallCanceled    ErrCode = 5
+	ErrCodeUnknownFunc     ErrCode = 6
+	ErrCodeArgNumMismatch  ErrCode = 7
+	ErrCodeNotMigratable   ErrCode = 8
+	ErrCodeNoObjLoader     ErrCode = 9
+	ErrCodeObjMigrating    ErrCode = 10
+	ErrCodeObjExists       ErrCode = 11
+	ErrCodeStaleOwner      ErrCode = 12
+	ErrCodeBadPath         ErrCode = 13
+	ErrCodeNotStreamable   ErrCode = 14
+	ErrCodeConnLost        ErrCode = 15
+	ErrCodeNoObjFactory    ErrCode = 16
+	ErrCodeNotRemindable   ErrCode = 17
+	ErrCodePayloadTooLarge ErrCode = 18
+	ErrCodeUserBegin       ErrCode = 1000
+)
+
+// 带错误码的ROC错误，用户的ROC对象可以返回该错误，调用方将得到具有相同错误码
+// 及信息的错误
+type Error struct {
+	Code    ErrCode
+	Message string
+	Details map[string]string
+}
+
+// 构造一个带错误码的ROC错误
+func NewError(code ErrCode, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// 为错误添加一条详情
+func (this *Error) WithDetail(key string, value string) *Error {
+	if this.Details == nil {
+		this.Details = make(map[string]string)
+	}
+	this.Details[key] = value
+	return this
+}
+
+// 获取错误的描述
+func (this *Error) Error() string {
+	if this.Message == "" {
+		if sentinel := getErrByCode(this.Code); sentinel != nil {
+			return sentinel.Error()
+		}
+	}
+	return this.Message
+}
+
+// 获取该错误码注册的错误，使 errors.Is(err, roc.ErrUnknowObj) 等判断成立
+func (this *Error) Unwrap() error {
+	return getErrByCode(this.Code)
+}
+
+// 错误码相同的ROC错误视为同一错误
+func (this *Error) Is(target error) bool {
+	if t, ok := target.(*Error); ok {
+		return t.Code == this.Code
+	}
+	return false
+}
+
+var (
+	_gErrCodes = make(map[ErrCode]error)
+	// 已注册的错误码，从小到大排列
+	_gErrCodeList   []ErrCode
+	_gErrCodesMutex sync.RWMutex
+)
+
+func init() {
+	RegErrCode(ErrCodeUnregisterROC, ErrUnregisterROC)
+	RegErrCode(ErrCodeUnknowObj, ErrUnknowObj)
+	RegErrCode(ErrCodeCallTimeout, ErrCallTimeout)
+	RegErrCode(ErrCodeCallCanceled, ErrCallCanceled)
+	RegErrCode(ErrCodeNotMigratable, ErrNotMigratable)
+	RegErrCode(ErrCodeNoObjLoader, ErrNoObjLoader)
+	RegErrCode(ErrCodeObjMigrating, ErrObjMigrating)
+	RegErrCode(ErrCodeObjExists, ErrObjExists)
+	RegErrCode(ErrCodeStaleOwner, ErrStaleOwner)
+	RegErrCode(ErrCodeBadPath, ErrBadPath)
+	RegErrCode(ErrCodeNotStreamable, ErrNotStreamable)
+	RegErrCode(ErrCodeConnLost, ErrConnLost)
+	RegErrCode(ErrCodeNoObjFactory, ErrNoObjFactory)
+	RegErrCode(ErrCodeNotRemindable, ErrNotRemindable)
+	RegErrCode(ErrCodePayloadTooLarge, ErrPayloadTooLarge)
+}
+
+// 为一个错误注册错误码，返回值为该错误或包装了该错误的错误，在调用方都会被还原为
+// 可以通过 errors.Is 判断为该错误的 *Error
+func RegErrCode(code ErrCode, err error) {
+	_gErrCodesMutex.Lock()
+	defer _gErrCodesMutex.Unlock()
+	if _, ok := _gErrCodes[code]; !ok {
+		i := sort.Search(len(_gErrCodeList), func(i int) bool {
+			return _gErrCodeList[i] >= code
+		})
+		_gErrCodeList = append(_gErrCodeList, 0)
+		copy(_gErrCodeList[i+1:], _gErrCodeList[i:])
+		_gErrCodeList[i] = code
+	}
+	_gErrCodes[code] = err
+}
+
+func getErrByCode(code ErrCode) error {
+	_gErrCodesMutex.RLock()
+	defer _gErrCodesMutex.RUnlock()
+	return _gErrCodes[code]
+}
+
+// 将错误转换为可以在模块间传递的错误码、错误信息及错误详情。
+// 错误同时匹配多个已注册的错误时，使用其中最小的错误码
+func ErrorToCode(err error) (ErrCode, string, map[string]string) {
+	if err == nil {
+		return ErrCodeOK, "", nil
+	}
+	var rocErr *Error
+	if errors.As(err, &rocErr) {
+		return rocErr.Code, err.Error(), rocErr.Details
+	}
+	_gErrCodesMutex.RLock()
+	defer _gErrCodesMutex.RUnlock()
+	for _, code := range _gErrCodeList {
+		if errors.Is(err, _gErrCodes[code]) {
+			return code, err.Error(), nil
+		}
+	}
+	return ErrCodeUnknown, err.Error(), nil
+}
+
+// 根据错误码、错误信息及错误详情还原错误，没有错误时返回 nil
+func CodeToError(code ErrCode, message string,
+	details map[string]string) error {
+	if code == ErrCodeOK {
+		if message == "" {
+			return nil
+		}
+		// 旧版本的模块只会传递错误信息
+		code = ErrCodeUnknown
+	}
+	return &Error{
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
@@ -0,0 +1,44 @@
+package roc
+
+import (
+	"errors"
+	"testing"
+)
+
+// 同时匹配多个错误的测试错误
+type testMultiErr struct {
+	targets []error
+}
+
+func (this *testMultiErr) Error() string {
+	return "test multi error"
+}
+
+func (this *testMultiErr) Is(target error) bool {
+	for _, err := range this.targets {
+		if err == target {
+			return true
+		}
+	}
+	return false
+}
+
+func TestErrorToCodeOrder(t *testing.T) {
+	errA := errors.New("test error a")
+	errB := errors.New("test error b")
+	RegErrCode(ErrCodeUserBegin+2, errB)
+	RegErrCode(ErrCodeUserBegin+1, errA)
+
+	// 匹配多个错误时总是使用最小的错误码
+	err := &testMultiErr{targets: []error{errB, errA}}
+	for i := 0; i < 100; i++ {
+		if code, _, _ := ErrorToCode(err); code != ErrCodeUserBegin+1 {
+			t.Fatalf("ErrorToCode code = %d, want %d", code,
+				ErrCodeUserBegin+1)
+		}
+	}
+	if code, _, _ := ErrorToCode(errB); code != ErrCodeUserBegin+2 {
+		t.Fatalf("ErrorToCode(errB) code = %d, want %d", code,
+			ErrCodeUserBegin+2)
+	}
+}
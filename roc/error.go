@@ -1,11 +1,36 @@
 package roc
 
 import (
+	"context"
 	"errors"
 )
 
 // ROC错误定义
 var (
-	ErrUnregisterROC = errors.New("unregistered roc")
-	ErrUnknowObj     = errors.New("unknow roc obj")
+	ErrUnregisterROC   = errors.New("unregistered roc")
+	ErrUnknowObj       = errors.New("unknow roc obj")
+	ErrCallTimeout     = errors.New("roc call timeout")
+	ErrCallCanceled    = errors.New("roc call canceled")
+	ErrNotMigratable   = errors.New("roc obj is not migratable")
+	ErrNoObjLoader     = errors.New("roc obj loader not set")
+	ErrNoObjFactory    = errors.New("roc obj factory not set")
+	ErrNotRemindable   = errors.New("roc obj does not accept reminder")
+	ErrObjMigrating    = errors.New("roc obj is migrating")
+	ErrObjExists       = errors.New("roc obj already exists")
+	ErrMulticast       = errors.New("roc multicast partially failed")
+	ErrStaleOwner      = errors.New("roc obj is owned by another module")
+	ErrBadPath         = errors.New("bad roc path")
+	ErrNotStreamable   = errors.New("roc obj does not support stream")
+	ErrFutureNotDone   = errors.New("roc future is not done")
+	ErrConnLost        = errors.New("roc connection to target module lost")
+	ErrPayloadTooLarge = errors.New("roc payload too large")
 )
+
+// 将 ctx 结束的原因转换为ROC错误，超时时返回 ErrCallTimeout ，否则返回
+// ErrCallCanceled
+func ContextErr(ctx context.Context) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return ErrCallTimeout
+	}
+	return ErrCallCanceled
+}
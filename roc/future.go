@@ -0,0 +1,130 @@
+package roc
+
+import (
+	"context"
+	"sync"
+)
+
+// 执行ROC异步调用回调的执行器，例如模块的定时器协程 timer.TimerManager
+type Executor interface {
+	Execute(f func())
+}
+
+// 将普通函数转换为执行器
+type ExecutorFunc func(f func())
+
+// 执行 f
+func (this ExecutorFunc) Execute(f func()) {
+	this(f)
+}
+
+// 完成时的回调
+type futureCallback struct {
+	executor Executor
+	f        func([]byte, error)
+}
+
+// ROC异步调用的结果，可以阻塞等待、轮询，或者设置完成时的回调
+type Future struct {
+	done      chan struct{}
+	mutex     sync.Mutex
+	finished  bool
+	data      []byte
+	err       error
+	callbacks []futureCallback
+}
+
+// 构造一个未完成的 Future
+func NewFuture() *Future {
+	return &Future{
+		done: make(chan struct{}),
+	}
+}
+
+// 设置结果并执行所有回调，只有第一次设置生效，返回本次设置是否生效
+func (this *Future) Complete(data []byte, err error) bool {
+	this.mutex.Lock()
+	if this.finished {
+		this.mutex.Unlock()
+		return false
+	}
+	this.finished = true
+	this.data = data
+	this.err = err
+	callbacks := this.callbacks
+	this.callbacks = nil
+	close(this.done)
+	this.mutex.Unlock()
+
+	for _, cb := range callbacks {
+		this.dispatch(cb)
+	}
+	return true
+}
+
+// 完成时被关闭的 chan ，可以与其他 chan 一起 select
+func (this *Future) Done() <-chan struct{} {
+	return this.done
+}
+
+// 是否已经完成
+func (this *Future) IsDone() bool {
+	select {
+	case <-this.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// 不阻塞地获取结果，未完成时返回 ErrFutureNotDone
+func (this *Future) Result() ([]byte, error) {
+	if !this.IsDone() {
+		return nil, ErrFutureNotDone
+	}
+	return this.data, this.err
+}
+
+// 阻塞等待结果
+func (this *Future) Wait() ([]byte, error) {
+	<-this.done
+	return this.data, this.err
+}
+
+// 等待结果，ctx 超时或被取消时不再等待，但不会影响调用本身
+func (this *Future) WaitContext(ctx context.Context) ([]byte, error) {
+	select {
+	case <-this.done:
+		return this.data, this.err
+	case <-ctx.Done():
+		return nil, ContextErr(ctx)
+	}
+}
+
+// 设置完成时的回调，回调通过 executor 执行，executor 为 nil 时在完成该 Future
+// 的协程中执行。已经完成时立即投递回调
+func (this *Future) OnComplete(executor Executor, f func([]byte, error)) {
+	cb := futureCallback{
+		executor: executor,
+		f:        f,
+	}
+	this.mutex.Lock()
+	if !this.finished {
+		this.callbacks = append(this.callbacks, cb)
+		this.mutex.Unlock()
+		return
+	}
+	this.mutex.Unlock()
+	this.dispatch(cb)
+}
+
+// 投递一个回调
+func (this *Future) dispatch(cb futureCallback) {
+	if cb.executor == nil {
+		cb.f(this.data, this.err)
+		return
+	}
+	cb.executor.Execute(func() {
+		cb.f(this.data, this.err)
+	})
+}
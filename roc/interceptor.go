@@ -0,0 +1,60 @@
+package roc
+
+import (
+	"context"
+	"time"
+)
+
+// 一次ROC调用的信息
+type CallInfo struct {
+	// 发起调用的模块
+	FromModuleID string
+	Path         *ROCPath
+	Arg          []byte
+	// 调用方是否需要返回值
+	NeedReturn bool
+	// 服务端为收到请求的时间，客户端为发起调用的时间
+	RecvTime time.Time
+	// 客户端是否为批量调用，此时 Path 中的对象ID为空，见 ROCMulticast
+	Multicast bool
+}
+
+// 执行ROC调用，返回调用的结果
+type CallHandler func(info *CallInfo) ([]byte, error)
+
+// 服务端ROC调用拦截器，在目标对象的邮箱中执行，调用 next 继续执行调用链，
+// 不调用 next 时，调用不会到达目标对象
+type ServerInterceptor func(info *CallInfo, next CallHandler) ([]byte, error)
+
+// 发起ROC调用，返回调用的结果，无返回值的调用结果总是 nil
+type ClientInvoker func(ctx context.Context, info *CallInfo) ([]byte, error)
+
+// 客户端ROC调用拦截器，在调用方的协程中执行，调用 next 继续执行调用链
+type ClientInterceptor func(ctx context.Context, info *CallInfo,
+	next ClientInvoker) ([]byte, error)
+
+// 将服务端拦截器按顺序串联，第一个拦截器在最外层
+func ChainServerInterceptor(interceptors []ServerInterceptor,
+	handler CallHandler) CallHandler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor := interceptors[i]
+		next := handler
+		handler = func(info *CallInfo) ([]byte, error) {
+			return interceptor(info, next)
+		}
+	}
+	return handler
+}
+
+// 将客户端拦截器按顺序串联，第一个拦截器在最外层
+func ChainClientInterceptor(interceptors []ClientInterceptor,
+	invoker ClientInvoker) ClientInvoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor := interceptors[i]
+		next := invoker
+		invoker = func(ctx context.Context, info *CallInfo) ([]byte, error) {
+			return interceptor(ctx, info, next)
+		}
+	}
+	return invoker
+}
@@ -1,5 +1,10 @@
 package roc
 
+import (
+	"context"
+	"time"
+)
+
 // ROC对象需要实现的接口
 type IObj interface {
 	GetROCObjType() ROCObjType
@@ -18,8 +23,21 @@ type IROCServer interface {
 	GetROC(ROCObjType) *ROC
 	NewROC(ROCObjType) *ROC
 	ROCCallNR(*ROCPath, []byte) error
+	ROCCallNRContext(context.Context, *ROCPath, []byte) error
 	ROCCallBlock(*ROCPath, []byte) ([]byte, error)
+	ROCCallContext(context.Context, *ROCPath, []byte) ([]byte, error)
+	ROCCallAsync(*ROCPath, []byte) *Future
+	ROCCallAsyncContext(context.Context, *ROCPath, []byte) *Future
 	GetROCCachedLocation(ROCObjType, string) string
 	RangeROCCachedByType(ROCObjType, func(id string, location string) bool)
 	RandomROCCachedByType(ROCObjType) string
+	MigrateROCObj(context.Context, ROCObjType, string, string) error
+	ROCMulticast(context.Context, ROCObjType, string, []byte,
+		*MulticastOptions) (*MulticastReport, error)
+	ROCStream(context.Context, *ROCPath, []byte) (StreamReader, error)
+	RegisterReminder(ROCObjType, string, string, time.Duration,
+		time.Duration) error
+	UnregisterReminder(ROCObjType, string, string) error
+	GetLocalROCStats(*StatsOptions) *ModuleStats
+	GetClusterROCStats(context.Context, *StatsOptions) *ClusterStats
 }
@@ -0,0 +1,139 @@
+package roc
+
+import (
+	"sort"
+)
+
+// 查询ROC对象统计的默认样本数量
+const DefaultStatsSampleNum = 10
+
+// 查询ROC对象统计的选项
+type StatsOptions struct {
+	// 只统计该类型的对象，为空时统计所有类型
+	ObjType ROCObjType `json:"type,omitempty"`
+	// 每种样本最多返回的对象ID数量，不大于0时为 DefaultStatsSampleNum
+	SampleNum int `json:"sample,omitempty"`
+}
+
+// 获取样本数量
+func (this *StatsOptions) GetSampleNum() int {
+	if this == nil || this.SampleNum <= 0 {
+		return DefaultStatsSampleNum
+	}
+	return this.SampleNum
+}
+
+// 是否需要统计目标类型
+func (this *StatsOptions) Match(objType ROCObjType) bool {
+	return this == nil || this.ObjType == "" || this.ObjType == objType
+}
+
+// 一组对象ID的数量及样本
+type StatsIDs struct {
+	Num     int      `json:"num"`
+	Samples []string `json:"samples,omitempty"`
+}
+
+// 添加一个对象ID，样本数量达到 sampleNum 后只计数
+func (this *StatsIDs) Add(objID string, sampleNum int) {
+	this.Num++
+	if len(this.Samples) < sampleNum {
+		this.Samples = append(this.Samples, objID)
+	}
+}
+
+// 一个模块中一种类型的ROC对象的统计
+type TypeStats struct {
+	ObjType ROCObjType `json:"type"`
+	// 该模块中注册的对象
+	Objs StatsIDs `json:"objs"`
+	// 该模块中注册，但该模块所在进程的ROC缓存中没有记录或记录在其他模块的对象
+	CacheMissing StatsIDs `json:"cacheMissing"`
+	// 该模块所在进程的ROC缓存中记录在该模块，但该模块中不存在的对象
+	CacheStale StatsIDs `json:"cacheStale"`
+	// 只存在于该模块的ROC中，或只存在于该模块记录的绑定信息中的对象
+	LocalMismatch StatsIDs `json:"localMismatch"`
+	// 该类型的对象邮箱中等待处理的请求数量
+	Pending int `json:"pending"`
+}
+
+// 是否存在缓存与本地记录不一致的对象
+func (this *TypeStats) HasDiscrepancy() bool {
+	return this.CacheMissing.Num > 0 || this.CacheStale.Num > 0 ||
+		this.LocalMismatch.Num > 0
+}
+
+// 一个模块中ROC对象的统计
+type ModuleStats struct {
+	ModuleID string `json:"module"`
+	// 按类型排序的各类型的统计
+	Types []*TypeStats `json:"types"`
+	// 所有对象邮箱中等待处理的请求数量
+	Pending int `json:"pending"`
+	// 查询该模块失败的原因
+	Error string `json:"error,omitempty"`
+}
+
+// 获取目标类型的统计，没有时返回 nil
+func (this *ModuleStats) GetType(objType ROCObjType) *TypeStats {
+	for _, v := range this.Types {
+		if v.ObjType == objType {
+			return v
+		}
+	}
+	return nil
+}
+
+// 获取目标类型的统计，没有时添加
+func (this *ModuleStats) GetTypeMust(objType ROCObjType) *TypeStats {
+	if v := this.GetType(objType); v != nil {
+		return v
+	}
+	v := &TypeStats{
+		ObjType: objType,
+	}
+	this.Types = append(this.Types, v)
+	sort.Slice(this.Types, func(i, j int) bool {
+		return this.Types[i].ObjType < this.Types[j].ObjType
+	})
+	return v
+}
+
+// 集群中ROC对象的统计
+type ClusterStats struct {
+	// 按模块ID排序的各模块的统计
+	Modules []*ModuleStats `json:"modules"`
+	// 是否有模块查询失败，此时统计只包括回答了查询的模块，失败的原因见各模块的 Error
+	Partial bool `json:"partial,omitempty"`
+}
+
+// 获取目标类型在各模块中的对象数量，键为模块ID
+func (this *ClusterStats) CountByModule(objType ROCObjType) map[string]int {
+	res := make(map[string]int)
+	for _, m := range this.Modules {
+		if t := m.GetType(objType); t != nil {
+			res[m.ModuleID] = t.Objs.Num
+		}
+	}
+	return res
+}
+
+// 获取目标类型在集群中的对象总数
+func (this *ClusterStats) Count(objType ROCObjType) int {
+	num := 0
+	for _, n := range this.CountByModule(objType) {
+		num += n
+	}
+	return num
+}
+
+// 获取查询失败的模块的统计
+func (this *ClusterStats) Failed() []*ModuleStats {
+	res := make([]*ModuleStats, 0)
+	for _, m := range this.Modules {
+		if m.Error != "" {
+			res = append(res, m)
+		}
+	}
+	return res
+}
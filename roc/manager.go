@@ -64,9 +64,17 @@ func (this *ROCManager) GetROC(objtype ROCObjType) *ROC {
 	return vi.(*ROC)
 }
 
-// 解码ROC调用路径
+// 遍历所有类型的ROC
+func (this *ROCManager) RangeROC(f func(objtype ROCObjType, roc *ROC) bool) {
+	this.rocs.Range(func(ki, vi interface{}) bool {
+		return f(ki.(ROCObjType), vi.(*ROC))
+	})
+}
+
+// 解码ROC调用路径，路径格式错误时返回空的类型及ID
 func (this *ROCManager) CallPathDecode(kstr string) (ROCObjType, string) {
-	return kstrDecode(kstr)
+	path := NewROCPath(kstr)
+	return path.GetObjType(), path.GetObjID()
 }
 
 // kstr的格式必须为 ROC 远程对象调用那样定义的格式
@@ -87,7 +95,15 @@ func (this *ROCManager) GetObj(objType ROCObjType, objID string) (IObj, bool) {
 
 // 执行远程发来的ROC调用请求
 func (this *ROCManager) Call(callstr string, arg []byte) ([]byte, error) {
-	path := NewROCPath(callstr)
+	path, err := ParseROCPath(callstr)
+	if err != nil {
+		return nil, err
+	}
+	return this.CallPath(path, arg)
+}
+
+// 调用路径指向的本地ROC对象
+func (this *ROCManager) CallPath(path *ROCPath, arg []byte) ([]byte, error) {
 	obj, ok := this.getObj(path.GetObjType(), path.GetObjID())
 	if !ok || obj == nil {
 		path.Reset()
@@ -96,3 +112,32 @@ func (this *ROCManager) Call(callstr string, arg []byte) ([]byte, error) {
 	path.Reset()
 	return obj.OnROCCall(path, arg)
 }
+
+// 将提醒交给路径指向的本地ROC对象处理，对象需要实现 Remindable 接口，
+// 只用于提醒调度发起的调用，见 IROCServer.RegisterReminder
+func (this *ROCManager) RemindPath(path *ROCPath, name string) error {
+	obj, ok := this.getObj(path.GetObjType(), path.GetObjID())
+	if !ok || obj == nil {
+		return ErrUnknowObj
+	}
+	if r, ok := obj.(Remindable); ok {
+		return r.OnROCReminder(name)
+	}
+	return ErrNotRemindable
+}
+
+// 以流式调用的方式调用路径指向的本地ROC对象，对象需要实现 StreamObj 接口
+func (this *ROCManager) StreamPath(path *ROCPath, arg []byte,
+	w StreamWriter) error {
+	obj, ok := this.getObj(path.GetObjType(), path.GetObjID())
+	if !ok || obj == nil {
+		path.Reset()
+		return ErrUnknowObj
+	}
+	path.Reset()
+	streamObj, ok := obj.(StreamObj)
+	if !ok {
+		return ErrNotStreamable
+	}
+	return streamObj.OnROCStream(path, arg, w)
+}
@@ -0,0 +1,19 @@
+package roc
+
+// 可迁移的ROC对象需要实现的接口，迁移时ROC对象的状态将被序列化后发送到目标模块，
+// 由目标模块中该类型ROC的对象加载器重建该对象
+type Migratable interface {
+	IObj
+	MarshalROCState() ([]byte, error)
+}
+
+// 需要持久化的ROC对象需要实现的接口，模块停止时及定时快照时，对象的状态通过
+// MarshalROCState 序列化后保存，模块重启时由该类型ROC的对象加载器重建对象。
+// ROCPersistent 返回 false 的对象不会被保存
+type Persistable interface {
+	Migratable
+	ROCPersistent() bool
+}
+
+// ROC对象加载器，根据对象ID及序列化的状态重建一个ROC对象
+type ObjLoader func(objID string, state []byte) (IObj, error)
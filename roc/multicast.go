@@ -0,0 +1,36 @@
+package roc
+
+// ROC批量调用的选项
+type MulticastOptions struct {
+	// 是否等待每个对象的返回值，为 false 时只报告发送失败的对象
+	NeedReturn bool
+	// 同时等待返回值的模块数量，不大于0时不限制，只在 NeedReturn 时有效
+	Concurrency int
+	// 只调用满足条件的对象，为 nil 时调用该类型所有已知的对象
+	Filter func(objID string) bool
+}
+
+// 批量调用中一个对象的调用结果
+type MulticastResult struct {
+	ObjID    string
+	ModuleID string
+	Data     []byte
+	Err      error
+}
+
+// ROC批量调用的报告
+type MulticastReport struct {
+	// 所有目标对象的调用结果，不保证顺序
+	Results []*MulticastResult
+}
+
+// 获取调用失败的对象的结果
+func (this *MulticastReport) Failed() []*MulticastResult {
+	res := make([]*MulticastResult, 0)
+	for _, v := range this.Results {
+		if v.Err != nil {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -1,16 +1,26 @@
 package roc
 
 import (
+	"context"
 	"fmt"
 	"strings"
 )
 
+// ROC调用路径中的特殊字符，出现在对象类型或函数名中时需要使用 \ 转义，
+// 对象ID由 [] 包围，其中的 . 不需要转义
+const (
+	rocPathSpecialChars   = "\\.[]"
+	rocPathIDSpecialChars = "\\[]"
+)
+
 // ROC调用的路径
 type ROCPath struct {
 	strs    []string
 	pos     int
 	objType ROCObjType
 	objID   string
+	// 处理调用时携带的 context ，包括调用链追踪信息
+	ctx context.Context
 }
 
 // 根据目标ROC的类型及ID，构造一个ROC调用路径
@@ -21,43 +31,114 @@ func O(objType ROCObjType, objID string) *ROCPath {
 	return res
 }
 
-// kstr的格式必须为 ROC 远程对象调用那样定义的格式，如：
-// 对象类型[对象的键]
-func kstrDecode(kstr string) (ROCObjType, string) {
-	t := ""
-	key := ""
-	inkey := false
-	for _, k := range kstr {
-		if k == '[' {
-			inkey = true
-		} else if k == ']' {
-			inkey = false
-		} else if k == '.' {
-			break
-		} else {
-			if key == "" && !inkey {
-				t = t + fmt.Sprintf("%c", k)
-			} else if t != "" && inkey {
-				key = key + fmt.Sprintf("%c", k)
-			} else {
-				return "", ""
+// 转义ROC调用路径中的一段，使其中的特殊字符 special 不会被当作分隔符
+func escapePathSegment(s string, special string) string {
+	if !strings.ContainsAny(s, special) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(special, s[i]) >= 0 {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// 解析ROC调用路径字符串，格式为 对象类型[对象ID].函数名.函数名 ，
+// 对象类型及函数名中的 \ . [ ] 以及对象ID中的 \ [ ] 需要使用 \ 转义。
+// 按字节解析，对象ID可以包含任意字节。对于对象类型及函数名均不为空的路径 p ，
+// ParseROCPath(p.String()) 总能得到与 p 相同的路径
+func ParseROCPath(pathstr string) (*ROCPath, error) {
+	res := &ROCPath{}
+	// 0:对象类型 1:对象ID 2:对象ID之后 3:函数名
+	state := 0
+	var cur strings.Builder
+	fail := func(i int, reason string) (*ROCPath, error) {
+		return nil, fmt.Errorf("%w: %s at %d in %q", ErrBadPath, reason, i,
+			pathstr)
+	}
+	for i := 0; i < len(pathstr); i++ {
+		c := pathstr[i]
+		if c == '\\' {
+			if state == 2 {
+				return fail(i, "unexpected escape")
+			}
+			if i+1 >= len(pathstr) {
+				return fail(i, "dangling escape")
+			}
+			i++
+			cur.WriteByte(pathstr[i])
+			continue
+		}
+		switch state {
+		case 0:
+			switch c {
+			case '[':
+				if cur.Len() == 0 {
+					return fail(i, "empty obj type")
+				}
+				res.objType = ROCObjType(cur.String())
+				cur.Reset()
+				state = 1
+			case ']', '.':
+				return fail(i, "unexpected "+string(c))
+			default:
+				cur.WriteByte(c)
+			}
+		case 1:
+			switch c {
+			case ']':
+				res.objID = cur.String()
+				cur.Reset()
+				state = 2
+			case '[':
+				return fail(i, "unexpected [")
+			default:
+				cur.WriteByte(c)
+			}
+		case 2:
+			if c != '.' {
+				return fail(i, "expected .")
+			}
+			state = 3
+		case 3:
+			switch c {
+			case '.':
+				if cur.Len() == 0 {
+					return fail(i, "empty func name")
+				}
+				res.strs = append(res.strs, cur.String())
+				cur.Reset()
+			case '[', ']':
+				return fail(i, "unexpected "+string(c))
+			default:
+				cur.WriteByte(c)
 			}
 		}
 	}
-	return ROCObjType(t), key
+	switch state {
+	case 0:
+		return fail(len(pathstr), "missing [")
+	case 1:
+		return fail(len(pathstr), "missing ]")
+	case 3:
+		if cur.Len() == 0 {
+			return fail(len(pathstr), "empty func name")
+		}
+		res.strs = append(res.strs, cur.String())
+	}
+	return res, nil
 }
 
-// 根据调用路径字符串，构造一个ROC调用路径
+// 根据调用路径字符串，构造一个ROC调用路径，路径格式错误时返回的路径对象类型为空，
+// 需要得知错误原因时使用 ParseROCPath
 func NewROCPath(pathstr string) *ROCPath {
-	res := &ROCPath{}
-	strs := strings.Split(pathstr, ".")
-	if len(strs) < 1 {
-		return res
+	res, err := ParseROCPath(pathstr)
+	if err != nil {
+		return &ROCPath{}
 	}
-	t, id := kstrDecode(strs[0])
-	res.objType = ROCObjType(t)
-	res.objID = id
-	res.strs = strs[1:]
 	return res
 }
 
@@ -104,15 +185,31 @@ func (this *ROCPath) F(funcName string) *ROCPath {
 	return res
 }
 
-// 获取当前ROC调用路径的字符串描述
+// 获取当前ROC调用路径的字符串描述，其中的特殊字符经过转义，
+// 可以通过 ParseROCPath 还原
 func (this *ROCPath) String() string {
-	res := string(this.objType) + "[" + string(this.objID) + "]"
+	res := escapePathSegment(string(this.objType), rocPathSpecialChars) +
+		"[" + escapePathSegment(this.objID, rocPathIDSpecialChars) + "]"
 	for _, v := range this.strs {
-		res += "." + v
+		res += "." + escapePathSegment(v, rocPathSpecialChars)
 	}
 	return res
 }
 
+// 获取处理该ROC调用时的 context ，在ROC对象中发起后续的ROC调用时使用该 context
+// 即可延续调用链，不会为 nil
+func (this *ROCPath) Context() context.Context {
+	if this.ctx == nil {
+		return context.Background()
+	}
+	return this.ctx
+}
+
+// 设置处理该ROC调用时的 context
+func (this *ROCPath) SetContext(ctx context.Context) {
+	this.ctx = ctx
+}
+
 // 重置当前ROC调用路径的位置
 func (this *ROCPath) Reset() {
 	this.pos = 0
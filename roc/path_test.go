@@ -0,0 +1,132 @@
+package roc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseROCPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		objType ROCObjType
+		objID   string
+		funcs   []string
+	}{
+		{"Player[1]", "Player", "1", nil},
+		{"Player[1].Add", "Player", "1", []string{"Add"}},
+		{"Player[1].Bag.Add", "Player", "1", []string{"Bag", "Add"}},
+		{"Player[].Add", "Player", "", []string{"Add"}},
+		{"Player[a.b.c].Add", "Player", "a.b.c", []string{"Add"}},
+		{`Player[a\]b\[c\\d].Add`, "Player", `a]b[c\d`, []string{"Add"}},
+		{`Pla\.yer\[x\][1].A\.dd`, "Pla.yer[x]", "1", []string{"A.dd"}},
+		{"玩家[编号].加", "玩家", "编号", []string{"加"}},
+	}
+	for _, tt := range tests {
+		p, err := ParseROCPath(tt.path)
+		if err != nil {
+			t.Errorf("ParseROCPath(%q) err: %v", tt.path, err)
+			continue
+		}
+		if p.GetObjType() != tt.objType || p.GetObjID() != tt.objID {
+			t.Errorf("ParseROCPath(%q) obj = %q[%q], want %q[%q]", tt.path,
+				p.GetObjType(), p.GetObjID(), tt.objType, tt.objID)
+		}
+		var funcs []string
+		for f := p.Move(); f != ""; f = p.Move() {
+			funcs = append(funcs, f)
+		}
+		if !reflect.DeepEqual(funcs, tt.funcs) {
+			t.Errorf("ParseROCPath(%q) funcs = %q, want %q", tt.path, funcs,
+				tt.funcs)
+		}
+	}
+}
+
+func TestParseROCPathMalformed(t *testing.T) {
+	tests := []string{
+		"",
+		"Player",
+		"[1].Add",
+		"Player[1",
+		"Player[1[2]].Add",
+		"Play]er[1].Add",
+		"Play.er[1].Add",
+		"Player[1]Add",
+		`Player[1]\.Add`,
+		"Player[1].",
+		"Player[1]..Add",
+		"Player[1].Add.",
+		"Player[1].A[dd",
+		"Player[1].A]dd",
+		`Player[1].Add\`,
+		`Player\`,
+	}
+	for _, path := range tests {
+		p, err := ParseROCPath(path)
+		if err == nil {
+			t.Errorf("ParseROCPath(%q) = %q, want error", path, p.String())
+			continue
+		}
+		if !errors.Is(err, ErrBadPath) {
+			t.Errorf("ParseROCPath(%q) err = %v, want ErrBadPath", path, err)
+		}
+		if p := NewROCPath(path); p.GetObjType() != "" {
+			t.Errorf("NewROCPath(%q) obj type = %q, want empty", path,
+				p.GetObjType())
+		}
+	}
+}
+
+func TestROCPathRoundTrip(t *testing.T) {
+	tests := []struct {
+		objType ROCObjType
+		objID   string
+		funcs   []string
+	}{
+		{"Player", "1", []string{"Add"}},
+		{"Player", "", []string{"Add"}},
+		{"Player", "1", nil},
+		{"Player", "a.b", []string{"Bag", "Add"}},
+		{"Pl.ay[er]", `i[d]\.`, []string{`A.d\d`, "[x]"}},
+		{`\`, `\`, []string{`\`}},
+		{"T", "\x00\xff]", []string{"F"}},
+	}
+	for _, tt := range tests {
+		p := O(tt.objType, tt.objID)
+		for _, f := range tt.funcs {
+			p.F(f)
+		}
+		s := p.String()
+		res, err := ParseROCPath(s)
+		if err != nil {
+			t.Errorf("ParseROCPath(%q) err: %v", s, err)
+			continue
+		}
+		if res.GetObjType() != tt.objType || res.GetObjID() != tt.objID ||
+			!reflect.DeepEqual(res.strs, p.strs) {
+			t.Errorf("round trip %q = %q[%q]%q, want %q[%q]%q", s,
+				res.GetObjType(), res.GetObjID(), res.strs, tt.objType,
+				tt.objID, p.strs)
+		}
+		if res.String() != s {
+			t.Errorf("round trip String() = %q, want %q", res.String(), s)
+		}
+	}
+}
+
+func TestROCPathMove(t *testing.T) {
+	p := NewROCPath("Player[1].Bag.Add")
+	if p.Get(0) != "Bag" || p.Get(1) != "Add" || p.Get(2) != "" ||
+		p.Get(-1) != "" {
+		t.Fatalf("Get = %q %q %q", p.Get(0), p.Get(1), p.Get(2))
+	}
+	if p.Move() != "Bag" || p.GetPos() != 1 || p.Move() != "Add" ||
+		p.Move() != "" || p.GetPos() != 2 {
+		t.Fatalf("Move ended at pos %d", p.GetPos())
+	}
+	p.Reset()
+	if p.GetPos() != 0 || p.Move() != "Bag" {
+		t.Fatalf("Reset did not rewind the path")
+	}
+}
@@ -0,0 +1,56 @@
+package persist
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// 使用本地文件作为后端的持久化存储，每份数据保存为目录下的一个文件
+type FileStorage struct {
+	dir string
+}
+
+// 构造一个将数据保存在 dir 目录下的 FileStorage ，目录不存在时将被创建
+func NewFileStorage(dir string) (*FileStorage, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileStorage{
+		dir: dir,
+	}, nil
+}
+
+// 获取数据对应的文件路径
+func (this *FileStorage) filePath(key string) string {
+	return filepath.Join(this.dir, url.PathEscape(key)+".snapshot")
+}
+
+// 保存一份数据，先写入临时文件再替换，避免写入中断时破坏已有的数据
+func (this *FileStorage) Save(key string, data []byte) error {
+	path := this.filePath(key)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// 读取一份数据
+func (this *FileStorage) Load(key string) ([]byte, error) {
+	data, err := ioutil.ReadFile(this.filePath(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotExist
+	}
+	return data, err
+}
+
+// 删除一份数据
+func (this *FileStorage) Delete(key string) error {
+	err := os.Remove(this.filePath(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
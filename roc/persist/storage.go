@@ -0,0 +1,25 @@
+/*
+ROC对象的持久化存储，模块停止时及运行期间定时将实现了 roc.Persistable 接口的
+ROC对象的状态保存到存储中，模块重启时从存储中恢复这些对象。
+*/
+package persist
+
+import (
+	"errors"
+)
+
+// 持久化存储错误定义
+var (
+	ErrNotExist = errors.New("persist data does not exist")
+)
+
+// ROC对象的持久化存储，可以实现该接口以使用数据库等作为存储后端，
+// 实现需要是并发安全的
+type Storage interface {
+	// 保存一份数据，已存在时覆盖
+	Save(key string, data []byte) error
+	// 读取一份数据，数据不存在时返回 ErrNotExist
+	Load(key string) ([]byte, error)
+	// 删除一份数据，数据不存在时不返回错误
+	Delete(key string) error
+}
@@ -0,0 +1,12 @@
+package roc
+
+// 提醒触发时调用目标ROC对象使用的函数名，调用参数为提醒的名称。
+// 只有提醒调度发起的调用会交给对象的 OnROCReminder 处理，
+// 其他以该函数名发起的调用与普通的ROC调用一样交给 OnROCCall 处理
+const ReminderFuncName = "OnROCReminder"
+
+// 需要接收提醒的ROC对象需要实现的接口，见 IROCServer.RegisterReminder 。
+// 与其他ROC调用一样在对象的邮箱中执行，返回错误时该次提醒将被重试
+type Remindable interface {
+	OnROCReminder(name string) error
+}
@@ -0,0 +1,57 @@
+package roc
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ROC调用的重试策略，只有传输错误会被重试，见 IsTransportError
+type RetryPolicy struct {
+	// 最多尝试的次数，包括第一次调用，不大于1时不重试
+	MaxAttempts int
+	// 第一次重试前等待的时间，之后每次重试等待的时间加倍，不超过 MaxBackoff ，
+	// MaxBackoff 不大于0时不限制
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// 每次尝试等待返回值的时间，超时后视为传输错误进行重试。为0时只受调用的
+	// context 限制，此时超时不会重试
+	AttemptTimeout time.Duration
+}
+
+// 获取第 attempt 次重试前等待的时间，attempt 从1开始
+func (this *RetryPolicy) GetBackoff(attempt int) time.Duration {
+	backoff := this.Backoff
+	for i := 1; i < attempt && backoff > 0; i++ {
+		backoff *= 2
+		if this.MaxBackoff > 0 && backoff >= this.MaxBackoff {
+			break
+		}
+	}
+	if this.MaxBackoff > 0 && backoff > this.MaxBackoff {
+		backoff = this.MaxBackoff
+	}
+	return backoff
+}
+
+// 判断ROC调用的错误是否为传输错误，此时请求或返回值可能在传输中丢失，
+// 目标对象可能执行了也可能没有执行该调用
+func IsTransportError(err error) bool {
+	return errors.Is(err, ErrConnLost)
+}
+
+type idemKeyCtxKey struct{}
+
+// 返回携带ROC调用幂等键的 context ，使用该 context 发起的调用在目标模块中按
+// 幂等键去重，相同幂等键的重复请求直接返回第一次执行的结果，见 conf.ROCDedupWindow
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idemKeyCtxKey{}, key)
+}
+
+// 获取 context 中携带的ROC调用幂等键，没有时返回空字符串
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	if key, ok := ctx.Value(idemKeyCtxKey{}).(string); ok {
+		return key
+	}
+	return ""
+}
@@ -29,8 +29,11 @@ const (
 
 // 一个类型的ROC，维护了这个类型的所有 ROC 对象
 type ROC struct {
-	objPool   pool.MapPool
-	eventHook IROCObjEventHook
+	objPool    pool.MapPool
+	eventHook  IROCObjEventHook
+	objLoader  ObjLoader
+	objFactory ObjFactory
+	placement  PlacementFunc
 }
 
 // 初始化该类型的ROC
@@ -100,6 +103,52 @@ func (this *ROC) GetOrRegObj(id string, obj IObj) (IObj, bool) {
 	return vi.(IObj), isLoad
 }
 
+// 设置该类型ROC对象的加载器，对象迁移到本模块时使用该加载器重建对象
+func (this *ROC) SetObjLoader(loader ObjLoader) {
+	this.objLoader = loader
+}
+
+// 使用对象加载器根据序列化的状态重建一个ROC对象，重建的对象不会被注册
+func (this *ROC) LoadObj(id string, state []byte) (IObj, error) {
+	if this.objLoader == nil {
+		return nil, ErrNoObjLoader
+	}
+	return this.objLoader(id, state)
+}
+
+// 设置该类型ROC对象的工厂，设置后调用方调用不存在的该类型对象时，可以按放置规则
+// 选择本模块按需激活该对象，该类型空闲超时的对象将被停用，见 conf.ROCIdleTTL
+func (this *ROC) SetObjFactory(factory ObjFactory) {
+	this.objFactory = factory
+}
+
+// 该类型的ROC对象是否可以按需激活
+func (this *ROC) HasObjFactory() bool {
+	return this.objFactory != nil
+}
+
+// 使用对象工厂构造一个ROC对象，构造的对象不会被注册
+func (this *ROC) NewObj(id string) (IObj, error) {
+	if this.objFactory == nil {
+		return nil, ErrNoObjFactory
+	}
+	return this.objFactory(id)
+}
+
+// 设置调用方为该类型的对象选择激活模块的放置规则，默认为 PlaceByHash 。
+// 放置规则在调用方的模块中使用，调用该类型对象的模块应设置相同的放置规则
+func (this *ROC) SetPlacement(placement PlacementFunc) {
+	this.placement = placement
+}
+
+// 按放置规则从 candidates 中为目标对象选择激活的模块
+func (this *ROC) Place(id string, candidates []string) string {
+	if this.placement == nil {
+		return PlaceByHash(id, candidates)
+	}
+	return this.placement(id, candidates)
+}
+
 // 遍历该类型的ROC对象
 func (this *ROC) RangeObj(f func(obj IObj) bool) {
 	this.objPool.Range(func(ki, vi interface{}) bool {
@@ -0,0 +1,30 @@
+package roc
+
+import (
+	"context"
+)
+
+// ROC流式调用中，被调用方向调用方发送数据的写入器
+type StreamWriter interface {
+	// 发送一帧数据，调用方的接收窗口已满时阻塞，直到调用方确认了已收到的数据，
+	// 调用方取消或者与调用方的连接断开时返回错误，数据超过ROC调用返回值的大小上限时
+	// 返回 ErrPayloadTooLarge
+	Send(data []byte) error
+	// 调用方取消或者流结束时该 context 被取消
+	Context() context.Context
+}
+
+// ROC流式调用中，调用方接收数据的读取器
+type StreamReader interface {
+	// 按顺序接收一帧数据，流正常结束时返回 io.EOF ，
+	// 被调用方返回错误时返回该错误
+	Recv() ([]byte, error)
+	// 不再接收数据，被调用方的写入器将返回错误，流已结束时没有影响
+	Close()
+}
+
+// 支持流式调用的ROC对象需要实现的接口，返回时流结束，返回的错误将传递给调用方。
+// 该方法在独立的协程中执行，与该对象的其他调用并发，需要自行保证并发安全
+type StreamObj interface {
+	OnROCStream(path *ROCPath, arg []byte, w StreamWriter) error
+}
@@ -9,7 +9,6 @@
 package rocutil
 
 import (
-	"encoding/json"
 	"fmt"
 	"reflect"
 	"sync"
@@ -89,18 +88,19 @@ func (this *ROCObjAgent) getMethod(name string) *Method {
 func (this *ROCObjAgent) OnROCCall(path *roc.ROCPath, arg []byte) ([]byte, error) {
 	funcName := path.Move()
 	if method := this.getMethod(funcName); method != nil {
-		callArg := &CallArg{}
-		err := json.Unmarshal(arg, callArg)
+		// 根据调用参数中的标记选择编解码器，返回值也使用同一个编解码器编码
+		values, c, err := unmarshalValues(arg)
 		if err != nil {
 			return nil, err
 		}
+		callArg := CallArg(values)
 
 		// 调用前处理
 		if this.opts != nil && this.opts.OnBeforeROCCall != nil {
 			this.opts.OnBeforeROCCall(this.obj, path, arg)
 		}
 		// 实际调用该函数
-		result, callErr := method.Call(callArg)
+		result, callErr := method.Call(path.Context(), c, &callArg)
 		// 调用后处理
 		if this.opts != nil && this.opts.OnAfterROCCall != nil {
 			this.opts.OnAfterROCCall(this.obj, path, arg)
@@ -110,15 +110,99 @@ func (this *ROCObjAgent) OnROCCall(path *roc.ROCPath, arg []byte) ([]byte, error
 			return nil, callErr
 		}
 		if len(result) > 0 {
-			sendb, err := json.Marshal(result)
-			return sendb, err
+			return method.EncodeResult(c, result)
 		}
 	} else {
-		return nil, fmt.Errorf("%s:%s", ErrUnknownFunc.Error(), funcName)
+		return nil, fmt.Errorf("%w:%s", ErrUnknownFunc, funcName)
 	}
 	return nil, nil
 }
 
+// 提供给 roc.Server 的接口，受到流式调用时调用，目标方法需要接收 *StreamWriter
+// 作为参数，否则返回 roc.ErrNotStreamable
+func (this *ROCObjAgent) OnROCStream(path *roc.ROCPath, arg []byte,
+	w roc.StreamWriter) error {
+	funcName := path.Move()
+	method := this.getMethod(funcName)
+	if method == nil {
+		return fmt.Errorf("%w:%s", ErrUnknownFunc, funcName)
+	}
+	if !method.IsStream() {
+		return roc.ErrNotStreamable
+	}
+	// 每一帧数据使用调用参数的编解码器编码
+	values, c, err := unmarshalValues(arg)
+	if err != nil {
+		return err
+	}
+	callArg := CallArg(values)
+
+	if this.opts != nil && this.opts.OnBeforeROCCall != nil {
+		this.opts.OnBeforeROCCall(this.obj, path, arg)
+	}
+	_, callErr := method.CallStream(path.Context(), c,
+		&StreamWriter{w: w, codec: c}, &callArg)
+	if this.opts != nil && this.opts.OnAfterROCCall != nil {
+		this.opts.OnAfterROCCall(this.obj, path, arg)
+	}
+	return callErr
+}
+
+// 提供给 roc.Server 的接口，迁移ROC对象时序列化对象的状态，目标对象需要实现
+// MarshalROCState() ([]byte, error) 方法，否则返回 roc.ErrNotMigratable 。
+// 在迁移目标模块中，对象加载器可以使用 NewROCObj 重建代理
+func (this *ROCObjAgent) MarshalROCState() ([]byte, error) {
+	if m, ok := this.obj.(interface {
+		MarshalROCState() ([]byte, error)
+	}); ok {
+		return m.MarshalROCState()
+	}
+	return nil, roc.ErrNotMigratable
+}
+
+// 提供给 roc.Server 的接口，持久化ROC对象时判断对象是否需要保存，目标对象需要实现
+// ROCPersistent() bool 方法，否则不会被保存
+func (this *ROCObjAgent) ROCPersistent() bool {
+	if p, ok := this.obj.(interface {
+		ROCPersistent() bool
+	}); ok {
+		return p.ROCPersistent()
+	}
+	return false
+}
+
+// 提供给 roc.Server 的接口，按需激活对象时在注册前调用，目标对象实现了
+// OnActivate() error 方法时调用该方法
+func (this *ROCObjAgent) OnActivate() error {
+	if a, ok := this.obj.(interface {
+		OnActivate() error
+	}); ok {
+		return a.OnActivate()
+	}
+	return nil
+}
+
+// 提供给 roc.Server 的接口，对象空闲超时被停用时调用，目标对象实现了
+// OnDeactivate() 方法时调用该方法
+func (this *ROCObjAgent) OnDeactivate() {
+	if d, ok := this.obj.(interface {
+		OnDeactivate()
+	}); ok {
+		d.OnDeactivate()
+	}
+}
+
+// 提供给 roc.Server 的接口，对象的提醒触发时调用，目标对象需要实现
+// OnROCReminder(name string) error 方法，否则返回 roc.ErrNotRemindable
+func (this *ROCObjAgent) OnROCReminder(name string) error {
+	if r, ok := this.obj.(interface {
+		OnROCReminder(name string) error
+	}); ok {
+		return r.OnROCReminder(name)
+	}
+	return roc.ErrNotRemindable
+}
+
 // 提供给 roc.Server 的接口，获取ROC对象的类型
 func (this *ROCObjAgent) GetROCObjType() roc.ROCObjType {
 	return this.typ
@@ -0,0 +1,155 @@
+// Copyright 2019 The Misserver Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE.txt file.
+
+/*
+ * @Author: Jansen
+ */
+
+package codec
+
+import (
+	"encoding"
+	"reflect"
+)
+
+// go2go 生成的消息结构具有的编码接口
+type binaryMsgWriter interface {
+	WriteBinary(data []byte) int
+	GetSize() int
+}
+
+// go2go 生成的消息结构具有的解码接口
+type binaryMsgReader interface {
+	ReadBinary(data []byte) int
+}
+
+// micserver 自有的二进制编解码器，支持基础类型、 string 、 []byte ，
+// 以及由 go2go 生成的消息结构和实现了 encoding.BinaryMarshaler 的类型
+type binaryCodec struct{}
+
+func (binaryCodec) Tag() byte {
+	return TagBinary
+}
+
+func (binaryCodec) Name() string {
+	return "binary"
+}
+
+func (binaryCodec) Marshal(v interface{}) ([]byte, error) {
+	w := &BinaryWriter{}
+	switch value := v.(type) {
+	case bool:
+		w.WriteBool(value)
+	case int8:
+		w.WriteInt8(value)
+	case uint8:
+		w.WriteUint8(value)
+	case int16:
+		w.WriteInt16(value)
+	case uint16:
+		w.WriteUint16(value)
+	case int32:
+		w.WriteInt32(value)
+	case uint32:
+		w.WriteUint32(value)
+	case int64:
+		w.WriteInt64(value)
+	case uint64:
+		w.WriteUint64(value)
+	case int:
+		w.WriteInt(value)
+	case uint:
+		w.WriteUint(value)
+	case float32:
+		w.WriteFloat32(value)
+	case float64:
+		w.WriteFloat64(value)
+	case string:
+		w.WriteString(value)
+	case []byte:
+		w.WriteBytes(value)
+	default:
+		return marshalBinaryObj(v)
+	}
+	return w.Bytes(), nil
+}
+
+// 编码结构对象，结构的编码方法通常定义在指针上，所以值类型需要先取得指针
+func marshalBinaryObj(v interface{}) ([]byte, error) {
+	if v != nil {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Ptr {
+			ptr := reflect.New(rv.Type())
+			ptr.Elem().Set(rv)
+			v = ptr.Interface()
+		}
+	}
+	switch value := v.(type) {
+	case binaryMsgWriter:
+		data := make([]byte, value.GetSize())
+		n := value.WriteBinary(data)
+		return data[:n], nil
+	case encoding.BinaryMarshaler:
+		return value.MarshalBinary()
+	}
+	return nil, ErrUnsupportedType
+}
+
+func (binaryCodec) Unmarshal(data []byte, v interface{}) error {
+	r := NewBinaryReader(data)
+	switch value := v.(type) {
+	case *bool:
+		*value = r.ReadBool()
+	case *int8:
+		*value = r.ReadInt8()
+	case *uint8:
+		*value = r.ReadUint8()
+	case *int16:
+		*value = r.ReadInt16()
+	case *uint16:
+		*value = r.ReadUint16()
+	case *int32:
+		*value = r.ReadInt32()
+	case *uint32:
+		*value = r.ReadUint32()
+	case *int64:
+		*value = r.ReadInt64()
+	case *uint64:
+		*value = r.ReadUint64()
+	case *int:
+		*value = r.ReadInt()
+	case *uint:
+		*value = r.ReadUint()
+	case *float32:
+		*value = r.ReadFloat32()
+	case *float64:
+		*value = r.ReadFloat64()
+	case *string:
+		*value = r.ReadString()
+	case *[]byte:
+		*value = r.ReadBytes()
+	default:
+		return unmarshalBinaryObj(data, v)
+	}
+	return r.Err()
+}
+
+// 解码结构对象，如果 v 是指向结构指针的指针，将为其分配新的结构
+func unmarshalBinaryObj(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Ptr {
+		if rv.Elem().IsNil() {
+			rv.Elem().Set(reflect.New(rv.Elem().Type().Elem()))
+		}
+		v = rv.Elem().Interface()
+	}
+	switch value := v.(type) {
+	case binaryMsgReader:
+		value.ReadBinary(data)
+		return nil
+	case encoding.BinaryUnmarshaler:
+		return value.UnmarshalBinary(data)
+	}
+	return ErrUnsupportedType
+}
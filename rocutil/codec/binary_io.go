@@ -0,0 +1,260 @@
+// Copyright 2019 The Misserver Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE.txt file.
+
+/*
+ * @Author: Jansen
+ */
+
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// 错误定义
+var (
+	ErrShortBuffer = errors.New("binary data too short")
+)
+
+// 二进制写入器，整数使用小端序，字符串及字节流带有4字节长度前缀，
+// 与 go2go 生成的消息格式一致，其中 int 及 uint 固定使用8字节以免截断
+type BinaryWriter struct {
+	buf []byte
+}
+
+// 获取已写入的数据
+func (this *BinaryWriter) Bytes() []byte {
+	return this.buf
+}
+
+func (this *BinaryWriter) grow(n int) []byte {
+	l := len(this.buf)
+	if cap(this.buf)-l < n {
+		newBuf := make([]byte, l, 2*cap(this.buf)+n)
+		copy(newBuf, this.buf)
+		this.buf = newBuf
+	}
+	this.buf = this.buf[:l+n]
+	return this.buf[l:]
+}
+
+// 写入 bool
+func (this *BinaryWriter) WriteBool(v bool) {
+	if v {
+		this.WriteUint8(1)
+	} else {
+		this.WriteUint8(0)
+	}
+}
+
+// 写入 uint8
+func (this *BinaryWriter) WriteUint8(v uint8) {
+	this.grow(1)[0] = v
+}
+
+// 写入 uint16
+func (this *BinaryWriter) WriteUint16(v uint16) {
+	binary.LittleEndian.PutUint16(this.grow(2), v)
+}
+
+// 写入 uint32
+func (this *BinaryWriter) WriteUint32(v uint32) {
+	binary.LittleEndian.PutUint32(this.grow(4), v)
+}
+
+// 写入 uint64
+func (this *BinaryWriter) WriteUint64(v uint64) {
+	binary.LittleEndian.PutUint64(this.grow(8), v)
+}
+
+// 写入 int8
+func (this *BinaryWriter) WriteInt8(v int8) {
+	this.WriteUint8(uint8(v))
+}
+
+// 写入 int16
+func (this *BinaryWriter) WriteInt16(v int16) {
+	this.WriteUint16(uint16(v))
+}
+
+// 写入 int32
+func (this *BinaryWriter) WriteInt32(v int32) {
+	this.WriteUint32(uint32(v))
+}
+
+// 写入 int64
+func (this *BinaryWriter) WriteInt64(v int64) {
+	this.WriteUint64(uint64(v))
+}
+
+// 写入 int
+func (this *BinaryWriter) WriteInt(v int) {
+	this.WriteInt64(int64(v))
+}
+
+// 写入 uint
+func (this *BinaryWriter) WriteUint(v uint) {
+	this.WriteUint64(uint64(v))
+}
+
+// 写入 float32
+func (this *BinaryWriter) WriteFloat32(v float32) {
+	this.WriteUint32(math.Float32bits(v))
+}
+
+// 写入 float64
+func (this *BinaryWriter) WriteFloat64(v float64) {
+	this.WriteUint64(math.Float64bits(v))
+}
+
+// 写入 string
+func (this *BinaryWriter) WriteString(v string) {
+	this.WriteUint32(uint32(len(v)))
+	copy(this.grow(len(v)), v)
+}
+
+// 写入 []byte
+func (this *BinaryWriter) WriteBytes(v []byte) {
+	this.WriteUint32(uint32(len(v)))
+	copy(this.grow(len(v)), v)
+}
+
+// 二进制读取器，与 BinaryWriter 对应，数据不足时后续读取均返回零值，
+// 错误可以通过 Err 获取
+type BinaryReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+// 构造一个二进制读取器
+func NewBinaryReader(data []byte) *BinaryReader {
+	return &BinaryReader{
+		data: data,
+	}
+}
+
+// 获取读取过程中发生的错误
+func (this *BinaryReader) Err() error {
+	return this.err
+}
+
+// 剩余未读取的数据长度
+func (this *BinaryReader) Len() int {
+	return len(this.data) - this.pos
+}
+
+func (this *BinaryReader) next(n int) []byte {
+	if this.err != nil {
+		return nil
+	}
+	if n < 0 || this.pos+n > len(this.data) {
+		this.err = ErrShortBuffer
+		return nil
+	}
+	res := this.data[this.pos : this.pos+n]
+	this.pos += n
+	return res
+}
+
+// 读取 bool
+func (this *BinaryReader) ReadBool() bool {
+	return this.ReadUint8() != 0
+}
+
+// 读取 uint8
+func (this *BinaryReader) ReadUint8() uint8 {
+	b := this.next(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+// 读取 uint16
+func (this *BinaryReader) ReadUint16() uint16 {
+	b := this.next(2)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(b)
+}
+
+// 读取 uint32
+func (this *BinaryReader) ReadUint32() uint32 {
+	b := this.next(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+// 读取 uint64
+func (this *BinaryReader) ReadUint64() uint64 {
+	b := this.next(8)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+// 读取 int8
+func (this *BinaryReader) ReadInt8() int8 {
+	return int8(this.ReadUint8())
+}
+
+// 读取 int16
+func (this *BinaryReader) ReadInt16() int16 {
+	return int16(this.ReadUint16())
+}
+
+// 读取 int32
+func (this *BinaryReader) ReadInt32() int32 {
+	return int32(this.ReadUint32())
+}
+
+// 读取 int64
+func (this *BinaryReader) ReadInt64() int64 {
+	return int64(this.ReadUint64())
+}
+
+// 读取 int
+func (this *BinaryReader) ReadInt() int {
+	return int(this.ReadInt64())
+}
+
+// 读取 uint
+func (this *BinaryReader) ReadUint() uint {
+	return uint(this.ReadUint64())
+}
+
+// 读取 float32
+func (this *BinaryReader) ReadFloat32() float32 {
+	return math.Float32frombits(this.ReadUint32())
+}
+
+// 读取 float64
+func (this *BinaryReader) ReadFloat64() float64 {
+	return math.Float64frombits(this.ReadUint64())
+}
+
+// 读取 string
+func (this *BinaryReader) ReadString() string {
+	n := this.ReadUint32()
+	return string(this.next(int(n)))
+}
+
+// 读取 []byte ，返回的数据是一份拷贝
+func (this *BinaryReader) ReadBytes() []byte {
+	n := this.ReadUint32()
+	b := this.next(int(n))
+	if b == nil {
+		return nil
+	}
+	res := make([]byte, len(b))
+	copy(res, b)
+	return res
+}
@@ -0,0 +1,137 @@
+// Copyright 2019 The Misserver Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE.txt file.
+
+/*
+ * @Author: Jansen
+ */
+
+/*
+rocutil 调用参数及返回值的编解码器。
+调用方选择的编解码器以标记的形式写入调用参数的首字节，被调用方根据该标记选择
+相同的编解码器解码参数并编码返回值，因此使用不同编解码器的模块之间可以互相调用。
+JSON 编解码器不写入标记，与旧版本的调用参数格式保持一致。
+*/
+package codec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// 编解码器标记
+const (
+	TagJSON   byte = 0
+	TagGob    byte = 1
+	TagBinary byte = 2
+)
+
+// 错误定义
+var (
+	ErrUnknownTag      = errors.New("unknown codec tag")
+	ErrInvalidTag      = errors.New("invalid codec tag")
+	ErrUnsupportedType = errors.New("unsupported type")
+)
+
+// 调用参数及返回值的编解码器
+type Codec interface {
+	// 编解码器的标记，在所有模块中应该唯一
+	Tag() byte
+	// 编解码器的名字
+	Name() string
+	// 编码一个值
+	Marshal(v interface{}) ([]byte, error)
+	// 将数据解码到 v 中， v 必须是指针
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// 内置的编解码器
+var (
+	JSON   Codec = jsonCodec{}
+	Gob    Codec = gobCodec{}
+	Binary Codec = binaryCodec{}
+)
+
+var (
+	_gCodecs      = make(map[byte]Codec)
+	_gCodecsMutex sync.RWMutex
+)
+
+func init() {
+	Register(JSON)
+	Register(Gob)
+	Register(Binary)
+}
+
+// 判断标记是否可能与旧版本 JSON 格式的调用参数首字节冲突
+func isJSONLeadByte(tag byte) bool {
+	switch tag {
+	case '[', 'n', ' ', '\t', '\r', '\n':
+		return true
+	}
+	return false
+}
+
+// 注册一个编解码器，注册后才能解码使用该编解码器标记的调用参数
+func Register(c Codec) error {
+	if c.Tag() != TagJSON && isJSONLeadByte(c.Tag()) {
+		return ErrInvalidTag
+	}
+	_gCodecsMutex.Lock()
+	defer _gCodecsMutex.Unlock()
+	_gCodecs[c.Tag()] = c
+	return nil
+}
+
+// 根据标记获取编解码器，不存在时返回 nil
+func Get(tag byte) Codec {
+	_gCodecsMutex.RLock()
+	defer _gCodecsMutex.RUnlock()
+	return _gCodecs[tag]
+}
+
+// encoding/json 编解码器
+type jsonCodec struct{}
+
+func (jsonCodec) Tag() byte {
+	return TagJSON
+}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// encoding/gob 编解码器，可以保留 int64 []byte 及非字符串键的 map 等类型
+type gobCodec struct{}
+
+func (gobCodec) Tag() byte {
+	return TagGob
+}
+
+func (gobCodec) Name() string {
+	return "gob"
+}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
@@ -0,0 +1,166 @@
+package codec
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// 实现了 encoding.BinaryMarshaler 的测试类型
+type testPoint struct {
+	X, Y int32
+}
+
+func (this *testPoint) MarshalBinary() ([]byte, error) {
+	w := &BinaryWriter{}
+	w.WriteInt32(this.X)
+	w.WriteInt32(this.Y)
+	return w.Bytes(), nil
+}
+
+func (this *testPoint) UnmarshalBinary(data []byte) error {
+	r := NewBinaryReader(data)
+	this.X = r.ReadInt32()
+	this.Y = r.ReadInt32()
+	return r.Err()
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"bool", true},
+		{"int8", int8(-8)},
+		{"uint8", uint8(200)},
+		{"int16", int16(-1600)},
+		{"uint16", uint16(60000)},
+		{"int32", int32(math.MinInt32)},
+		{"uint32", uint32(math.MaxUint32)},
+		{"int64", int64(math.MinInt64)},
+		{"uint64", uint64(math.MaxUint64)},
+		{"int", int(-1) << 40},
+		{"uint", uint(1) << 40},
+		{"float32", float32(3.5)},
+		{"float64", math.Pi},
+		{"string", "hello 世界"},
+		{"empty string", ""},
+		{"bytes", []byte{0, 1, 2, 255}},
+	}
+	for _, c := range []Codec{JSON, Gob, Binary} {
+		for _, tt := range tests {
+			data, err := c.Marshal(tt.v)
+			if err != nil {
+				t.Errorf("%s %s Marshal err: %v", c.Name(), tt.name, err)
+				continue
+			}
+			res := reflect.New(reflect.TypeOf(tt.v))
+			if err := c.Unmarshal(data, res.Interface()); err != nil {
+				t.Errorf("%s %s Unmarshal err: %v", c.Name(), tt.name, err)
+				continue
+			}
+			if !reflect.DeepEqual(res.Elem().Interface(), tt.v) {
+				t.Errorf("%s %s = %v, want %v", c.Name(), tt.name,
+					res.Elem().Interface(), tt.v)
+			}
+		}
+	}
+}
+
+func TestBinaryCodecObj(t *testing.T) {
+	p := testPoint{X: 1, Y: -2}
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"value", p},
+		{"pointer", &p},
+	}
+	for _, tt := range tests {
+		data, err := Binary.Marshal(tt.v)
+		if err != nil {
+			t.Errorf("%s Marshal err: %v", tt.name, err)
+			continue
+		}
+		var res testPoint
+		if err := Binary.Unmarshal(data, &res); err != nil || res != p {
+			t.Errorf("%s Unmarshal = %v, %v, want %v", tt.name, res, err, p)
+		}
+		// 指向结构指针的指针将被分配新的结构
+		var pres *testPoint
+		if err := Binary.Unmarshal(data, &pres); err != nil || pres == nil ||
+			*pres != p {
+			t.Errorf("%s Unmarshal to **T = %v, %v, want %v", tt.name, pres,
+				err, p)
+		}
+	}
+}
+
+func TestBinaryCodecMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		v    interface{}
+		err  error
+	}{
+		{"empty int32", nil, new(int32), ErrShortBuffer},
+		{"short int64", []byte{1, 2, 3}, new(int64), ErrShortBuffer},
+		{"short float64", []byte{1}, new(float64), ErrShortBuffer},
+		{"short string prefix", []byte{1, 0}, new(string), ErrShortBuffer},
+		{"short string", []byte{5, 0, 0, 0, 'a'}, new(string), ErrShortBuffer},
+		{"huge bytes prefix", []byte{255, 255, 255, 255}, new([]byte),
+			ErrShortBuffer},
+		{"short obj", []byte{1, 0}, new(testPoint), ErrShortBuffer},
+		{"unsupported", []byte{1}, new(struct{ A int }), ErrUnsupportedType},
+	}
+	for _, tt := range tests {
+		err := Binary.Unmarshal(tt.data, tt.v)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s Unmarshal err = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+	if _, err := Binary.Marshal(struct{ A int }{}); err != ErrUnsupportedType {
+		t.Errorf("Marshal unsupported err = %v, want %v", err,
+			ErrUnsupportedType)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	for _, tag := range []byte{'[', 'n', ' ', '\t', '\r', '\n'} {
+		if err := Register(testCodec{tag}); err != ErrInvalidTag {
+			t.Errorf("Register tag %q err = %v, want %v", tag, err,
+				ErrInvalidTag)
+		}
+		if Get(tag) != nil {
+			t.Errorf("Get(%q) registered invalid codec", tag)
+		}
+	}
+	for tag, c := range map[byte]Codec{TagJSON: JSON, TagGob: Gob,
+		TagBinary: Binary} {
+		if Get(tag) != c {
+			t.Errorf("Get(%d) = %v, want %s", tag, Get(tag), c.Name())
+		}
+	}
+}
+
+// 只用于测试注册的编解码器
+type testCodec struct {
+	tag byte
+}
+
+func (this testCodec) Tag() byte {
+	return this.tag
+}
+
+func (testCodec) Name() string {
+	return "test"
+}
+
+func (testCodec) Marshal(v interface{}) ([]byte, error) {
+	return nil, ErrUnsupportedType
+}
+
+func (testCodec) Unmarshal(data []byte, v interface{}) error {
+	return ErrUnsupportedType
+}
@@ -10,11 +10,20 @@ package rocutil
 
 import (
 	"errors"
+
+	"github.com/liasece/micserver/roc"
 )
 
 // 错误定义
 var (
-	ErrIsNotFunc      = errors.New("target funcname isn't func")
-	ErrUnknownFunc    = errors.New("unknown function name")
-	ErrArgNumMismatch = errors.New("call arg num mismatch")
+	ErrIsNotFunc         = errors.New("target funcname isn't func")
+	ErrUnknownFunc       = errors.New("unknown function name")
+	ErrArgNumMismatch    = errors.New("call arg num mismatch")
+	ErrResultNumMismatch = errors.New("call result num mismatch")
+	ErrStreamFunc        = errors.New("stream function must be called by stream")
 )
+
+func init() {
+	roc.RegErrCode(roc.ErrCodeUnknownFunc, ErrUnknownFunc)
+	roc.RegErrCode(roc.ErrCodeArgNumMismatch, ErrArgNumMismatch)
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 The Misserver Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE.txt file.
+
+/*
+ * @Author: Jansen
+ */
+
+package rocutil
+
+import (
+	"encoding/json"
+
+	"github.com/liasece/micserver/rocutil/codec"
+)
+
+// 将一组已编码的值打包为调用参数或返回值的二进制流。
+// JSON 编解码器保持旧版本的格式，其他编解码器的格式为：
+// 编解码器标记(1字节) + 值的数量(4字节) + 每个值(4字节长度前缀)
+func marshalValues(c codec.Codec, values [][]byte) ([]byte, error) {
+	if c == nil || c.Tag() == codec.TagJSON {
+		return json.Marshal(values)
+	}
+	w := &codec.BinaryWriter{}
+	w.WriteUint8(c.Tag())
+	w.WriteUint32(uint32(len(values)))
+	for _, v := range values {
+		w.WriteBytes(v)
+	}
+	return w.Bytes(), nil
+}
+
+// 解包调用参数或返回值的二进制流，同时返回发送方使用的编解码器
+func unmarshalValues(b []byte) ([][]byte, codec.Codec, error) {
+	var values [][]byte
+	if len(b) > 0 && b[0] != codec.TagJSON {
+		if c := codec.Get(b[0]); c != nil {
+			r := codec.NewBinaryReader(b[1:])
+			n := r.ReadUint32()
+			for i := uint32(0); i < n && r.Err() == nil; i++ {
+				values = append(values, r.ReadBytes())
+			}
+			if r.Err() != nil {
+				return nil, nil, r.Err()
+			}
+			return values, c, nil
+		}
+		if b[0] != '[' && b[0] != 'n' {
+			return nil, nil, codec.ErrUnknownTag
+		}
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &values); err != nil {
+			return nil, nil, err
+		}
+	}
+	return values, codec.JSON, nil
+}
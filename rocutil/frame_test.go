@@ -0,0 +1,91 @@
+package rocutil
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/liasece/micserver/rocutil/codec"
+)
+
+func TestValuesRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		values [][]byte
+	}{
+		{"nil", nil},
+		{"one", [][]byte{[]byte("1")}},
+		{"many", [][]byte{[]byte(`"a"`), []byte("2"), []byte("null")}},
+		{"binary", [][]byte{{0, 1, 255}, {}}},
+	}
+	for _, c := range []codec.Codec{codec.JSON, codec.Gob, codec.Binary} {
+		for _, tt := range tests {
+			data, err := marshalValues(c, tt.values)
+			if err != nil {
+				t.Errorf("%s %s marshalValues err: %v", c.Name(), tt.name, err)
+				continue
+			}
+			values, resCodec, err := unmarshalValues(data)
+			if err != nil {
+				t.Errorf("%s %s unmarshalValues err: %v", c.Name(), tt.name,
+					err)
+				continue
+			}
+			if resCodec != c {
+				t.Errorf("%s %s codec = %s", c.Name(), tt.name,
+					resCodec.Name())
+			}
+			if len(values) != len(tt.values) {
+				t.Errorf("%s %s values = %q, want %q", c.Name(), tt.name,
+					values, tt.values)
+				continue
+			}
+			for i := range values {
+				if string(values[i]) != string(tt.values[i]) {
+					t.Errorf("%s %s values = %q, want %q", c.Name(), tt.name,
+						values, tt.values)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestValuesLegacyJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		values [][]byte
+	}{
+		{"empty", "", nil},
+		{"null", "null", nil},
+		{"array", `["MQ==","Mg=="]`, [][]byte{[]byte("1"), []byte("2")}},
+	}
+	for _, tt := range tests {
+		values, c, err := unmarshalValues([]byte(tt.data))
+		if err != nil || c != codec.JSON {
+			t.Errorf("%s unmarshalValues = %v, %v", tt.name, c, err)
+			continue
+		}
+		if !reflect.DeepEqual(values, tt.values) {
+			t.Errorf("%s values = %q, want %q", tt.name, values, tt.values)
+		}
+	}
+}
+
+func TestValuesMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"unknown tag", []byte{200, 0, 0, 0, 0}},
+		{"bad json", []byte(`["MQ==",`)},
+		{"short count", []byte{codec.TagBinary, 1, 0}},
+		{"missing value", []byte{codec.TagBinary, 2, 0, 0, 0, 0, 0, 0, 0}},
+		{"short value", []byte{codec.TagGob, 1, 0, 0, 0, 9, 0, 0, 0, 1}},
+	}
+	for _, tt := range tests {
+		if values, _, err := unmarshalValues(tt.data); err == nil {
+			t.Errorf("%s unmarshalValues = %q, want error", tt.name, values)
+		}
+	}
+}
@@ -9,13 +9,29 @@
 package rocutil
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
 	"reflect"
+
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/rocutil/codec"
 )
 
 // ROC调用的参数列表
 type CallArg [][]byte
 
+// ROC调用的返回值列表，不包括作为最后一个返回值的 error
+type CallResult [][]byte
+
+// error 接口的类型
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// context.Context 接口的类型
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// 流式调用写入器的类型
+var streamWriterType = reflect.TypeOf((*StreamWriter)(nil))
+
 // 增加一个调用参数
 func (this *CallArg) Add(data []byte) {
 	(*this) = append(*this, data)
@@ -32,6 +48,13 @@ type Method struct {
 	args  []reflect.Type
 	value reflect.Value
 	typ   reflect.Type
+	// 最后一个返回值是否是 error
+	returnErr bool
+	// 第一个参数是否是 context.Context ，该参数不由调用方传递
+	hasContext bool
+	// 是否是流式调用的方法，在 context.Context 之后的第一个参数是 *StreamWriter ，
+	// 该参数不由调用方传递
+	isStream bool
 }
 
 // 初始化一个方法
@@ -45,16 +68,28 @@ func (this *Method) Init(name string, f reflect.Value) error {
 
 	// 遍历所有参数
 	numArgs := this.typ.NumIn()
-	this.args = make([]reflect.Type, numArgs)
+	this.args = make([]reflect.Type, 0, numArgs)
 	for i := 0; i < numArgs; i++ {
 		argTyp := this.typ.In(i)
-		this.args[i] = argTyp
+		if i == 0 && argTyp == contextType {
+			this.hasContext = true
+			continue
+		}
+		if len(this.args) == 0 && !this.isStream &&
+			argTyp == streamWriterType {
+			this.isStream = true
+			continue
+		}
+		this.args = append(this.args, argTyp)
 	}
+	numOut := this.typ.NumOut()
+	this.returnErr = numOut > 0 && this.typ.Out(numOut-1) == errorType
 	return nil
 }
 
 // 根据远程调用发来的参数，将信息解码为当前方法的参数列表，提供给外层反射调用。
-func (this *Method) GetArgValues(data *CallArg) ([]reflect.Value, error) {
+func (this *Method) GetArgValues(c codec.Codec,
+	data *CallArg) ([]reflect.Value, error) {
 	if data.Len() != len(this.args) {
 		return nil, ErrArgNumMismatch
 	}
@@ -63,7 +98,7 @@ func (this *Method) GetArgValues(data *CallArg) ([]reflect.Value, error) {
 		// new arg value
 		v := reflect.New(this.args[i])
 		// unmarshal arg value
-		err := json.Unmarshal((*data)[i], v.Interface())
+		err := c.Unmarshal((*data)[i], v.Interface())
 		if err != nil {
 			return nil, err
 		}
@@ -73,16 +108,69 @@ func (this *Method) GetArgValues(data *CallArg) ([]reflect.Value, error) {
 	return res, nil
 }
 
-// 提供编码好的参数二进制流，调用该方法
-func (this *Method) Call(data *CallArg) ([]reflect.Value, error) {
-	args, err := this.GetArgValues(data)
+// 提供编码好的参数二进制流，调用该方法。
+// 如果该方法的第一个参数是 context.Context ，将传入 ctx 。
+// 如果该方法的最后一个返回值是 error ，该返回值不会出现在返回值列表中，
+// 而是作为调用的错误返回。流式调用的方法需要通过 CallStream 调用。
+func (this *Method) Call(ctx context.Context, c codec.Codec,
+	data *CallArg) ([]reflect.Value, error) {
+	if this.isStream {
+		return nil, fmt.Errorf("%w:%s", ErrStreamFunc, this.name)
+	}
+	return this.call(ctx, c, nil, data)
+}
+
+// 以流式调用的方式调用该方法，w 将作为 *StreamWriter 参数传入
+func (this *Method) CallStream(ctx context.Context, c codec.Codec,
+	w *StreamWriter, data *CallArg) ([]reflect.Value, error) {
+	if !this.isStream {
+		return nil, roc.ErrNotStreamable
+	}
+	return this.call(ctx, c, w, data)
+}
+
+func (this *Method) call(ctx context.Context, c codec.Codec,
+	w *StreamWriter, data *CallArg) ([]reflect.Value, error) {
+	args, err := this.GetArgValues(c, data)
 	if err != nil {
 		return nil, err
 	}
+	if this.isStream {
+		args = append([]reflect.Value{reflect.ValueOf(w)}, args...)
+	}
+	if this.hasContext {
+		args = append([]reflect.Value{reflect.ValueOf(&ctx).Elem()}, args...)
+	}
 	result := this.value.Call(args)
+	if this.returnErr {
+		last := result[len(result)-1]
+		result = result[:len(result)-1]
+		if !last.IsNil() {
+			return result, last.Interface().(error)
+		}
+	}
 	return result, nil
 }
 
+// 将方法的返回值编码为ROC调用的返回数据
+func (this *Method) EncodeResult(c codec.Codec,
+	result []reflect.Value) ([]byte, error) {
+	callResult := make(CallResult, len(result))
+	for i, v := range result {
+		b, err := c.Marshal(v.Interface())
+		if err != nil {
+			return nil, err
+		}
+		callResult[i] = b
+	}
+	return marshalValues(c, callResult)
+}
+
+// 该方法是否是流式调用的方法
+func (this *Method) IsStream() bool {
+	return this.isStream
+}
+
 // 获取当前方法的名字。
 func (this *Method) GetName() string {
 	return this.name
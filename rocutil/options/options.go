@@ -10,17 +10,22 @@ package options
 
 import (
 	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/rocutil/codec"
 )
 
 type Options struct {
 	// 检查ROC调用的函数名称，并且返回一个最终的名称以及是否使用它
 	CheckFuncName func(method string) (string, bool)
-	// 在ROC被调用前，会执行该函数，你可以在这里完成一些保证同步的操作，如加锁
+	// 在ROC被调用前，会执行该函数。同一个ROC对象的调用总是按顺序执行的，
+	// 只有对象的状态会在ROC调用之外被访问时，才需要在这里完成加锁等同步操作
 	OnBeforeROCCall func(obj interface{}, callpath *roc.ROCPath,
 		arg []byte)
-	// 在ROC被调用后，会执行该函数，你可以在这里完成一些保证同步的操作，如加锁
+	// 在ROC被调用后，会执行该函数，你可以在这里释放 OnBeforeROCCall 中加的锁
 	OnAfterROCCall func(obj interface{}, callpath *roc.ROCPath,
 		arg []byte)
+	// 发起ROC调用时使用的参数编解码器，默认为 codec.JSON ，
+	// 被调用方会根据调用参数中的编解码器标记使用相同的编解码器
+	Codec codec.Codec
 }
 
 func (this *Options) Merge(opt *Options) {
@@ -36,4 +41,7 @@ func (this *Options) Merge(opt *Options) {
 	if opt.OnAfterROCCall != nil {
 		this.OnAfterROCCall = opt.OnAfterROCCall
 	}
+	if opt.Codec != nil {
+		this.Codec = opt.Codec
+	}
 }
@@ -0,0 +1,61 @@
+// Copyright 2019 The Misserver Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE.txt file.
+
+/*
+ * @Author: Jansen
+ */
+
+package rocutil
+
+import (
+	"github.com/liasece/micserver/rocutil/codec"
+)
+
+// ROC调用的返回值，由 CallBlock 等阻塞调用返回
+type Result struct {
+	data  CallResult
+	codec codec.Codec
+}
+
+// 解码ROC调用返回的数据
+func decodeResult(b []byte) (*Result, error) {
+	values, c, err := unmarshalValues(b)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		data:  values,
+		codec: c,
+	}, nil
+}
+
+// 返回值的数量
+func (this *Result) Len() int {
+	return len(this.data)
+}
+
+// 将第 i 个返回值解码到 ptr 中， ptr 必须是指针
+func (this *Result) DecodeAt(i int, ptr interface{}) error {
+	if i < 0 || i >= len(this.data) {
+		return ErrResultNumMismatch
+	}
+	return this.codec.Unmarshal(this.data[i], ptr)
+}
+
+// 按顺序将返回值解码到 ptrs 中，为 nil 的指针对应的返回值将被忽略，
+// ptrs 的数量不可以多于返回值的数量
+func (this *Result) Decode(ptrs ...interface{}) error {
+	if len(ptrs) > len(this.data) {
+		return ErrResultNumMismatch
+	}
+	for i, ptr := range ptrs {
+		if ptr == nil {
+			continue
+		}
+		if err := this.DecodeAt(i, ptr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -12,9 +12,10 @@ roc对象构造及调用的简单使用工具，基于反射实现，性能敏
 package rocutil
 
 import (
-	"encoding/json"
+	"context"
 
 	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/rocutil/codec"
 	"github.com/liasece/micserver/rocutil/options"
 )
 
@@ -49,19 +50,133 @@ func ServerROCObj(rocserver roc.IROCServer, obj interface{},
 // 必须由 rocutil 实现。
 func CallNR(rocServer roc.IROCServer, typ roc.ROCObjType, objID string,
 	funcName string, args ...interface{}) error {
+	return CallNRWithOptions(rocServer, nil, typ, objID, funcName, args...)
+}
+
+// 同 CallNR ，使用 opts 中指定的编解码器等选项
+func CallNRWithOptions(rocServer roc.IROCServer, opts *options.Options,
+	typ roc.ROCObjType, objID string, funcName string,
+	args ...interface{}) error {
+	b, err := encodeCallArg(opts, args)
+	if err != nil {
+		return err
+	}
+	return rocServer.ROCCallNR(roc.O(typ, objID).F(funcName), b)
+}
+
+// call remote object function and wait for the results
+// 阻塞调用一个由 rocutil 创建的ROC对象，返回值可以通过 Result.Decode 解码，
+// 如果目标方法的最后一个返回值是 error ，它将作为本函数的错误返回。
+func CallBlock(rocServer roc.IROCServer, typ roc.ROCObjType, objID string,
+	funcName string, args ...interface{}) (*Result, error) {
+	return CallBlockWithOptions(rocServer, nil, typ, objID, funcName,
+		args...)
+}
+
+// 同 CallBlock ，使用 opts 中指定的编解码器等选项
+func CallBlockWithOptions(rocServer roc.IROCServer, opts *options.Options,
+	typ roc.ROCObjType, objID string, funcName string,
+	args ...interface{}) (*Result, error) {
+	b, err := encodeCallArg(opts, args)
+	if err != nil {
+		return nil, err
+	}
+	resb, err := rocServer.ROCCallBlock(roc.O(typ, objID).F(funcName), b)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResult(resb)
+}
+
+// 同 CallBlock ，在 ctx 超时或被取消时不再等待返回值
+func CallContext(ctx context.Context, rocServer roc.IROCServer,
+	typ roc.ROCObjType, objID string, funcName string,
+	args ...interface{}) (*Result, error) {
+	return CallContextWithOptions(ctx, rocServer, nil, typ, objID, funcName,
+		args...)
+}
+
+// 同 CallContext ，使用 opts 中指定的编解码器等选项
+func CallContextWithOptions(ctx context.Context, rocServer roc.IROCServer,
+	opts *options.Options, typ roc.ROCObjType, objID string,
+	funcName string, args ...interface{}) (*Result, error) {
+	b, err := encodeCallArg(opts, args)
+	if err != nil {
+		return nil, err
+	}
+	resb, err := rocServer.ROCCallContext(ctx,
+		roc.O(typ, objID).F(funcName), b)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResult(resb)
+}
+
+// 异步调用一个由 rocutil 创建的ROC对象，立即返回调用结果的 roc.Future ，
+// 在 Future.OnComplete 的回调中可以通过 DecodeAsyncResult 解码结果
+func CallAsync(rocServer roc.IROCServer, typ roc.ROCObjType, objID string,
+	funcName string, args ...interface{}) *roc.Future {
+	return CallAsyncWithOptions(rocServer, nil, typ, objID, funcName,
+		args...)
+}
+
+// 同 CallAsync ，使用 opts 中指定的编解码器等选项
+func CallAsyncWithOptions(rocServer roc.IROCServer, opts *options.Options,
+	typ roc.ROCObjType, objID string, funcName string,
+	args ...interface{}) *roc.Future {
+	b, err := encodeCallArg(opts, args)
+	if err != nil {
+		future := roc.NewFuture()
+		future.Complete(nil, err)
+		return future
+	}
+	return rocServer.ROCCallAsync(roc.O(typ, objID).F(funcName), b)
+}
+
+// 解码异步调用的结果，调用失败时返回其错误
+func DecodeAsyncResult(data []byte, err error) (*Result, error) {
+	if err != nil {
+		return nil, err
+	}
+	return decodeResult(data)
+}
+
+// 批量调用一个类型所有已知的由 rocutil 创建的ROC对象，mopts 为 nil 时不等待返回值，
+// 每个对象的返回值可以通过 DecodeMulticastResult 解码
+func Multicast(ctx context.Context, rocServer roc.IROCServer,
+	typ roc.ROCObjType, funcName string, mopts *roc.MulticastOptions,
+	args ...interface{}) (*roc.MulticastReport, error) {
+	b, err := encodeCallArg(nil, args)
+	if err != nil {
+		return nil, err
+	}
+	return rocServer.ROCMulticast(ctx, typ, funcName, b, mopts)
+}
+
+// 解码批量调用中一个对象的返回值，该对象调用失败时返回其错误
+func DecodeMulticastResult(res *roc.MulticastResult) (*Result, error) {
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return decodeResult(res.Data)
+}
+
+// 编码调用参数列表
+func encodeCallArg(opts *options.Options, args []interface{}) ([]byte,
+	error) {
+	// default encoder is encoding/json
+	c := codec.JSON
+	if opts != nil && opts.Codec != nil {
+		c = opts.Codec
+	}
 	callArg := &CallArg{}
 	for _, arg := range args {
-		// default encoder is encoding/json
-		b, err := json.Marshal(arg)
+		b, err := c.Marshal(arg)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		callArg.Add(b)
 	}
 	// outermost arg marshal
-	b, err := json.Marshal(callArg)
-	if err != nil {
-		return err
-	}
-	return rocServer.ROCCallNR(roc.O(typ, objID).F(funcName), b)
+	return marshalValues(c, *callArg)
 }
@@ -0,0 +1,90 @@
+// Copyright 2019 The Misserver Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE.txt file.
+
+/*
+ * @Author: Jansen
+ */
+
+package rocutil
+
+import (
+	"context"
+
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/rocutil/codec"
+	"github.com/liasece/micserver/rocutil/options"
+)
+
+// 由 rocutil 创建的ROC对象处理流式调用时，向调用方发送数据的写入器，
+// 每一帧数据使用调用方的编解码器编码
+type StreamWriter struct {
+	w     roc.StreamWriter
+	codec codec.Codec
+}
+
+// 编码并发送一帧数据，调用方可以通过 Result.Decode 按顺序解码这些值。
+// 调用方的接收窗口已满时阻塞，调用方取消时返回错误
+func (this *StreamWriter) Send(values ...interface{}) error {
+	frame := make(CallResult, len(values))
+	for i, v := range values {
+		b, err := this.codec.Marshal(v)
+		if err != nil {
+			return err
+		}
+		frame[i] = b
+	}
+	b, err := marshalValues(this.codec, frame)
+	if err != nil {
+		return err
+	}
+	return this.w.Send(b)
+}
+
+// 调用方取消或者流结束时该 context 被取消
+func (this *StreamWriter) Context() context.Context {
+	return this.w.Context()
+}
+
+// 流式调用一个由 rocutil 创建的ROC对象时，接收数据的读取器
+type StreamReader struct {
+	r roc.StreamReader
+}
+
+// 按顺序接收一帧数据，流正常结束时返回 io.EOF ，目标方法返回错误时返回该错误
+func (this *StreamReader) Recv() (*Result, error) {
+	b, err := this.r.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return decodeResult(b)
+}
+
+// 不再接收数据，目标方法中的写入器将返回错误
+func (this *StreamReader) Close() {
+	this.r.Close()
+}
+
+// 流式调用一个由 rocutil 创建的ROC对象，目标方法在 context.Context 之后的第一个
+// 参数需要是 *StreamWriter ，目标方法返回时流结束
+func Stream(ctx context.Context, rocServer roc.IROCServer,
+	typ roc.ROCObjType, objID string, funcName string,
+	args ...interface{}) (*StreamReader, error) {
+	return StreamWithOptions(ctx, rocServer, nil, typ, objID, funcName,
+		args...)
+}
+
+// 同 Stream ，使用 opts 中指定的编解码器等选项
+func StreamWithOptions(ctx context.Context, rocServer roc.IROCServer,
+	opts *options.Options, typ roc.ROCObjType, objID string,
+	funcName string, args ...interface{}) (*StreamReader, error) {
+	b, err := encodeCallArg(opts, args)
+	if err != nil {
+		return nil, err
+	}
+	r, err := rocServer.ROCStream(ctx, roc.O(typ, objID).F(funcName), b)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamReader{r: r}, nil
+}
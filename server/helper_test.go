@@ -0,0 +1,220 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liasece/micserver/conf"
+	"github.com/liasece/micserver/log"
+	"github.com/liasece/micserver/roc"
+)
+
+func TestMain(m *testing.M) {
+	log.SetLogLevelByStr("fatal")
+	os.Exit(m.Run())
+}
+
+// 测试中ROC调用默认的超时时间，调用没有按预期返回时测试失败而不是一直等待
+const testCallTimeout = 5000
+
+// 测试模块ID及ROC类型的序号，所有测试共用进程中的ROC缓存及进程内的连接，
+// 模块ID及ROC类型不能重复
+var testModuleSeq int64
+
+// 创建一个测试模块的服务，测试模块之间使用进程内的 chan 连接
+func newTestServer(t *testing.T, moduletype string,
+	settings conf.BaseConfig) *Server {
+	moduleid := fmt.Sprintf("%s%d", moduletype,
+		atomic.AddInt64(&testModuleSeq, 1))
+	if settings == nil {
+		settings = conf.BaseConfig{}
+	}
+	if _, ok := settings[string(conf.ROCCallTimeout)]; !ok {
+		settings[string(conf.ROCCallTimeout)] = testCallTimeout
+	}
+	s := &Server{}
+	s.SetLogger(log.GetDefaultLogger())
+	s.Init(moduleid)
+	s.InitSubnet(&conf.ModuleConfig{
+		ID:          moduleid,
+		Settings:    &settings,
+		AppSettings: conf.NewBaseConfig(),
+	})
+	t.Cleanup(s.Stop)
+	return s
+}
+
+// 连接测试模块，等待所有模块都加入了彼此的子网
+func connectTestServers(t *testing.T, servers ...*Server) {
+	t.Helper()
+	for i, s := range servers {
+		for _, target := range servers[i+1:] {
+			s.subnetManager.TryConnectServer(target.moduleid, "")
+		}
+	}
+	waitTest(t, "servers connected", func() bool {
+		for _, s := range servers {
+			for _, target := range servers {
+				if target == s {
+					continue
+				}
+				conn := s.subnetManager.GetServer(target.moduleid)
+				if conn == nil || conn.ModuleInfo == nil ||
+					conn.ModuleInfo.ModuleID != target.moduleid {
+					return false
+				}
+			}
+		}
+		return true
+	})
+}
+
+// 生成测试使用的ROC类型，所有测试共用进程中的ROC缓存，每次运行使用不同的类型
+func newTestObjType(name string) roc.ROCObjType {
+	return roc.ROCObjType(fmt.Sprintf("%s%d", name,
+		atomic.AddInt64(&testModuleSeq, 1)))
+}
+
+// 等待条件成立，超时后测试失败
+func waitTest(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// 测试使用的ROC对象，参数及返回值均为十进制整数的字符串。
+// Add 累加参数并返回结果， Get 返回当前值， Echo 原样返回参数，
+// Wait 阻塞直到 wait 被关闭， Panic 使调用处理 panic
+type testObj struct {
+	objType roc.ROCObjType
+	objID   string
+	wait    chan struct{}
+
+	mutex sync.Mutex
+	n     int
+	// 调用过的函数及参数，按调用顺序记录
+	calls []string
+}
+
+func newTestObj(objType roc.ROCObjType, objID string, n int) *testObj {
+	return &testObj{
+		objType: objType,
+		objID:   objID,
+		n:       n,
+		wait:    make(chan struct{}),
+	}
+}
+
+func (this *testObj) GetROCObjType() roc.ROCObjType {
+	return this.objType
+}
+
+func (this *testObj) GetROCObjID() string {
+	return this.objID
+}
+
+func (this *testObj) OnROCCall(path *roc.ROCPath,
+	arg []byte) ([]byte, error) {
+	f := path.Move()
+	if f != "Echo" {
+		this.record(f + " " + string(arg))
+	}
+	switch f {
+	case "Add":
+		a, err := strconv.Atoi(string(arg))
+		if err != nil {
+			return nil, err
+		}
+		this.mutex.Lock()
+		this.n += a
+		n := this.n
+		this.mutex.Unlock()
+		return []byte(strconv.Itoa(n)), nil
+	case "Get":
+		this.mutex.Lock()
+		n := this.n
+		this.mutex.Unlock()
+		return []byte(strconv.Itoa(n)), nil
+	case "Echo":
+		return arg, nil
+	case "Wait":
+		<-this.wait
+		return nil, nil
+	case "Panic":
+		panic("test panic")
+	}
+	return nil, fmt.Errorf("unknown function name %s", f)
+}
+
+func (this *testObj) MarshalROCState() ([]byte, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return []byte(strconv.Itoa(this.n)), nil
+}
+
+// 记录一次调用
+func (this *testObj) record(call string) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.calls = append(this.calls, call)
+}
+
+// 获取调用过的函数及参数
+func (this *testObj) getCalls() []string {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return append([]string(nil), this.calls...)
+}
+
+// 等待对象处理到指定的调用
+func (this *testObj) waitCall(t *testing.T, call string) {
+	t.Helper()
+	waitTest(t, call, func() bool {
+		for _, v := range this.getCalls() {
+			if v == call {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// 从状态中加载 testObj 的对象加载器
+func loadTestObj(objType roc.ROCObjType) roc.ObjLoader {
+	return func(objID string, state []byte) (roc.IObj, error) {
+		n, err := strconv.Atoi(string(state))
+		if err != nil {
+			return nil, err
+		}
+		return newTestObj(objType, objID, n), nil
+	}
+}
+
+// 调用测试对象并将返回值解析为整数
+func callTestInt(s *Server, path *roc.ROCPath, arg string) (int, error) {
+	res, err := s.ROCCallBlock(path, []byte(arg))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(res))
+}
+
+// 在新的协程中调用测试对象，调用结果写入返回的 chan
+func goCallTest(s *Server, path *roc.ROCPath, arg string) chan error {
+	res := make(chan error, 1)
+	go func() {
+		_, err := s.ROCCallBlock(path, []byte(arg))
+		res <- err
+	}()
+	return res
+}
@@ -0,0 +1,254 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/liasece/micserver/conf"
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/servercomm"
+)
+
+// 判断本模块是否可以按需激活该类型的ROC对象
+func (this *ROCServer) canActivate(objType roc.ROCObjType) bool {
+	r := this.GetROC(objType)
+	return r != nil && r.HasObjFactory()
+}
+
+// 所有模块都不知道目标对象的位置时，按放置规则从可以激活该对象的模块中选择一个
+// 并请求激活，返回是否已发起激活，调用方需要持有 locateMutex
+func (this *ROCServer) startActivateNoLock(locating *rocLocating,
+	objType roc.ROCObjType, objID string) bool {
+	if len(locating.candidates) == 0 {
+		return false
+	}
+	candidates := make([]string, 0, len(locating.candidates))
+	for moduleid := range locating.candidates {
+		candidates = append(candidates, moduleid)
+	}
+	sort.Strings(candidates)
+	// 调用方没有注册该类型的ROC时使用默认的放置规则
+	var target string
+	if r := this.GetROC(objType); r != nil {
+		target = r.Place(objID, candidates)
+	} else {
+		target = roc.PlaceByHash(objID, candidates)
+	}
+	if _, ok := locating.candidates[target]; !ok {
+		return false
+	}
+	req := &servercomm.SROCActivateReq{
+		FromModuleID: this.server.moduleid,
+		ToModuleID:   target,
+		Seq:          locating.seq,
+		ObjType:      string(objType),
+		ObjID:        objID,
+	}
+	// 激活在目标对象的邮箱中异步执行，不会在此时回答
+	if local := this.getLocalROCServer(target); local != nil {
+		local.onMsgROCActivateReq(req)
+	} else {
+		server := this.server.subnetManager.GetServer(target)
+		if server == nil {
+			return false
+		}
+		server.SendCmd(req)
+	}
+	locating.activating = target
+	this.Syslog("locateROCObj %s activate on %s",
+		roc.O(objType, objID).String(), target)
+	return true
+}
+
+// 当收到按需激活ROC对象的请求时，在目标对象的邮箱中激活该对象，
+// 对象已存在或已迁出时回答其位置
+func (this *ROCServer) onMsgROCActivateReq(msg *servercomm.SROCActivateReq) {
+	objType := roc.ROCObjType(msg.ObjType)
+	this.pushROCRequest(&requestAgent{
+		objType: objType,
+		objID:   msg.ObjID,
+		fn: func() {
+			host, token := this.getLocalObjHost(objType, msg.ObjID)
+			var err error
+			if host == "" {
+				err = this.activateROCObj(objType, msg.ObjID)
+				if err == nil {
+					host = this.server.moduleid
+					token = this.getLocalObjToken(objType, msg.ObjID)
+				}
+			}
+			code, errMsg, _ := roc.ErrorToCode(err)
+			this.sendROCActivateRes(&servercomm.SROCActivateRes{
+				FromModuleID: this.server.moduleid,
+				ToModuleID:   msg.FromModuleID,
+				Seq:          msg.Seq,
+				ObjType:      msg.ObjType,
+				ObjID:        msg.ObjID,
+				HostModuleID: host,
+				Token:        token,
+				Error:        errMsg,
+				ErrCode:      int32(code),
+			})
+		},
+	})
+}
+
+// 使用对象工厂激活一个ROC对象并注册到本模块，对象实现了 roc.Activatable 接口时，
+// 在注册前调用其 OnActivate
+func (this *ROCServer) activateROCObj(objType roc.ROCObjType,
+	objID string) error {
+	r := this.GetROC(objType)
+	if r == nil {
+		return roc.ErrUnregisterROC
+	}
+	obj, err := r.NewObj(objID)
+	if err != nil {
+		return err
+	}
+	if obj == nil || obj.GetROCObjType() != objType ||
+		obj.GetROCObjID() != objID {
+		return fmt.Errorf("roc obj factory returned a mismatched obj for "+
+			"%s[%s]", objType, objID)
+	}
+	if a, ok := obj.(roc.Activatable); ok {
+		if err := a.OnActivate(); err != nil {
+			this.Warn("activateROCObj %s[%s] OnActivate err:%s",
+				objType, objID, err.Error())
+			return err
+		}
+	}
+	r.GetOrRegObj(objID, obj)
+	this.Syslog("activateROCObj %s[%s]", objType, objID)
+	return nil
+}
+
+// 向发起激活的模块回答激活的结果
+func (this *ROCServer) sendROCActivateRes(res *servercomm.SROCActivateRes) {
+	if local := this.getLocalROCServer(res.ToModuleID); local != nil {
+		local.onMsgROCActivateRes(res)
+		return
+	}
+	server := this.server.subnetManager.GetServer(res.ToModuleID)
+	if server == nil {
+		return
+	}
+	server.SendCmd(res)
+}
+
+// 当收到按需激活ROC对象的结果时，结束对应的位置查询
+func (this *ROCServer) onMsgROCActivateRes(msg *servercomm.SROCActivateRes) {
+	objType := roc.ROCObjType(msg.ObjType)
+	if msg.HostModuleID != "" {
+		roc.GetCache().SetToken(objType, msg.ObjID, msg.HostModuleID,
+			msg.Token)
+	}
+	key := roc.O(objType, msg.ObjID).String()
+	this.locateMutex.Lock()
+	defer this.locateMutex.Unlock()
+	locating, ok := this.locating[key]
+	if !ok || locating.seq != msg.Seq {
+		return
+	}
+	locating.host = msg.HostModuleID
+	locating.token = msg.Token
+	locating.err = roc.CodeToError(roc.ErrCode(msg.ErrCode), msg.Error, nil)
+	this.Syslog("locateROCObj %s activated host[%s]", key, locating.host)
+	delete(this.locating, key)
+	close(locating.done)
+}
+
+// 记录本模块中可以按需激活的类型的ROC对象，空闲超时后将被停用
+func (this *ROCServer) trackIdleObj(objType roc.ROCObjType, objID string,
+	isDelete bool) {
+	if !isDelete && !this.canActivate(objType) {
+		return
+	}
+	key := roc.O(objType, objID).String()
+	this.activeMutex.Lock()
+	defer this.activeMutex.Unlock()
+	if isDelete {
+		delete(this.activeTime, key)
+		return
+	}
+	if this.activeTime == nil {
+		this.activeTime = make(map[string]time.Time)
+	}
+	this.activeTime[key] = time.Now()
+}
+
+// 刷新ROC对象最后一次处理调用的时间
+func (this *ROCServer) touchIdleObj(objType roc.ROCObjType, objID string) {
+	key := roc.O(objType, objID).String()
+	this.activeMutex.Lock()
+	defer this.activeMutex.Unlock()
+	if _, ok := this.activeTime[key]; ok {
+		this.activeTime[key] = time.Now()
+	}
+}
+
+// 停用本模块中空闲超过 conf.ROCIdleTTL 的按需激活类型的ROC对象，
+// 对象实现了 roc.Deactivatable 接口时先调用其 OnDeactivate ，之后从ROC中删除。
+// 停用在对象的邮箱中执行，不会与该对象的调用同时发生，正在迁移的对象不会被停用
+func (this *ROCServer) DeactivateIdleROCObj() {
+	ttl := time.Duration(this.server.moduleConfig.GetInt64(
+		conf.ROCIdleTTL)) * time.Second
+	if ttl <= 0 {
+		return
+	}
+	idle := make([]string, 0)
+	this.activeMutex.Lock()
+	for key, t := range this.activeTime {
+		if time.Since(t) > ttl {
+			idle = append(idle, key)
+		}
+	}
+	this.activeMutex.Unlock()
+	for _, key := range idle {
+		path, err := roc.ParseROCPath(key)
+		if err != nil {
+			continue
+		}
+		objType, objID := path.GetObjType(), path.GetObjID()
+		this.pushROCRequest(&requestAgent{
+			objType: objType,
+			objID:   objID,
+			fn: func() {
+				this.deactivateROCObj(objType, objID, ttl)
+			},
+		})
+	}
+}
+
+// 停用一个空闲的ROC对象，在对象的邮箱中执行
+func (this *ROCServer) deactivateROCObj(objType roc.ROCObjType,
+	objID string, ttl time.Duration) {
+	key := roc.O(objType, objID).String()
+	// 排队期间可能处理了新的调用
+	this.activeMutex.Lock()
+	t, ok := this.activeTime[key]
+	this.activeMutex.Unlock()
+	if !ok || time.Since(t) <= ttl {
+		return
+	}
+	this.migrateMutex.Lock()
+	_, migrating := this.migratingObj[migrateKey(objType, objID)]
+	this.migrateMutex.Unlock()
+	if migrating {
+		return
+	}
+	r := this.GetROC(objType)
+	if r == nil {
+		return
+	}
+	obj, ok := r.GetObj(objID)
+	if !ok || obj == nil {
+		this.trackIdleObj(objType, objID, true)
+		return
+	}
+	if d, ok := obj.(roc.Deactivatable); ok {
+		d.OnDeactivate()
+	}
+	r.DelObjByID(objID)
+	this.Syslog("deactivateROCObj %s idle for %s", key, time.Since(t))
+}
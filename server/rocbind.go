@@ -0,0 +1,169 @@
+package server
+
+import (
+	"github.com/liasece/micserver/base"
+	"github.com/liasece/micserver/connect"
+	"github.com/liasece/micserver/process"
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/servercomm"
+)
+
+// 向目标服务器发送本模块ROC绑定信息的版本
+func (this *ROCServer) sendROCBindVersion(server *connect.Server) {
+	this.bindMutex.Lock()
+	defer this.bindMutex.Unlock()
+	server.SendCmd(&servercomm.SROCBindVersion{
+		HostModuleID: this.server.moduleid,
+		Epoch:        this.bindEpoch,
+		Version:      this.bindVersion,
+	})
+}
+
+// 当与一个服务器的所有连接都已断开时，在重新同步之前，该模块上的对象不会再被查询到。
+// 缓存由本进程中的所有模块共用，本进程中还有其他模块与之连接时，该模块的绑定信息
+// 仍然会被同步到缓存中，不需要标记为可疑
+func (this *ROCServer) onServerLeaveSubnet(server *connect.Server) {
+	moduleid := server.ModuleInfo.ModuleID
+	this.onOrderModuleLeave(moduleid)
+	this.onLocateModuleLeave(moduleid)
+	this.onStreamModuleLeave(moduleid)
+	this.onCallModuleLeave(moduleid)
+	this.onStatsModuleLeave(moduleid)
+	this.onChunkModuleLeave(moduleid)
+	this.onPlacementModuleChange(moduleid, true)
+	if process.HasModule(moduleid) {
+		return
+	}
+	this.bindSyncingMutex.Lock()
+	delete(this.bindSyncing, moduleid)
+	this.bindSyncingMutex.Unlock()
+	if observer := this.getLocalObserver(moduleid); observer != "" {
+		this.Syslog("onServerLeaveSubnet host[%s] still connected to "+
+			"local module[%s]", moduleid, observer)
+		return
+	}
+	roc.GetCache().SetModuleSuspect(moduleid, true)
+	this.Syslog("onServerLeaveSubnet roc cache suspect host[%s]", moduleid)
+}
+
+// 获取本进程中除本模块外仍与目标模块连接的模块，没有时返回空字符串
+func (this *ROCServer) getLocalObserver(moduleid string) string {
+	res := ""
+	process.RangeModule(func(m base.IModule) bool {
+		getter, ok := m.(rocServerGetter)
+		if !ok {
+			return true
+		}
+		local := getter.getROCServer()
+		if local == this || local.server.subnetManager == nil ||
+			local.server.subnetManager.GetServer(moduleid) == nil {
+			return true
+		}
+		res = m.GetModuleID()
+		return false
+	})
+	return res
+}
+
+// 当收到其他模块ROC绑定信息的版本时，版本与本地记录的一致时不需要同步
+func (this *ROCServer) onMsgROCBindVersion(msg *servercomm.SROCBindVersion) {
+	if process.HasModule(msg.HostModuleID) {
+		return
+	}
+	epoch, version := roc.GetCache().GetModuleVersion(msg.HostModuleID)
+	if epoch == msg.Epoch && version == msg.Version {
+		roc.GetCache().SetModuleSuspect(msg.HostModuleID, false)
+		this.Syslog("onMsgROCBindVersion host[%s] version[%d:%d] "+
+			"up to date", msg.HostModuleID, msg.Epoch, msg.Version)
+		return
+	}
+	this.startBindSync(msg.HostModuleID)
+}
+
+// 开始与目标模块同步ROC绑定信息，同步结束时，缓存中该模块上未被同步的对象将被删除
+func (this *ROCServer) startBindSync(hostModuleID string) {
+	this.bindSyncingMutex.Lock()
+	if this.bindSyncing == nil {
+		this.bindSyncing =
+			make(map[string]map[roc.ROCObjType]map[string]struct{})
+	}
+	if _, ok := this.bindSyncing[hostModuleID]; ok {
+		this.bindSyncingMutex.Unlock()
+		return
+	}
+	this.bindSyncing[hostModuleID] =
+		make(map[roc.ROCObjType]map[string]struct{})
+	this.bindSyncingMutex.Unlock()
+
+	server := this.server.subnetManager.GetServer(hostModuleID)
+	if server == nil {
+		this.bindSyncingMutex.Lock()
+		delete(this.bindSyncing, hostModuleID)
+		this.bindSyncingMutex.Unlock()
+		return
+	}
+	this.Syslog("startBindSync host[%s]", hostModuleID)
+	server.SendCmd(&servercomm.SROCBindSyncReq{
+		FromModuleID: this.server.moduleid,
+		HostModuleID: hostModuleID,
+	})
+}
+
+// 同步期间，记录收到的目标模块上的对象，返回是否正在与该模块同步
+func (this *ROCServer) recordSyncingBind(msg *servercomm.SROCBind) bool {
+	this.bindSyncingMutex.Lock()
+	defer this.bindSyncingMutex.Unlock()
+	objs, ok := this.bindSyncing[msg.HostModuleID]
+	if !ok {
+		return false
+	}
+	objType := roc.ROCObjType(msg.ObjType)
+	ids, ok := objs[objType]
+	if !ok {
+		ids = make(map[string]struct{})
+		objs[objType] = ids
+	}
+	for _, id := range msg.ObjIDs {
+		if msg.IsDelete {
+			delete(ids, id)
+		} else {
+			ids[id] = struct{}{}
+		}
+	}
+	return true
+}
+
+// 当收到同步请求时，发送本模块所有的ROC对象，以及当前的版本
+func (this *ROCServer) onMsgROCBindSyncReq(msg *servercomm.SROCBindSyncReq) {
+	server := this.server.subnetManager.GetServer(msg.FromModuleID)
+	if server == nil {
+		return
+	}
+	this.localObjMutex.Lock()
+	defer this.localObjMutex.Unlock()
+	this.bindMutex.Lock()
+	defer this.bindMutex.Unlock()
+	this.sendLocalObjBind(server)
+	server.SendCmd(&servercomm.SROCBindSyncEnd{
+		HostModuleID: this.server.moduleid,
+		Epoch:        this.bindEpoch,
+		Version:      this.bindVersion,
+	})
+}
+
+// 当同步结束时，删除缓存中该模块上未被同步的对象
+func (this *ROCServer) onMsgROCBindSyncEnd(msg *servercomm.SROCBindSyncEnd) {
+	this.bindSyncingMutex.Lock()
+	objs, ok := this.bindSyncing[msg.HostModuleID]
+	delete(this.bindSyncing, msg.HostModuleID)
+	this.bindSyncingMutex.Unlock()
+	if !ok {
+		return
+	}
+	cache := roc.GetCache()
+	cache.Retain(msg.HostModuleID, objs)
+	cache.SetModuleVersion(msg.HostModuleID, msg.Epoch, msg.Version)
+	cache.SetModuleSuspect(msg.HostModuleID, false)
+	this.Syslog("onMsgROCBindSyncEnd host[%s] version[%d:%d]",
+		msg.HostModuleID, msg.Epoch, msg.Version)
+}
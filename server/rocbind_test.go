@@ -0,0 +1,117 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/liasece/micserver/process"
+	"github.com/liasece/micserver/roc"
+)
+
+// 注册到本进程中的测试模块
+type testModule struct {
+	*Server
+}
+
+func (this *testModule) GetModuleID() string {
+	return this.moduleid
+}
+
+func (this *testModule) GetModuleType() string {
+	return ""
+}
+
+func (this *testModule) GetModuleNum() int {
+	return 0
+}
+
+func (this *testModule) GetModuleIDHash() uint32 {
+	return 0
+}
+
+func TestROCBindLeaveAndRejoin(t *testing.T) {
+	a := newTestServer(t, "testbind", nil)
+	b := newTestServer(t, "testbind", nil)
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestBindLeaveObj")
+	b.NewROC(objType).GetOrRegObj("1", newTestObj(objType, "1", 0))
+	if got := a.GetROCCachedLocation(objType, "1"); got != b.moduleid {
+		t.Fatalf("cached location = %q, want %q", got, b.moduleid)
+	}
+
+	// 与目标模块断开后，该模块上的对象不会再被查询到
+	a.onServerLeaveSubnet(a.subnetManager.GetServer(b.moduleid))
+	if got := a.GetROCCachedLocation(objType, "1"); got != "" {
+		t.Fatalf("cached location after leave = %q, want empty", got)
+	}
+	// 重新加入后，版本一致，不需要全量同步即可恢复
+	b.onServerJoinSubnet(b.subnetManager.GetServer(a.moduleid))
+	waitTest(t, "location restored", func() bool {
+		return a.GetROCCachedLocation(objType, "1") == b.moduleid
+	})
+}
+
+func TestROCBindLeaveLocalObserver(t *testing.T) {
+	a := newTestServer(t, "testbind", nil)
+	b := newTestServer(t, "testbind", nil)
+	h := newTestServer(t, "testbind", nil)
+	process.AddModule(&testModule{a})
+	process.AddModule(&testModule{b})
+	connectTestServers(t, a, h)
+	connectTestServers(t, b, h)
+
+	objType := newTestObjType("TestBindObserverObj")
+	h.NewROC(objType).GetOrRegObj("1", newTestObj(objType, "1", 0))
+	waitTest(t, "location cached", func() bool {
+		return a.GetROCCachedLocation(objType, "1") == h.moduleid
+	})
+
+	// 本进程中还有模块与目标模块连接时，共用的缓存仍然有效
+	a.onServerLeaveSubnet(a.subnetManager.GetServer(h.moduleid))
+	if got := b.GetROCCachedLocation(objType, "1"); got != h.moduleid {
+		t.Fatalf("cached location after leave = %q, want %q", got,
+			h.moduleid)
+	}
+	if n, err := callTestInt(b, roc.O(objType, "1").F("Add"), "1"); err != nil ||
+		n != 1 {
+		t.Fatalf("Add after leave = %d, %v, want 1", n, err)
+	}
+}
+
+func TestROCBindResync(t *testing.T) {
+	a := newTestServer(t, "testbind", nil)
+	b := newTestServer(t, "testbind", nil)
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestBindResyncObj")
+	b.NewROC(objType).GetOrRegObj("1", newTestObj(objType, "1", 0))
+	cache := roc.GetCache()
+	// 缓存中残留的已不在目标模块上的对象
+	cache.Set(objType, "stale", b.moduleid)
+
+	// 模拟错过了一条绑定信息：目标模块记录了对象并推进了版本，但没有发送
+	b.recordLocalObj(string(objType), "2", 0, false)
+	b.bindMutex.Lock()
+	b.bindVersion++
+	b.bindMutex.Unlock()
+
+	// 之后的绑定信息版本不连续，触发全量同步
+	b.GetROC(objType).GetOrRegObj("3", newTestObj(objType, "3", 0))
+	waitTest(t, "missed bind synced", func() bool {
+		return cache.Get(objType, "2") == b.moduleid &&
+			cache.Get(objType, "stale") == ""
+	})
+	for _, id := range []string{"1", "3"} {
+		if got := cache.Get(objType, id); got != b.moduleid {
+			t.Fatalf("cached location of %s = %q, want %q", id, got,
+				b.moduleid)
+		}
+	}
+	epoch, version := cache.GetModuleVersion(b.moduleid)
+	b.bindMutex.Lock()
+	defer b.bindMutex.Unlock()
+	if epoch != b.bindEpoch || version != b.bindVersion {
+		t.Fatalf("cached version = %d:%d, want %d:%d", epoch, version,
+			b.bindEpoch, b.bindVersion)
+	}
+}
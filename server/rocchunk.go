@@ -0,0 +1,291 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/liasece/micserver/conf"
+	"github.com/liasece/micserver/connect"
+	"github.com/liasece/micserver/msg"
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/servercomm"
+)
+
+const (
+	// ROC调用参数及返回值默认的最大字节数
+	defaultROCMaxPayloadSize = 64 * 1024 * 1024
+	// 分片传输中每个分片携带的最大字节数，留出分片消息其他字段的空间
+	rocChunkSize = msg.MessageMaxSize / 2
+	// 未完成的分片传输在最后一次收到分片后被丢弃的时间
+	rocChunkTTL = time.Minute
+)
+
+// 接收中的一个分片传输
+type rocChunkBuffer struct {
+	moduleid string
+	msgID    uint16
+	data     []byte
+	got      []bool
+	received int32
+	// 被分片的消息的排序会话及序号
+	orderSession int64
+	orderSeq     int64
+	// 最后一次收到分片的时间
+	activeTime time.Time
+}
+
+// 获取ROC调用参数及返回值的最大字节数
+func (this *ROCServer) getMaxPayloadSize() int64 {
+	size := this.server.moduleConfig.GetInt64(conf.ROCMaxPayloadSize)
+	if size <= 0 {
+		return defaultROCMaxPayloadSize
+	}
+	return size
+}
+
+// 检查ROC调用参数或返回值的大小，超过上限时返回 roc.ErrPayloadTooLarge
+func (this *ROCServer) checkPayloadSize(size int64) error {
+	if limit := this.getMaxPayloadSize(); size > limit {
+		return roc.NewError(roc.ErrCodePayloadTooLarge,
+			fmt.Sprintf("roc payload size %d exceeds limit %d", size, limit))
+	}
+	return nil
+}
+
+// 向目标模块发送ROC消息，编码后超过单个消息大小上限的消息被分片发送。
+// 发送给同一模块的消息依次编号，目标模块按编号的顺序处理
+func (this *ROCServer) sendROCMsg(server *connect.Server,
+	sendmsg msg.MsgStruct) {
+	order := this.getROCOrderSender(server.ModuleInfo.ModuleID)
+	// 编号及发送在同一个锁中进行，同一连接上消息到达的顺序与编号一致
+	order.mutex.Lock()
+	defer order.mutex.Unlock()
+	if !setROCMsgOrder(sendmsg, order.session, order.seq+1) {
+		this.Error("sendROCMsg unsupported msg MsgID[%d]", sendmsg.GetMsgId())
+		return
+	}
+	order.seq++
+	totalSize := sendmsg.GetSize()
+	if msg.DEFAULT_MSG_HEADSIZE+totalSize < msg.MessageMaxSize {
+		server.SendCmd(sendmsg)
+		return
+	}
+	chunk := &servercomm.SROCChunk{
+		FromModuleID: this.server.moduleid,
+		TransferID:   this.newSeq(),
+		MsgID:        sendmsg.GetMsgId(),
+		TotalSize:    int64(totalSize),
+		Total:        int32((totalSize + rocChunkSize - 1) / rocChunkSize),
+		OrderSession: order.session,
+		OrderSeq:     order.seq,
+	}
+	switch m := sendmsg.(type) {
+	case *servercomm.SROCRequest:
+		chunk.ToModuleID = m.ToModuleID
+		chunk.PayloadSize = int64(len(m.CallArg))
+		chunk.CallerModuleID = m.FromModuleID
+		chunk.CallSeq = m.Seq
+	case *servercomm.SROCResponse:
+		chunk.ToModuleID = m.ToModuleID
+		chunk.PayloadSize = int64(len(m.ResData))
+		chunk.CallerModuleID = m.ToModuleID
+		chunk.CallSeq = m.ReqSeq
+	case *servercomm.SROCMulticast:
+		chunk.ToModuleID = m.ToModuleID
+		chunk.PayloadSize = int64(len(m.CallArg))
+		chunk.CallerModuleID = m.FromModuleID
+	case *servercomm.SROCStreamFrame:
+		chunk.ToModuleID = m.ToModuleID
+		chunk.PayloadSize = int64(len(m.Data))
+		chunk.CallerModuleID = m.ToModuleID
+		chunk.CallSeq = m.Seq
+	case *servercomm.SROCMigrate:
+		chunk.ToModuleID = m.ToModuleID
+		chunk.PayloadSize = int64(len(m.State))
+		chunk.CallerModuleID = m.FromModuleID
+		chunk.CallSeq = m.Seq
+	}
+	data := make([]byte, totalSize)
+	sendmsg.WriteBinary(data)
+	this.Syslog("sendROCMsg MsgID[%d] Size[%d] to %s in %d chunks",
+		chunk.MsgID, totalSize, chunk.ToModuleID, chunk.Total)
+	for i := int32(0); i < chunk.Total; i++ {
+		begin := int(i) * rocChunkSize
+		end := begin + rocChunkSize
+		if end > totalSize {
+			end = totalSize
+		}
+		part := *chunk
+		part.Index = i
+		part.Offset = int64(begin)
+		part.Data = data[begin:end]
+		server.SendCmd(&part)
+	}
+}
+
+// 当收到ROC消息的分片时，所有分片到达后重组，并按该消息的排序序号处理。
+// 同一模块的消息可能经过不同的连接及处理协程到达，因此分片可能乱序
+func (this *ROCServer) onMsgROCChunk(chunk *servercomm.SROCChunk) {
+	if err := this.checkPayloadSize(chunk.PayloadSize); err != nil {
+		// 每个传输只有一个序号为0的分片，只拒绝一次
+		if chunk.Index == 0 {
+			this.recvOrderedROCMsg(chunk.FromModuleID, chunk.OrderSession,
+				chunk.OrderSeq, func() {
+					this.rejectROCChunk(chunk, err)
+				})
+		}
+		return
+	}
+	if chunk.Total <= 0 || chunk.Index < 0 || chunk.Index >= chunk.Total ||
+		chunk.Offset < 0 || chunk.TotalSize <= 0 ||
+		chunk.TotalSize-chunk.PayloadSize > rocChunkSize ||
+		chunk.Offset+int64(len(chunk.Data)) > chunk.TotalSize {
+		this.Error("onMsgROCChunk bad chunk from %s TransferID[%d] "+
+			"Index[%d/%d] Offset[%d] Size[%d/%d]", chunk.FromModuleID,
+			chunk.TransferID, chunk.Index, chunk.Total, chunk.Offset,
+			len(chunk.Data), chunk.TotalSize)
+		return
+	}
+	this.touchROCOrder(chunk.FromModuleID, chunk.OrderSession, chunk.OrderSeq)
+	key := fmt.Sprintf("%s:%d", chunk.FromModuleID, chunk.TransferID)
+	now := time.Now()
+	var expired []*rocChunkBuffer
+	this.chunkMutex.Lock()
+	if this.chunkBuffers == nil {
+		this.chunkBuffers = make(map[string]*rocChunkBuffer)
+	}
+	buf, ok := this.chunkBuffers[key]
+	if !ok {
+		expired = this.dropExpiredChunkNoLock(now)
+		buf = &rocChunkBuffer{
+			moduleid:     chunk.FromModuleID,
+			msgID:        chunk.MsgID,
+			data:         make([]byte, chunk.TotalSize),
+			got:          make([]bool, chunk.Total),
+			orderSession: chunk.OrderSession,
+			orderSeq:     chunk.OrderSeq,
+		}
+		this.chunkBuffers[key] = buf
+	}
+	buf.activeTime = now
+	if int(chunk.Index) >= len(buf.got) ||
+		int64(len(buf.data)) != chunk.TotalSize || buf.got[chunk.Index] {
+		this.chunkMutex.Unlock()
+		this.skipExpiredChunk(expired)
+		return
+	}
+	copy(buf.data[chunk.Offset:], chunk.Data)
+	buf.got[chunk.Index] = true
+	buf.received++
+	if buf.received < int32(len(buf.got)) {
+		this.chunkMutex.Unlock()
+		this.skipExpiredChunk(expired)
+		return
+	}
+	delete(this.chunkBuffers, key)
+	this.chunkMutex.Unlock()
+	this.skipExpiredChunk(expired)
+	this.Syslog("onMsgROCChunk MsgID[%d] Size[%d] from %s reassembled",
+		buf.msgID, len(buf.data), chunk.FromModuleID)
+	this.recvOrderedROCMsg(buf.moduleid, buf.orderSession, buf.orderSeq,
+		func() {
+			this.handleROCChunkMsg(buf.msgID, buf.data)
+		})
+}
+
+// 处理重组后的ROC消息
+func (this *ROCServer) handleROCChunkMsg(msgID uint16, data []byte) {
+	switch msgID {
+	case servercomm.SROCRequestID:
+		layerMsg := &servercomm.SROCRequest{}
+		layerMsg.ReadBinary(data)
+		this.onMsgROCRequest(layerMsg)
+	case servercomm.SROCResponseID:
+		layerMsg := &servercomm.SROCResponse{}
+		layerMsg.ReadBinary(data)
+		this.onMsgROCResponse(layerMsg)
+	case servercomm.SROCMulticastID:
+		layerMsg := &servercomm.SROCMulticast{}
+		layerMsg.ReadBinary(data)
+		this.onMsgROCMulticast(layerMsg)
+	case servercomm.SROCStreamFrameID:
+		layerMsg := &servercomm.SROCStreamFrame{}
+		layerMsg.ReadBinary(data)
+		this.onMsgROCStreamFrame(layerMsg)
+	case servercomm.SROCMigrateID:
+		layerMsg := &servercomm.SROCMigrate{}
+		layerMsg.ReadBinary(data)
+		this.onMsgROCMigrate(layerMsg)
+	default:
+		this.Error("handleROCChunkMsg unsupported MsgID[%d] Size[%d]",
+			msgID, len(data))
+	}
+}
+
+// 拒绝重组超过大小上限的消息，通知调用方调用失败
+func (this *ROCServer) rejectROCChunk(chunk *servercomm.SROCChunk,
+	err error) {
+	this.Warn("ROC chunked msg MsgID[%d] from %s rejected, err:%s",
+		chunk.MsgID, chunk.FromModuleID, err.Error())
+	switch chunk.MsgID {
+	case servercomm.SROCRequestID, servercomm.SROCMigrateID:
+		// 无返回值的调用在调用方没有等待中的请求，错误将被丢弃
+		this.sendROCResponse(&requestAgent{
+			fromModuleID: chunk.CallerModuleID,
+			seq:          chunk.CallSeq,
+			needReturn:   true,
+		}, nil, err)
+	case servercomm.SROCResponseID:
+		// 本模块即为调用方
+		this.rocResponseChan <- &responseAgent{
+			fromModuleID: chunk.FromModuleID,
+			seq:          chunk.CallSeq,
+			err:          err,
+		}
+	case servercomm.SROCStreamFrameID:
+		// 本模块即为调用方，结束该流并通知被调用方取消
+		if vi, ok := this.streams.Load(chunk.CallSeq); ok {
+			reader := vi.(*rocStreamReader)
+			reader.mutex.Lock()
+			if reader.moduleid == "" {
+				reader.moduleid = chunk.FromModuleID
+			}
+			reader.mutex.Unlock()
+			reader.finish(err, true)
+		}
+	}
+}
+
+// 丢弃长时间没有收到分片的传输并返回，调用方需要持有 chunkMutex
+func (this *ROCServer) dropExpiredChunkNoLock(
+	now time.Time) (res []*rocChunkBuffer) {
+	for key, buf := range this.chunkBuffers {
+		if now.Sub(buf.activeTime) > rocChunkTTL {
+			this.Warn("ROC chunked msg %s MsgID[%d] expired, "+
+				"received %d/%d chunks", key, buf.msgID, buf.received,
+				len(buf.got))
+			delete(this.chunkBuffers, key)
+			res = append(res, buf)
+		}
+	}
+	return
+}
+
+// 被丢弃的传输不再处理，之后的消息不再等待它们，调用方不能持有 chunkMutex
+func (this *ROCServer) skipExpiredChunk(expired []*rocChunkBuffer) {
+	for _, buf := range expired {
+		this.recvOrderedROCMsg(buf.moduleid, buf.orderSession, buf.orderSeq,
+			func() {})
+	}
+}
+
+// 与一个模块的连接全部断开时，丢弃该模块未完成的分片传输
+func (this *ROCServer) onChunkModuleLeave(moduleid string) {
+	this.chunkMutex.Lock()
+	defer this.chunkMutex.Unlock()
+	for key, buf := range this.chunkBuffers {
+		if buf.moduleid == moduleid {
+			delete(this.chunkBuffers, key)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liasece/micserver/conf"
+	"github.com/liasece/micserver/msg"
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/servercomm"
+)
+
+func TestROCChunk(t *testing.T) {
+	a := newTestServer(t, "testchunk", nil)
+	b := newTestServer(t, "testchunk", nil)
+	// 参数上限大于单个消息的大小上限，但小于测试使用的参数
+	c := newTestServer(t, "testchunk", conf.BaseConfig{
+		string(conf.ROCMaxPayloadSize): msg.MessageMaxSize + 1024*1024,
+	})
+	connectTestServers(t, a, b, c)
+
+	objType := newTestObjType("TestChunkObj")
+	b.NewROC(objType).GetOrRegObj("1", newTestObj(objType, "1", 0))
+	c.NewROC(objType).GetOrRegObj("2", newTestObj(objType, "2", 0))
+
+	// 超过单个消息大小上限的参数及返回值被分片传输
+	arg := bytes.Repeat([]byte("0123456789"), (msg.MessageMaxSize+
+		2*1024*1024)/10)
+	res, err := a.ROCCallBlock(roc.O(objType, "1").F("Echo"), arg)
+	if err != nil {
+		t.Fatalf("Echo err: %v", err)
+	}
+	if !bytes.Equal(res, arg) {
+		t.Fatalf("Echo res size %d, want %d", len(res), len(arg))
+	}
+
+	// 超过本模块上限的参数在发送前被拒绝
+	if _, err := c.ROCCallBlock(roc.O(objType, "1").F("Echo"),
+		arg); !errors.Is(err, roc.ErrPayloadTooLarge) {
+		t.Fatalf("local limit err = %v, want %v", err, roc.ErrPayloadTooLarge)
+	}
+	// 超过目标模块上限的参数被目标模块拒绝
+	if _, err := a.ROCCallBlock(roc.O(objType, "2").F("Echo"),
+		arg); !errors.Is(err, roc.ErrPayloadTooLarge) {
+		t.Fatalf("remote limit err = %v, want %v", err, roc.ErrPayloadTooLarge)
+	}
+	// 之后的调用不受影响
+	if n, err := callTestInt(a, roc.O(objType, "2").F("Add"), "1"); err != nil ||
+		n != 1 {
+		t.Fatalf("Add after reject = %d, %v, want 1", n, err)
+	}
+}
+
+func TestROCChunkOrder(t *testing.T) {
+	a := newTestServer(t, "testchunk", nil)
+	b := newTestServer(t, "testchunk", nil)
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestChunkOrderObj")
+	obj := newTestObj(objType, "1", 0)
+	b.NewROC(objType).GetOrRegObj("1", obj)
+
+	// 先发送的分片传输的请求，其分片在之后发送的请求之后到达
+	session := time.Now().UnixNano()
+	arg := bytes.Repeat([]byte("0123456789"), (msg.MessageMaxSize+
+		2*1024*1024)/10)
+	big := &servercomm.SROCRequest{
+		FromModuleID: a.moduleid,
+		ToModuleID:   b.moduleid,
+		Seq:          a.newSeq(),
+		CallStr:      roc.O(objType, "1").F("Get").String(),
+		CallArg:      arg,
+		OrderSession: session,
+		OrderSeq:     1,
+	}
+	small := &servercomm.SROCRequest{
+		FromModuleID: a.moduleid,
+		ToModuleID:   b.moduleid,
+		Seq:          a.newSeq(),
+		CallStr:      roc.O(objType, "1").F("Add").String(),
+		CallArg:      []byte("1"),
+		OrderSession: session,
+		OrderSeq:     2,
+	}
+	b.recvOrderedROCMsg(a.moduleid, session, small.OrderSeq, func() {
+		b.onMsgROCRequest(small)
+	})
+	data := make([]byte, big.GetSize())
+	big.WriteBinary(data)
+	transferID := a.newSeq()
+	total := int32((len(data) + rocChunkSize - 1) / rocChunkSize)
+	for i := total - 1; i >= 0; i-- {
+		end := int(i+1) * rocChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		b.onMsgROCChunk(&servercomm.SROCChunk{
+			FromModuleID: a.moduleid,
+			ToModuleID:   b.moduleid,
+			TransferID:   transferID,
+			MsgID:        big.GetMsgId(),
+			TotalSize:    int64(len(data)),
+			PayloadSize:  int64(len(arg)),
+			Index:        i,
+			Total:        total,
+			Offset:       int64(i) * rocChunkSize,
+			Data:         data[int(i)*rocChunkSize : end],
+			OrderSession: session,
+			OrderSeq:     big.OrderSeq,
+		})
+	}
+	waitTest(t, "both requests handled", func() bool {
+		return len(obj.getCalls()) == 2
+	})
+	if calls := obj.getCalls(); !strings.HasPrefix(calls[0], "Get 0123") ||
+		calls[1] != "Add 1" {
+		t.Fatalf("calls = %.20q, want Get then Add", calls)
+	}
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/liasece/micserver/conf"
+	"github.com/liasece/micserver/roc"
+)
+
+// 默认保存ROC请求执行结果的时间及数量
+const (
+	defaultROCDedupWindow = 60 * time.Second
+	defaultROCDedupSize   = 10000
+)
+
+// 一个按幂等键保存的ROC请求的执行结果
+type rocDedupEntry struct {
+	key  string
+	time time.Time
+	data []byte
+	err  error
+}
+
+// 按幂等键去重ROC请求的缓存，只保存时间窗口内有限数量的执行结果
+type rocDedupCache struct {
+	mutex   sync.Mutex
+	entries map[string]*rocDedupEntry
+	// 按保存时间排序的执行结果，用于淘汰
+	queue []*rocDedupEntry
+}
+
+// 获取时间窗口内保存的执行结果
+func (this *rocDedupCache) get(key string,
+	window time.Duration) (*rocDedupEntry, bool) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	entry, ok := this.entries[key]
+	if !ok || time.Since(entry.time) > window {
+		return nil, false
+	}
+	return entry, true
+}
+
+// 保存一个执行结果，并淘汰超出时间窗口或数量限制的结果
+func (this *rocDedupCache) put(key string, data []byte, err error,
+	window time.Duration, size int) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.entries == nil {
+		this.entries = make(map[string]*rocDedupEntry)
+	}
+	now := time.Now()
+	entry := &rocDedupEntry{
+		key:  key,
+		time: now,
+		data: data,
+		err:  err,
+	}
+	this.entries[key] = entry
+	this.queue = append(this.queue, entry)
+	n := 0
+	for n < len(this.queue) && (len(this.queue)-n > size ||
+		now.Sub(this.queue[n].time) > window) {
+		old := this.queue[n]
+		// 同一幂等键被重新保存时，只删除最新的记录
+		if this.entries[old.key] == old {
+			delete(this.entries, old.key)
+		}
+		this.queue[n] = nil
+		n++
+	}
+	this.queue = this.queue[n:]
+}
+
+// 获取ROC请求去重的时间窗口及数量限制
+func (this *ROCServer) getDedupConfig() (time.Duration, int) {
+	window := defaultROCDedupWindow
+	if ms := this.server.moduleConfig.GetInt64(conf.ROCDedupWindow); ms > 0 {
+		window = time.Duration(ms) * time.Millisecond
+	}
+	size := defaultROCDedupSize
+	if n := this.server.moduleConfig.GetInt64(conf.ROCDedupSize); n > 0 {
+		size = int(n)
+	}
+	return window, size
+}
+
+// 去重使用的键，不同调用方的幂等键互不影响
+func dedupKey(agent *requestAgent) string {
+	return agent.fromModuleID + ":" + agent.idemKey
+}
+
+// 对已经执行过的带幂等键的请求，直接返回第一次执行的结果，返回是否已处理。
+// 同一对象的请求在其邮箱中按顺序处理，重复的请求总是在第一次执行结束后到达这里
+func (this *ROCServer) replyDedupROCRequest(agent *requestAgent) bool {
+	if agent.idemKey == "" || agent.streamWindow > 0 {
+		return false
+	}
+	window, _ := this.getDedupConfig()
+	entry, ok := this.rocDedup.get(dedupKey(agent), window)
+	if !ok {
+		return false
+	}
+	this.Syslog("ROC Request[%s] from %s is duplicate, IdemKey[%s]",
+		agent.callpath, agent.fromModuleID, agent.idemKey)
+	if agent.needReturn {
+		this.sendROCResponse(agent, entry.data, entry.err)
+	}
+	return true
+}
+
+// 保存带幂等键的请求的执行结果，目标对象不存在时没有执行，不需要保存
+func (this *ROCServer) saveDedupROCResult(agent *requestAgent, res []byte,
+	err error) {
+	if agent.idemKey == "" || errors.Is(err, roc.ErrUnknowObj) {
+		return
+	}
+	window, size := this.getDedupConfig()
+	this.rocDedup.put(dedupKey(agent), res, err, window, size)
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/liasece/micserver/roc"
+)
+
+func TestROCDedup(t *testing.T) {
+	a := newTestServer(t, "testdedup", nil)
+	b := newTestServer(t, "testdedup", nil)
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestDedupObj")
+	obj := newTestObj(objType, "1", 0)
+	b.NewROC(objType).GetOrRegObj("1", obj)
+
+	// 相同幂等键的请求只执行一次，重复的请求得到第一次执行的结果
+	ctx := roc.WithIdempotencyKey(context.Background(), "key1")
+	for i := 0; i < 2; i++ {
+		res, err := a.ROCCallContext(ctx, roc.O(objType, "1").F("Add"),
+			[]byte("1"))
+		if err != nil || string(res) != "1" {
+			t.Fatalf("Add %d = %q, %v, want 1", i, res, err)
+		}
+	}
+	// 不同调用方的幂等键互不影响
+	if res, err := b.ROCCallContext(ctx, roc.O(objType, "1").F("Add"),
+		[]byte("1")); err != nil || string(res) != "2" {
+		t.Fatalf("Add from other module = %q, %v, want 2", res, err)
+	}
+	if calls := obj.getCalls(); len(calls) != 2 {
+		t.Fatalf("calls = %q, want 2 Add", calls)
+	}
+}
+
+func TestROCRetryDedup(t *testing.T) {
+	a := newTestServer(t, "testdedup", nil)
+	b := newTestServer(t, "testdedup", nil)
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestRetryObj")
+	obj := newTestObj(objType, "1", 0)
+	b.NewROC(objType).GetOrRegObj("1", obj)
+	a.SetROCRetryPolicy(&roc.RetryPolicy{
+		MaxAttempts:    3,
+		AttemptTimeout: 100 * time.Millisecond,
+	})
+
+	// 对象阻塞期间第一次尝试超时，重试的请求与第一次请求使用相同的幂等键，
+	// 对象恢复后只执行一次
+	waitRes := goCallTest(b, roc.O(objType, "1").F("Wait"), "")
+	obj.waitCall(t, "Wait ")
+	addRes := make(chan string, 1)
+	go func() {
+		res, err := a.ROCCallBlock(roc.O(objType, "1").F("Add"), []byte("1"))
+		if err != nil {
+			t.Errorf("Add with retry err: %v", err)
+		}
+		addRes <- string(res)
+	}()
+	time.Sleep(150 * time.Millisecond)
+	close(obj.wait)
+	if err := <-waitRes; err != nil {
+		t.Fatalf("Wait err: %v", err)
+	}
+	if res := <-addRes; res != "1" {
+		t.Fatalf("Add with retry = %q, want 1", res)
+	}
+	if n, err := callTestInt(b, roc.O(objType, "1").F("Get"), ""); err != nil ||
+		n != 1 {
+		t.Fatalf("Get = %d, %v, want 1", n, err)
+	}
+	if calls := obj.getCalls(); len(calls) != 3 ||
+		calls[1] != "Add "+strconv.Itoa(1) {
+		t.Fatalf("calls = %q, want Wait, Add 1, Get", calls)
+	}
+}
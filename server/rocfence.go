@@ -0,0 +1,66 @@
+package server
+
+import (
+	"github.com/liasece/micserver/roc"
+)
+
+// 为本模块中的ROC对象取得一个不小于 minToken 的围栏令牌，将缓存指向本模块，
+// 并记录到本地对象中，调用方需要持有 localObjMutex
+func (this *ROCServer) acquireObjTokenNoLock(objType roc.ROCObjType,
+	objID string, minToken uint64) uint64 {
+	token := roc.GetCache().Acquire(objType, objID, this.server.moduleid,
+		minToken)
+	this.recordLocalObjNoLock(string(objType), objID, token, false)
+	return token
+}
+
+// 获取本模块中ROC对象注册的围栏令牌，对象不在本模块中时返回0
+func (this *ROCServer) getLocalObjToken(objType roc.ROCObjType,
+	objID string) uint64 {
+	this.localObjMutex.Lock()
+	defer this.localObjMutex.Unlock()
+	return this.localObj[string(objType)][objID]
+}
+
+// 判断本模块对目标对象的注册是否已经失效：调用方所知的令牌 reqToken 比本模块的新，
+// 或者缓存中该对象已被其他模块以更新的注册取得。失效的注册不再处理ROC调用
+func (this *ROCServer) isStaleOwner(objType roc.ROCObjType, objID string,
+	reqToken uint64) bool {
+	token := this.getLocalObjToken(objType, objID)
+	if token == 0 {
+		return false
+	}
+	if reqToken > token {
+		return true
+	}
+	moduleid, _ := roc.GetCache().GetToken(objType, objID)
+	return moduleid != "" && moduleid != this.server.moduleid
+}
+
+// 收到其他模块的绑定信息后，检查本模块持有的对象是否已被该模块取得，
+// 正在迁出的对象在迁移完成前会被目标模块取得，不需要检查
+func (this *ROCServer) checkFencedObj(objType roc.ROCObjType,
+	objIDs []string, hostModuleID string) {
+	if hostModuleID == this.server.moduleid {
+		return
+	}
+	for _, id := range objIDs {
+		token := this.getLocalObjToken(objType, id)
+		if token == 0 {
+			continue
+		}
+		this.migrateMutex.Lock()
+		_, migrating := this.migratingObj[migrateKey(objType, id)]
+		this.migrateMutex.Unlock()
+		if migrating {
+			continue
+		}
+		moduleid, hostToken := roc.GetCache().GetToken(objType, id)
+		if moduleid != hostModuleID {
+			continue
+		}
+		this.Warn("ROC obj %s[%s] is owned by %s with token %d, "+
+			"local registration with token %d is fenced",
+			objType, id, hostModuleID, hostToken, token)
+	}
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/liasece/micserver/roc"
+)
+
+func TestROCFencedOwner(t *testing.T) {
+	a := newTestServer(t, "testfence", nil)
+	b := newTestServer(t, "testfence", nil)
+	c := newTestServer(t, "testfence", nil)
+	connectTestServers(t, a, b, c)
+
+	objType := newTestObjType("TestFenceObj")
+	oldObj := newTestObj(objType, "1", 0)
+	b.NewROC(objType).GetOrRegObj("1", oldObj)
+	oldToken := b.getLocalObjToken(objType, "1")
+
+	// 其他模块以更新的令牌注册了同一个对象
+	newObj := newTestObj(objType, "1", 100)
+	c.NewROC(objType).GetOrRegObj("1", newObj)
+	if token := c.getLocalObjToken(objType, "1"); token <= oldToken {
+		t.Fatalf("new token = %d, want > %d", token, oldToken)
+	}
+	if n, err := callTestInt(a, roc.O(objType, "1").F("Add"), "1"); err != nil ||
+		n != 101 {
+		t.Fatalf("Add = %d, %v, want 101", n, err)
+	}
+
+	// 携带旧令牌到达旧模块的请求被拒绝
+	seq := a.newSeq()
+	ch := a.addBlockChan(seq)
+	b.pushROCRequest(&requestAgent{
+		fromModuleID: a.moduleid,
+		callpath:     roc.O(objType, "1").F("Add").String(),
+		callarg:      []byte("1"),
+		seq:          seq,
+		needReturn:   true,
+		caller:       &a.ROCServer,
+		token:        oldToken,
+	})
+	if res := <-ch; !errors.Is(res.err, roc.ErrStaleOwner) {
+		t.Fatalf("stale owner err = %v, want %v", res.err, roc.ErrStaleOwner)
+	}
+	if calls := oldObj.getCalls(); len(calls) != 0 {
+		t.Fatalf("stale obj calls = %q, want none", calls)
+	}
+}
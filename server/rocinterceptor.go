@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/trace"
+)
+
+// 添加服务端ROC调用拦截器，本模块中所有ROC对象的调用都将经过拦截器，
+// 先添加的拦截器在外层。从其他模块转发到本模块的调用只在本模块中被拦截一次
+func (this *ROCServer) AddROCServerInterceptor(
+	interceptors ...roc.ServerInterceptor) {
+	this.interceptorMutex.Lock()
+	defer this.interceptorMutex.Unlock()
+	res := make([]roc.ServerInterceptor, 0,
+		len(this.serverInterceptors)+len(interceptors))
+	res = append(res, this.serverInterceptors...)
+	this.serverInterceptors = append(res, interceptors...)
+}
+
+// 添加客户端ROC调用拦截器，本模块发起的 ROCCallNR 、 ROCCallBlock 及
+// ROCCallContext 调用都将经过拦截器，先添加的拦截器在外层
+func (this *ROCServer) AddROCClientInterceptor(
+	interceptors ...roc.ClientInterceptor) {
+	this.interceptorMutex.Lock()
+	defer this.interceptorMutex.Unlock()
+	res := make([]roc.ClientInterceptor, 0,
+		len(this.clientInterceptors)+len(interceptors))
+	res = append(res, this.clientInterceptors...)
+	this.clientInterceptors = append(res, interceptors...)
+}
+
+// 经过客户端拦截器发起ROC调用，并记录调用的指标及调用链片段，
+// info 中的调用方及发起调用的时间由本函数设置
+func (this *ROCServer) invokeROCCall(ctx context.Context, info *roc.CallInfo,
+	invoker roc.ClientInvoker) (res []byte, err error) {
+	info.FromModuleID = this.server.moduleid
+	info.RecvTime = time.Now()
+	pathMetrics := this.getROCPathMetrics(rocMetricsClient, info.Path)
+	start := pathMetrics.begin()
+	defer func() {
+		pathMetrics.end(start, err)
+	}()
+	ctx, span := trace.StartSpan(ctx, this.server.moduleid, "roc.call")
+	if span != nil {
+		span.SetAttr("path", info.Path.String())
+		if info.Multicast {
+			span.SetAttr("multicast", "true")
+		}
+		defer func() {
+			span.Finish(err)
+		}()
+	}
+	this.interceptorMutex.RLock()
+	interceptors := this.clientInterceptors
+	this.interceptorMutex.RUnlock()
+	if len(interceptors) == 0 {
+		return invoker(ctx, info)
+	}
+	return roc.ChainClientInterceptor(interceptors, invoker)(ctx, info)
+}
+
+// 经过服务端拦截器调用本模块中的ROC对象，并记录调用的指标及调用链片段，
+// 调用路径的 context 中携带了该片段的标识，call 为最终对目标对象的调用
+func (this *ROCServer) callROCObj(agent *requestAgent,
+	call func(*roc.ROCPath, []byte) ([]byte, error)) (res []byte,
+	err error) {
+	path := roc.NewROCPath(agent.callpath)
+	objType, funcName := path.GetObjType(), path.Get(0)
+	pathMetrics := this.getROCPathMetrics(rocMetricsServer, path)
+	pathMetrics.queue.ObserveDuration(time.Since(agent.recvTime))
+	start := pathMetrics.begin()
+	defer func() {
+		pathMetrics.end(start, err)
+		this.learnROCFunc(objType, funcName, err)
+	}()
+	ctx, span := trace.StartSpanWithParent(context.Background(),
+		this.server.moduleid, "roc.handle", agent.spanCtx)
+	if span != nil {
+		span.SetAttr("path", agent.callpath)
+		span.SetAttr("from_module_id", agent.fromModuleID)
+		span.SetAttr("queue_time", time.Since(agent.recvTime).String())
+		defer func() {
+			span.Finish(err)
+		}()
+	}
+	// 流式调用在独立的协程中执行，其他调用在调度器的工作协程中执行
+	if agent.streamWindow == 0 {
+		ctx = withROCWorker(ctx, &this.rocDispatcher)
+	}
+	path.SetContext(ctx)
+
+	this.interceptorMutex.RLock()
+	interceptors := this.serverInterceptors
+	this.interceptorMutex.RUnlock()
+	if len(interceptors) == 0 {
+		return call(path, agent.callarg)
+	}
+	info := &roc.CallInfo{
+		FromModuleID: agent.fromModuleID,
+		Path:         path,
+		Arg:          agent.callarg,
+		NeedReturn:   agent.needReturn,
+		RecvTime:     agent.recvTime,
+	}
+	return roc.ChainServerInterceptor(interceptors,
+		func(info *roc.CallInfo) ([]byte, error) {
+			return call(info.Path, info.Arg)
+		})(info)
+}
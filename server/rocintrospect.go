@@ -0,0 +1,328 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/liasece/micserver/connect"
+	"github.com/liasece/micserver/metrics"
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/servercomm"
+)
+
+// ROC管理接口查询集群ROC对象统计时等待其他模块回答的时间
+const rocStatsTimeout = 5 * time.Second
+
+// 进行中的集群ROC对象统计查询
+type rocStatsQuery struct {
+	mutex sync.Mutex
+	// 还未回答的模块
+	waiting map[string]struct{}
+	stats   map[string]*roc.ModuleStats
+	done    chan struct{}
+}
+
+// 记录一个模块的统计，所有模块都已回答时结束查询
+func (this *rocStatsQuery) finish(moduleid string, stats *roc.ModuleStats) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if _, ok := this.waiting[moduleid]; !ok {
+		return
+	}
+	delete(this.waiting, moduleid)
+	this.stats[moduleid] = stats
+	if len(this.waiting) == 0 {
+		close(this.done)
+	}
+}
+
+// 统计本模块中的ROC对象。对象数量及样本取自本模块的ROC，
+// 并与本模块记录的绑定信息及本进程的ROC缓存比较，得到不一致的对象
+func (this *ROCServer) GetLocalROCStats(
+	opts *roc.StatsOptions) *roc.ModuleStats {
+	sampleNum := opts.GetSampleNum()
+	moduleid := this.server.moduleid
+	res := &roc.ModuleStats{
+		ModuleID: moduleid,
+		Types:    make([]*roc.TypeStats, 0),
+	}
+	if opts != nil && opts.ObjType != "" {
+		res.GetTypeMust(opts.ObjType)
+	}
+
+	// ROC中注册的对象
+	objs := make(map[roc.ROCObjType]map[string]struct{})
+	this._ROCManager.RangeROC(func(objType roc.ROCObjType, r *roc.ROC) bool {
+		if !opts.Match(objType) {
+			return true
+		}
+		ids := make(map[string]struct{})
+		r.RangeObj(func(obj roc.IObj) bool {
+			ids[obj.GetROCObjID()] = struct{}{}
+			return true
+		})
+		objs[objType] = ids
+		return true
+	})
+	// 本模块记录的绑定信息
+	local := make(map[roc.ROCObjType]map[string]struct{})
+	this.localObjMutex.Lock()
+	for objType, typemap := range this.localObj {
+		if !opts.Match(roc.ROCObjType(objType)) || len(typemap) == 0 {
+			continue
+		}
+		ids := make(map[string]struct{}, len(typemap))
+		for objID := range typemap {
+			ids[objID] = struct{}{}
+		}
+		local[roc.ROCObjType(objType)] = ids
+	}
+	this.localObjMutex.Unlock()
+
+	cache := roc.GetCache()
+	for objType, ids := range objs {
+		stats := res.GetTypeMust(objType)
+		for objID := range ids {
+			stats.Objs.Add(objID, sampleNum)
+			if cache.Get(objType, objID) != moduleid {
+				stats.CacheMissing.Add(objID, sampleNum)
+			}
+			if _, ok := local[objType][objID]; !ok {
+				stats.LocalMismatch.Add(objID, sampleNum)
+			}
+		}
+	}
+	for objType, ids := range local {
+		stats := res.GetTypeMust(objType)
+		for objID := range ids {
+			if _, ok := objs[objType][objID]; !ok {
+				stats.LocalMismatch.Add(objID, sampleNum)
+			}
+		}
+	}
+	limit := map[string]bool{moduleid: true}
+	for _, objType := range cache.GetTypes() {
+		if !opts.Match(objType) {
+			continue
+		}
+		cache.RangeByType(objType, func(objID string, location string) bool {
+			if _, ok := objs[objType][objID]; !ok {
+				res.GetTypeMust(objType).CacheStale.Add(objID, sampleNum)
+			}
+			return true
+		}, limit)
+	}
+
+	// 邮箱中等待处理的请求
+	for objType, num := range this.rocDispatcher.queueDepthByType() {
+		res.Pending += num
+		if opts.Match(objType) {
+			res.GetTypeMust(objType).Pending = num
+		}
+	}
+	return res
+}
+
+// 统计子网中所有模块的ROC对象，包括本模块。已连接的其他模块通过消息查询，
+// ctx 超时或被取消时，未回答的模块在结果中记录错误原因，结果被标记为部分统计
+func (this *ROCServer) GetClusterROCStats(ctx context.Context,
+	opts *roc.StatsOptions) *roc.ClusterStats {
+	query := &rocStatsQuery{
+		waiting: make(map[string]struct{}),
+		stats:   make(map[string]*roc.ModuleStats),
+		done:    make(chan struct{}),
+	}
+	query.stats[this.server.moduleid] = this.GetLocalROCStats(opts)
+	servers := make([]*connect.Server, 0)
+	this.server.subnetManager.RangeServer(func(s *connect.Server) bool {
+		if s.ModuleInfo == nil {
+			return true
+		}
+		moduleid := s.ModuleInfo.ModuleID
+		if _, ok := query.stats[moduleid]; ok {
+			return true
+		}
+		// 本进程中的模块直接统计
+		if local := this.getLocalROCServer(moduleid); local != nil {
+			query.stats[moduleid] = local.GetLocalROCStats(opts)
+			return true
+		}
+		// 同一个模块可能存在多条连接，只向其中一条发送
+		if _, ok := query.waiting[moduleid]; !ok {
+			query.waiting[moduleid] = struct{}{}
+			servers = append(servers, s)
+		}
+		return true
+	})
+
+	if len(servers) > 0 {
+		seq := this.newSeq()
+		this.statsQueries.Store(seq, query)
+		defer this.statsQueries.Delete(seq)
+		for _, s := range servers {
+			sendmsg := &servercomm.SROCStatsReq{
+				FromModuleID: this.server.moduleid,
+				ToModuleID:   s.ModuleInfo.ModuleID,
+				Seq:          seq,
+			}
+			if opts != nil {
+				sendmsg.ObjType = string(opts.ObjType)
+				sendmsg.SampleNum = int32(opts.SampleNum)
+			}
+			s.SendCmd(sendmsg)
+		}
+		select {
+		case <-query.done:
+		case <-ctx.Done():
+		}
+	}
+
+	res := &roc.ClusterStats{
+		Modules: make([]*roc.ModuleStats, 0, len(query.stats)),
+	}
+	query.mutex.Lock()
+	for _, stats := range query.stats {
+		res.Modules = append(res.Modules, stats)
+	}
+	// 未回答的模块
+	for moduleid := range query.waiting {
+		res.Modules = append(res.Modules, &roc.ModuleStats{
+			ModuleID: moduleid,
+			Types:    make([]*roc.TypeStats, 0),
+			Error:    roc.ContextErr(ctx).Error(),
+		})
+	}
+	query.mutex.Unlock()
+	sort.Slice(res.Modules, func(i, j int) bool {
+		return res.Modules[i].ModuleID < res.Modules[j].ModuleID
+	})
+	res.Partial = len(res.Failed()) > 0
+	return res
+}
+
+// 当收到ROC对象统计查询时，回答本模块的统计
+func (this *ROCServer) onMsgROCStatsReq(msg *servercomm.SROCStatsReq) {
+	server := this.server.subnetManager.GetServer(msg.FromModuleID)
+	if server == nil {
+		return
+	}
+	stats := this.GetLocalROCStats(&roc.StatsOptions{
+		ObjType:   roc.ROCObjType(msg.ObjType),
+		SampleNum: int(msg.SampleNum),
+	})
+	data, err := json.Marshal(stats)
+	if err != nil {
+		this.Error("onMsgROCStatsReq json.Marshal err:%s", err.Error())
+		return
+	}
+	server.SendCmd(&servercomm.SROCStatsRes{
+		FromModuleID: this.server.moduleid,
+		ToModuleID:   msg.FromModuleID,
+		Seq:          msg.Seq,
+		Stats:        data,
+	})
+}
+
+// 当收到ROC对象统计查询的回答时
+func (this *ROCServer) onMsgROCStatsRes(msg *servercomm.SROCStatsRes) {
+	vi, ok := this.statsQueries.Load(msg.Seq)
+	if !ok {
+		return
+	}
+	stats := &roc.ModuleStats{}
+	if err := json.Unmarshal(msg.Stats, stats); err != nil {
+		stats = &roc.ModuleStats{
+			ModuleID: msg.FromModuleID,
+			Types:    make([]*roc.TypeStats, 0),
+			Error:    err.Error(),
+		}
+	}
+	vi.(*rocStatsQuery).finish(msg.FromModuleID, stats)
+}
+
+// 与一个模块的连接全部断开时，不再等待该模块的回答
+func (this *ROCServer) onStatsModuleLeave(moduleid string) {
+	this.statsQueries.Range(func(ki, vi interface{}) bool {
+		vi.(*rocStatsQuery).finish(moduleid, &roc.ModuleStats{
+			ModuleID: moduleid,
+			Types:    make([]*roc.TypeStats, 0),
+			Error:    roc.ErrConnLost.Error(),
+		})
+		return true
+	})
+}
+
+// 获取以 JSON 格式返回子网中所有模块的ROC对象统计的 http.Handler ，
+// 可以使用查询参数 type 指定统计的类型， sample 指定样本数量。
+// 最多等待 rocStatsTimeout ，请求被取消时立即返回，未回答的模块作为部分统计返回
+func (this *ROCServer) ROCStatsHandler() http.Handler {
+	return http.HandlerFunc(this.serveROCStats)
+}
+
+func (this *ROCServer) serveROCStats(w http.ResponseWriter, r *http.Request) {
+	opts := &roc.StatsOptions{
+		ObjType: roc.ROCObjType(r.URL.Query().Get("type")),
+	}
+	if sample := r.URL.Query().Get("sample"); sample != "" {
+		n, err := strconv.Atoi(sample)
+		if err != nil {
+			http.Error(w, "invalid sample: "+err.Error(),
+				http.StatusBadRequest)
+			return
+		}
+		opts.SampleNum = n
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), rocStatsTimeout)
+	defer cancel()
+	stats := this.GetClusterROCStats(ctx, opts)
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(stats)
+}
+
+// 启动ROC管理接口的HTTP服务，通过 /roc/stats 查询子网中ROC对象的统计，
+// 通过 /metrics 获取所有指标
+func (this *ROCServer) StartROCAdmin(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/roc/stats", this.ROCStatsHandler())
+	mux.Handle("/metrics", metrics.GetRegistry())
+	this.adminMutex.Lock()
+	if this.adminListener != nil {
+		this.adminMutex.Unlock()
+		listener.Close()
+		return fmt.Errorf("roc admin already listen on %s",
+			this.adminListener.Addr().String())
+	}
+	this.adminListener = listener
+	this.adminMutex.Unlock()
+	this.Syslog("StartROCAdmin listen on %s", listener.Addr().String())
+	go func() {
+		err := http.Serve(listener, mux)
+		if !this.server.isStop {
+			this.Error("StartROCAdmin serve %s err:%s", addr, err.Error())
+		}
+	}()
+	return nil
+}
+
+// 停止ROC管理接口的HTTP服务
+func (this *ROCServer) stopROCAdmin() {
+	this.adminMutex.Lock()
+	defer this.adminMutex.Unlock()
+	if this.adminListener != nil {
+		this.adminListener.Close()
+		this.adminListener = nil
+	}
+}
@@ -0,0 +1,253 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/liasece/micserver/conf"
+	"github.com/liasece/micserver/connect"
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/servercomm"
+)
+
+const (
+	// 查询后确认不存在的ROC对象的默认缓存时间
+	rocLocateMissTTL = time.Second
+	// 无返回值的ROC调用查询目标对象位置的默认超时时间
+	rocLocateTimeout = 5 * time.Second
+)
+
+// 一次进行中的ROC对象位置查询
+type rocLocating struct {
+	seq int64
+	// 尚未回答的模块
+	waiting map[string]struct{}
+	// 查询到的对象所在的模块，为空表示不存在，以及该注册的围栏令牌
+	host  string
+	token uint64
+	// 可以按需激活该对象的模块，以及已请求激活该对象的模块
+	candidates map[string]struct{}
+	activating string
+	// 激活失败的原因
+	err  error
+	done chan struct{}
+}
+
+// 获取目标对象所在的模块及该注册的围栏令牌，缓存中不存在时向所有已连接的模块查询，
+// 并将结果写入缓存，同一对象同时只会进行一次查询。所有模块都回答不知道时，
+// 如果有模块可以按需激活该类型的对象，按放置规则选择其中一个模块激活该对象，
+// 否则返回 roc.ErrUnknowObj ，该结果会被缓存一段时间，见 conf.ROCLocateMissTTL
+func (this *ROCServer) locateROCObj(ctx context.Context,
+	objType roc.ROCObjType, objID string) (string, uint64, error) {
+	cache := roc.GetCache()
+	if moduleid, token := cache.GetToken(objType, objID); moduleid != "" {
+		return moduleid, token, nil
+	}
+	if cache.IsMiss(objType, objID) {
+		return "", 0, roc.ErrUnknowObj
+	}
+	// 对象可能刚刚在本模块中创建，还未写入缓存
+	if host, token := this.getLocalObjHost(objType, objID); host != "" {
+		return host, token, nil
+	}
+
+	key := roc.O(objType, objID).String()
+	this.locateMutex.Lock()
+	if this.locating == nil {
+		this.locating = make(map[string]*rocLocating)
+	}
+	locating, ok := this.locating[key]
+	servers := make([]*connect.Server, 0)
+	if !ok {
+		locating = &rocLocating{
+			seq:        this.newSeq(),
+			waiting:    make(map[string]struct{}),
+			candidates: make(map[string]struct{}),
+			done:       make(chan struct{}),
+		}
+		if this.canActivate(objType) {
+			locating.candidates[this.server.moduleid] = struct{}{}
+		}
+		host, token := "", uint64(0)
+		this.server.subnetManager.RangeServer(func(s *connect.Server) bool {
+			if s.ModuleInfo == nil {
+				return true
+			}
+			moduleid := s.ModuleInfo.ModuleID
+			// 本进程中的模块直接查询
+			if local := this.getLocalROCServer(moduleid); local != nil {
+				host, token = local.getLocalObjHost(objType, objID)
+				if local.canActivate(objType) {
+					locating.candidates[moduleid] = struct{}{}
+				}
+				return host == ""
+			}
+			// 同一个模块可能存在多条连接，只向其中一条发送
+			if _, ok := locating.waiting[moduleid]; !ok {
+				locating.waiting[moduleid] = struct{}{}
+				servers = append(servers, s)
+			}
+			return true
+		})
+		if host != "" {
+			this.locateMutex.Unlock()
+			cache.SetToken(objType, objID, host, token)
+			return host, token, nil
+		}
+		if len(servers) == 0 &&
+			!this.startActivateNoLock(locating, objType, objID) {
+			this.locateMutex.Unlock()
+			cache.SetMiss(objType, objID, this.getLocateMissTTL())
+			return "", 0, roc.ErrUnknowObj
+		}
+		this.locating[key] = locating
+	}
+	this.locateMutex.Unlock()
+
+	if len(servers) > 0 {
+		this.Syslog("locateROCObj %s send to %d module", key, len(servers))
+		sendmsg := &servercomm.SROCLocateReq{
+			FromModuleID: this.server.moduleid,
+			Seq:          locating.seq,
+			ObjType:      string(objType),
+			ObjID:        objID,
+		}
+		for _, s := range servers {
+			s.SendCmd(sendmsg)
+		}
+	}
+
+	select {
+	case <-locating.done:
+	case <-ctx.Done():
+		// 未回答的模块可能已经失去响应，之后的查询需要重新发起
+		this.locateMutex.Lock()
+		if this.locating[key] == locating {
+			delete(this.locating, key)
+		}
+		this.locateMutex.Unlock()
+		return "", 0, roc.ContextErr(ctx)
+	}
+	if locating.err != nil {
+		return "", 0, locating.err
+	}
+	if locating.host == "" {
+		return "", 0, roc.ErrUnknowObj
+	}
+	return locating.host, locating.token, nil
+}
+
+// 获取本模块所知的目标对象的位置及该注册的围栏令牌，对象在本模块中或已从本模块
+// 迁出时有效。本模块的注册已失效时，返回缓存中取得了该对象的模块
+func (this *ROCServer) getLocalObjHost(objType roc.ROCObjType,
+	objID string) (string, uint64) {
+	if r := this.GetROC(objType); r != nil {
+		if _, ok := r.GetObj(objID); ok {
+			if this.isStaleOwner(objType, objID, 0) {
+				return roc.GetCache().GetToken(objType, objID)
+			}
+			return this.server.moduleid, this.getLocalObjToken(objType, objID)
+		}
+	}
+	this.migrateMutex.Lock()
+	defer this.migrateMutex.Unlock()
+	return this.getMigratedNoLock(migrateKey(objType, objID)), 0
+}
+
+// 获取查询后确认不存在的ROC对象的缓存时间
+func (this *ROCServer) getLocateMissTTL() time.Duration {
+	ttl := this.server.moduleConfig.GetInt64(conf.ROCLocateMissTTL)
+	if ttl <= 0 {
+		return rocLocateMissTTL
+	}
+	return time.Duration(ttl) * time.Millisecond
+}
+
+// 获取无返回值的ROC调用查询目标对象位置的超时时间
+func (this *ROCServer) getLocateTimeout() time.Duration {
+	timeout := this.server.moduleConfig.GetInt64(conf.ROCLocateTimeout)
+	if timeout <= 0 {
+		return rocLocateTimeout
+	}
+	return time.Duration(timeout) * time.Millisecond
+}
+
+// 当收到ROC对象位置查询时，回答本模块所知的该对象的位置
+func (this *ROCServer) onMsgROCLocateReq(msg *servercomm.SROCLocateReq) {
+	server := this.server.subnetManager.GetServer(msg.FromModuleID)
+	if server == nil {
+		return
+	}
+	host, token := this.getLocalObjHost(roc.ROCObjType(msg.ObjType),
+		msg.ObjID)
+	server.SendCmd(&servercomm.SROCLocateRes{
+		FromModuleID: this.server.moduleid,
+		ToModuleID:   msg.FromModuleID,
+		Seq:          msg.Seq,
+		ObjType:      msg.ObjType,
+		ObjID:        msg.ObjID,
+		HostModuleID: host,
+		Token:        token,
+		Activatable:  this.canActivate(roc.ROCObjType(msg.ObjType)),
+	})
+}
+
+// 当收到ROC对象位置查询的回答时
+func (this *ROCServer) onMsgROCLocateRes(msg *servercomm.SROCLocateRes) {
+	objType := roc.ROCObjType(msg.ObjType)
+	if msg.HostModuleID != "" {
+		roc.GetCache().SetToken(objType, msg.ObjID, msg.HostModuleID,
+			msg.Token)
+	}
+	key := roc.O(objType, msg.ObjID).String()
+	this.locateMutex.Lock()
+	defer this.locateMutex.Unlock()
+	locating, ok := this.locating[key]
+	if !ok || locating.seq != msg.Seq {
+		return
+	}
+	delete(locating.waiting, msg.FromModuleID)
+	if msg.Activatable {
+		locating.candidates[msg.FromModuleID] = struct{}{}
+	}
+	if msg.HostModuleID != "" {
+		locating.host = msg.HostModuleID
+		locating.token = msg.Token
+	} else if len(locating.waiting) > 0 || locating.activating != "" {
+		return
+	} else if this.startActivateNoLock(locating, objType, msg.ObjID) {
+		// 等待激活的结果
+		return
+	} else {
+		roc.GetCache().SetMiss(objType, msg.ObjID, this.getLocateMissTTL())
+	}
+	this.Syslog("locateROCObj %s host[%s]", key, locating.host)
+	delete(this.locating, key)
+	close(locating.done)
+}
+
+// 与一个模块的连接全部断开时，不再等待该模块的回答，请求该模块激活的查询以
+// roc.ErrConnLost 结束
+func (this *ROCServer) onLocateModuleLeave(moduleid string) {
+	this.locateMutex.Lock()
+	defer this.locateMutex.Unlock()
+	for key, locating := range this.locating {
+		delete(locating.candidates, moduleid)
+		if locating.activating == moduleid {
+			locating.err = fmt.Errorf("%w: module %s left",
+				roc.ErrConnLost, moduleid)
+			delete(this.locating, key)
+			close(locating.done)
+			continue
+		}
+		if _, ok := locating.waiting[moduleid]; !ok {
+			continue
+		}
+		delete(locating.waiting, moduleid)
+		if len(locating.waiting) == 0 && locating.activating == "" {
+			delete(this.locating, key)
+			close(locating.done)
+		}
+	}
+}
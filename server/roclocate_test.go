@@ -0,0 +1,47 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/liasece/micserver/roc"
+)
+
+func TestLocateROCObj(t *testing.T) {
+	a := newTestServer(t, "testlocate", nil)
+	b := newTestServer(t, "testlocate", nil)
+	c := newTestServer(t, "testlocate", nil)
+	connectTestServers(t, a, b, c)
+
+	objType := newTestObjType("TestLocateObj")
+	b.NewROC(objType).GetOrRegObj("1", newTestObj(objType, "1", 0))
+	cache := roc.GetCache()
+
+	// 缓存中没有对象的位置时，向其他模块查询
+	cache.Del(objType, "1", b.moduleid)
+	if n, err := callTestInt(a, roc.O(objType, "1").F("Add"), "1"); err != nil ||
+		n != 1 {
+		t.Fatalf("Add = %d, %v, want 1", n, err)
+	}
+	if got := cache.Get(objType, "1"); got != b.moduleid {
+		t.Fatalf("cached location = %q, want %q", got, b.moduleid)
+	}
+
+	// 所有模块都不知道的对象，结果被缓存
+	_, err := a.ROCCallBlock(roc.O(objType, "2").F("Add"), []byte("1"))
+	if !errors.Is(err, roc.ErrUnknowObj) {
+		t.Fatalf("call unknown obj err = %v, want %v", err, roc.ErrUnknowObj)
+	}
+	if !cache.IsMiss(objType, "2") {
+		t.Fatalf("unknown obj not recorded as miss")
+	}
+	// 对象注册后，缓存的不存在结果被清除
+	c.NewROC(objType).GetOrRegObj("2", newTestObj(objType, "2", 0))
+	if cache.IsMiss(objType, "2") {
+		t.Fatalf("registered obj still recorded as miss")
+	}
+	if n, err := callTestInt(a, roc.O(objType, "2").F("Add"), "2"); err != nil ||
+		n != 2 {
+		t.Fatalf("Add after register = %d, %v, want 2", n, err)
+	}
+}
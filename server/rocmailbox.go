@@ -0,0 +1,334 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/liasece/micserver/conf"
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/util/sysutil"
+)
+
+// ROC调用处理协程的默认数量
+const (
+	defaultROCWorkerNum = 16
+	// 一个邮箱被执行一次最多连续处理的请求数，超过后让出工作协程，避免饿死其他对象
+	rocMailboxBatchNum = 64
+)
+
+// ROC对象的邮箱，同一个ROC对象的请求在邮箱中排队，依次执行
+type rocMailbox struct {
+	key     string
+	objType roc.ROCObjType
+	queue   []*requestAgent
+}
+
+// ROC请求调度器，每个ROC对象的请求在其邮箱中严格按顺序执行，不同ROC对象的请求由
+// 有限数量的工作协程并行执行，并且可以限制每个ROC类型同时执行的对象数量。
+// 工作协程中的调用处理以调用处理的 context 阻塞等待本进程中的ROC调用时，该工作
+// 协程不计入数量限制，调度器将启动额外的工作协程，避免所有工作协程都在等待而无法
+// 处理被等待的调用
+type rocDispatcher struct {
+	server *ROCServer
+
+	// 有待处理请求的邮箱，键为 roc.O(objType, objID).String()
+	mailboxes map[string]*rocMailbox
+	// 等待工作协程执行的邮箱
+	ready []*rocMailbox
+	// 由于所属类型的并发数限制而等待的邮箱
+	waiting map[roc.ROCObjType][]*rocMailbox
+	// 各类型就绪或正在执行的邮箱数量
+	active map[roc.ROCObjType]int
+	// 各类型同时执行的邮箱数量限制，不大于0时不限制
+	concurrency map[roc.ROCObjType]int
+	// 所有邮箱中等待处理的请求数量
+	pending int
+	// 工作协程的数量限制，当前工作协程的数量，其中空闲的数量，
+	// 以及阻塞等待本进程中ROC调用的数量
+	workerNum int
+	workers   int
+	idle      int
+	blocked   int
+
+	mutex     sync.Mutex
+	cond      *sync.Cond
+	startOnce sync.Once
+	stopped   bool
+}
+
+type rocWorkerCtxKey struct{}
+
+// 返回标记了在调度器的工作协程中处理调用的 context
+func withROCWorker(ctx context.Context,
+	dispatcher *rocDispatcher) context.Context {
+	return context.WithValue(ctx, rocWorkerCtxKey{}, dispatcher)
+}
+
+// 获取 context 所标记的处理调用的工作协程所属的调度器，不是在工作协程中处理的
+// 调用时返回 nil
+func rocWorkerFromContext(ctx context.Context) *rocDispatcher {
+	if dispatcher, ok := ctx.Value(rocWorkerCtxKey{}).(*rocDispatcher); ok {
+		return dispatcher
+	}
+	return nil
+}
+
+// 初始化调度器
+func (this *rocDispatcher) Init(server *ROCServer) {
+	this.server = server
+	this.mailboxes = make(map[string]*rocMailbox)
+	this.waiting = make(map[roc.ROCObjType][]*rocMailbox)
+	this.active = make(map[roc.ROCObjType]int)
+	this.concurrency = make(map[roc.ROCObjType]int)
+	this.cond = sync.NewCond(&this.mutex)
+}
+
+// 启动工作协程，模块配置在ROC服务初始化之后才被设置，所以在第一个请求到达时启动
+func (this *rocDispatcher) start() {
+	workerNum := 0
+	if this.server.server.moduleConfig != nil {
+		workerNum = int(this.server.server.moduleConfig.GetInt64(
+			conf.ROCWorkerNum))
+	}
+	if workerNum <= 0 {
+		workerNum = defaultROCWorkerNum
+	}
+	this.server.Syslog("[rocDispatcher.start] ROC调用处理协程数量 "+
+		"WorkerNum[%d]", workerNum)
+	this.server.registerDispatcherMetrics()
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.workerNum = workerNum
+	for this.workers < workerNum {
+		this.spawnWorkerNoLock()
+	}
+}
+
+// 启动一个工作协程，调用方需要持有锁
+func (this *rocDispatcher) spawnWorkerNoLock() {
+	this.workers++
+	go this.workerProcess()
+}
+
+// 工作协程开始阻塞等待本进程中的ROC调用，没有空闲的工作协程时启动额外的工作协程
+func (this *rocDispatcher) blockBegin() {
+	this.startOnce.Do(this.start)
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.blocked++
+	if len(this.ready) > 0 && this.idle == 0 && !this.stopped {
+		this.spawnWorkerNoLock()
+	}
+}
+
+// 结束阻塞等待，多出的工作协程将在空闲后退出
+func (this *rocDispatcher) blockEnd() {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.blocked--
+}
+
+// 停止所有工作协程，正在执行的请求执行完毕后工作协程退出。
+// 邮箱中未处理的请求不再执行，其中需要返回的调用向调用方返回错误
+func (this *rocDispatcher) stop() {
+	this.mutex.Lock()
+	this.stopped = true
+	this.cond.Broadcast()
+	agents := make([]*requestAgent, 0, this.pending)
+	for _, mb := range this.mailboxes {
+		agents = append(agents, mb.queue...)
+		mb.queue = nil
+	}
+	this.mailboxes = make(map[string]*rocMailbox)
+	this.ready = nil
+	this.waiting = make(map[roc.ROCObjType][]*rocMailbox)
+	this.pending = 0
+	this.mutex.Unlock()
+
+	for _, agent := range agents {
+		this.discard(agent)
+	}
+}
+
+// 丢弃调度器停止后无法执行的请求。ROC服务内部的操作仍然直接执行，
+// 以免等待该操作的流程无法结束；需要返回的调用向调用方返回 roc.ErrConnLost
+func (this *rocDispatcher) discard(agent *requestAgent) {
+	if agent.fn != nil {
+		this.handle(agent)
+		return
+	}
+	this.server.Warn("[rocDispatcher.discard] ROC Request[%s] from %s "+
+		"discarded, dispatcher stopped", agent.callpath, agent.fromModuleID)
+	if agent.needReturn {
+		this.server.sendROCResponse(agent, nil,
+			roc.NewError(roc.ErrCodeConnLost, "roc dispatcher stopped"))
+	}
+}
+
+// 设置一个ROC类型同时执行的对象数量，不大于0时不限制
+func (this *rocDispatcher) setConcurrency(objType roc.ROCObjType, n int) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.concurrency[objType] = n
+	// 限制放宽后，唤醒等待中的邮箱
+	for len(this.waiting[objType]) > 0 && this.canActive(objType) {
+		mb := this.waiting[objType][0]
+		this.waiting[objType] = this.waiting[objType][1:]
+		this.active[objType]++
+		this.pushReady(mb)
+	}
+}
+
+// 将请求投递到目标ROC对象的邮箱
+func (this *rocDispatcher) push(agent *requestAgent) {
+	this.startOnce.Do(this.start)
+	key := migrateKey(agent.objType, agent.objID)
+
+	this.mutex.Lock()
+	if this.stopped {
+		this.mutex.Unlock()
+		this.discard(agent)
+		return
+	}
+	defer this.mutex.Unlock()
+	this.pending++
+	if mb, ok := this.mailboxes[key]; ok {
+		// 邮箱已在等待或执行中
+		mb.queue = append(mb.queue, agent)
+		return
+	}
+	mb := &rocMailbox{
+		key:     key,
+		objType: agent.objType,
+		queue:   []*requestAgent{agent},
+	}
+	this.mailboxes[key] = mb
+	if this.canActive(mb.objType) {
+		this.active[mb.objType]++
+		this.pushReady(mb)
+	} else {
+		this.waiting[mb.objType] = append(this.waiting[mb.objType], mb)
+	}
+}
+
+// 获取所有邮箱中等待处理的请求数量
+func (this *rocDispatcher) queueDepth() int {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.pending
+}
+
+// 获取各类型的对象邮箱中等待处理的请求数量
+func (this *rocDispatcher) queueDepthByType() map[roc.ROCObjType]int {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	res := make(map[roc.ROCObjType]int)
+	for _, mb := range this.mailboxes {
+		if len(mb.queue) > 0 {
+			res[mb.objType] += len(mb.queue)
+		}
+	}
+	return res
+}
+
+// 判断目标类型是否还能执行更多的邮箱，调用方需要持有锁
+func (this *rocDispatcher) canActive(objType roc.ROCObjType) bool {
+	limit := this.concurrency[objType]
+	return limit <= 0 || this.active[objType] < limit
+}
+
+// 将邮箱交给工作协程执行，有工作协程阻塞等待ROC调用并且没有空闲的工作协程时，
+// 启动额外的工作协程，调用方需要持有锁
+func (this *rocDispatcher) pushReady(mb *rocMailbox) {
+	this.ready = append(this.ready, mb)
+	if this.idle == 0 && this.workers < this.workerNum+this.blocked &&
+		!this.stopped {
+		this.spawnWorkerNoLock()
+		return
+	}
+	this.cond.Signal()
+}
+
+// 工作协程
+func (this *rocDispatcher) workerProcess() {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	for {
+		for len(this.ready) == 0 && !this.stopped {
+			// 阻塞的调用结束后，多出的工作协程退出
+			if this.workers > this.workerNum+this.blocked {
+				this.workers--
+				return
+			}
+			this.idle++
+			this.cond.Wait()
+			this.idle--
+		}
+		if this.stopped {
+			this.workers--
+			return
+		}
+		mb := this.ready[0]
+		this.ready[0] = nil
+		this.ready = this.ready[1:]
+		this.mutex.Unlock()
+
+		this.runMailbox(mb)
+		this.mutex.Lock()
+	}
+}
+
+// 执行一个邮箱中的请求
+func (this *rocDispatcher) runMailbox(mb *rocMailbox) {
+	for i := 0; i < rocMailboxBatchNum; i++ {
+		this.mutex.Lock()
+		if len(mb.queue) == 0 {
+			this.mutex.Unlock()
+			break
+		}
+		agent := mb.queue[0]
+		mb.queue[0] = nil
+		mb.queue = mb.queue[1:]
+		this.pending--
+		this.mutex.Unlock()
+
+		this.handle(agent)
+	}
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	waiting := this.waiting[mb.objType]
+	if len(mb.queue) == 0 {
+		// 邮箱已空，释放所属类型的并发数
+		delete(this.mailboxes, mb.key)
+		if len(waiting) > 0 {
+			this.waiting[mb.objType] = waiting[1:]
+			this.pushReady(waiting[0])
+		} else {
+			this.active[mb.objType]--
+		}
+	} else if len(waiting) > 0 {
+		// 让出并发数给同类型等待中的邮箱
+		this.waiting[mb.objType] = append(waiting[1:], mb)
+		this.pushReady(waiting[0])
+	} else {
+		this.pushReady(mb)
+	}
+}
+
+// 处理一个请求，用户代码 panic 时向调用方返回错误
+func (this *rocDispatcher) handle(agent *requestAgent) {
+	defer func() {
+		if err, stackInfo := sysutil.GetPanicInfo(recover()); err != nil {
+			this.server.Error("[rocDispatcher.handle] "+
+				"Panic: Path[%s] Err[%v] \n Stack[%s]",
+				agent.callpath, err, stackInfo)
+			if agent.fn == nil && agent.needReturn {
+				this.server.sendROCResponse(agent, nil,
+					fmt.Errorf("roc call panic: %v", err))
+			}
+		}
+	}()
+	this.server.handleROCRequest(agent)
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/liasece/micserver/conf"
+	"github.com/liasece/micserver/roc"
+)
+
+// 在调用处理中以调用处理的 context 调用本模块中其他对象的测试对象
+type testNestObj struct {
+	*testObj
+	server *Server
+}
+
+func (this *testNestObj) OnROCCall(path *roc.ROCPath,
+	arg []byte) ([]byte, error) {
+	return this.server.ROCCallContext(path.Context(),
+		roc.O(this.objType, string(arg)).F("Add"), []byte("1"))
+}
+
+func TestROCMailboxOrder(t *testing.T) {
+	a := newTestServer(t, "testmailbox", nil)
+	b := newTestServer(t, "testmailbox", nil)
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestMailboxOrderObj")
+	obj := newTestObj(objType, "1", 0)
+	b.NewROC(objType).GetOrRegObj("1", obj)
+
+	// 同一模块对同一对象发起的调用按发起的顺序执行
+	want := make([]string, 0)
+	for i := 1; i <= 100; i++ {
+		if err := a.ROCCallNR(roc.O(objType, "1").F("Add"),
+			[]byte(fmt.Sprint(i))); err != nil {
+			t.Fatalf("ROCCallNR err: %v", err)
+		}
+		want = append(want, fmt.Sprint("Add ", i))
+	}
+	if n, err := callTestInt(a, roc.O(objType, "1").F("Get"), ""); err != nil ||
+		n != 5050 {
+		t.Fatalf("Get = %d, %v, want 5050", n, err)
+	}
+	if calls := obj.getCalls(); !reflect.DeepEqual(calls[:len(want)], want) {
+		t.Fatalf("calls = %q, want %q", calls, want)
+	}
+}
+
+func TestROCMailboxConcurrency(t *testing.T) {
+	a := newTestServer(t, "testmailbox", nil)
+	b := newTestServer(t, "testmailbox", nil)
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestMailboxConcurrencyObj")
+	obj1 := newTestObj(objType, "1", 0)
+	obj2 := newTestObj(objType, "2", 0)
+	b.NewROC(objType).GetOrRegObj("1", obj1)
+	b.GetROC(objType).GetOrRegObj("2", obj2)
+
+	// 一个对象的调用阻塞时，不影响其他对象
+	waitRes := goCallTest(a, roc.O(objType, "1").F("Wait"), "")
+	obj1.waitCall(t, "Wait ")
+	if _, err := callTestInt(a, roc.O(objType, "2").F("Add"), "1"); err != nil {
+		t.Fatalf("Add while other obj blocked err: %v", err)
+	}
+	// 同一对象之后的调用等待阻塞的调用结束
+	addRes := goCallTest(a, roc.O(objType, "1").F("Add"), "1")
+	select {
+	case err := <-addRes:
+		t.Fatalf("Add returned while obj blocked, err: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(obj1.wait)
+	if err := <-waitRes; err != nil {
+		t.Fatalf("Wait err: %v", err)
+	}
+	if err := <-addRes; err != nil {
+		t.Fatalf("Add err: %v", err)
+	}
+
+	// 限制类型的并发数为1后，该类型的对象依次执行
+	b.SetROCTypeConcurrency(objType, 1)
+	obj3 := newTestObj(objType, "3", 0)
+	b.GetROC(objType).GetOrRegObj("3", obj3)
+	waitRes = goCallTest(a, roc.O(objType, "3").F("Wait"), "")
+	obj3.waitCall(t, "Wait ")
+	addRes = goCallTest(a, roc.O(objType, "2").F("Add"), "1")
+	select {
+	case err := <-addRes:
+		t.Fatalf("Add returned while type limited, err: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(obj3.wait)
+	if err := <-waitRes; err != nil {
+		t.Fatalf("Wait err: %v", err)
+	}
+	if err := <-addRes; err != nil {
+		t.Fatalf("Add err: %v", err)
+	}
+}
+
+func TestROCMailboxNestedCall(t *testing.T) {
+	a := newTestServer(t, "testmailbox", nil)
+	b := newTestServer(t, "testmailbox", conf.BaseConfig{
+		string(conf.ROCWorkerNum): 1,
+	})
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestMailboxNestedObj")
+	b.NewROC(objType).GetOrRegObj("1",
+		&testNestObj{newTestObj(objType, "1", 0), b})
+	b.GetROC(objType).GetOrRegObj("2", newTestObj(objType, "2", 0))
+
+	// 唯一的工作协程等待本模块中的调用时，由额外的工作协程处理被等待的调用
+	if n, err := callTestInt(a, roc.O(objType, "1").F("Nest"), "2"); err != nil ||
+		n != 1 {
+		t.Fatalf("Nest = %d, %v, want 1", n, err)
+	}
+	b.rocDispatcher.mutex.Lock()
+	defer b.rocDispatcher.mutex.Unlock()
+	if b.rocDispatcher.blocked != 0 {
+		t.Fatalf("blocked workers = %d, want 0", b.rocDispatcher.blocked)
+	}
+}
+
+func TestROCMailboxPanic(t *testing.T) {
+	a := newTestServer(t, "testmailbox", nil)
+	b := newTestServer(t, "testmailbox", nil)
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestMailboxPanicObj")
+	b.NewROC(objType).GetOrRegObj("1", newTestObj(objType, "1", 0))
+
+	// 调用处理 panic 时调用方收到错误，之后的调用正常执行
+	if _, err := a.ROCCallBlock(roc.O(objType, "1").F("Panic"),
+		nil); err == nil {
+		t.Fatalf("Panic err = nil")
+	}
+	if n, err := callTestInt(a, roc.O(objType, "1").F("Add"), "1"); err != nil ||
+		n != 1 {
+		t.Fatalf("Add after panic = %d, %v, want 1", n, err)
+	}
+}
+
+func TestROCMailboxStop(t *testing.T) {
+	a := newTestServer(t, "testmailbox", nil)
+	b := newTestServer(t, "testmailbox", nil)
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestMailboxStopObj")
+	obj := newTestObj(objType, "1", 0)
+	b.NewROC(objType).GetOrRegObj("1", obj)
+
+	// 停止时正在执行的调用执行完毕，邮箱中等待的调用返回错误
+	waitRes := goCallTest(a, roc.O(objType, "1").F("Wait"), "")
+	obj.waitCall(t, "Wait ")
+	addRes := goCallTest(a, roc.O(objType, "1").F("Add"), "1")
+	waitTest(t, "Add queued", func() bool {
+		return b.rocDispatcher.queueDepth() == 1
+	})
+	b.Stop()
+	if err := <-addRes; !errors.Is(err, roc.ErrConnLost) {
+		t.Fatalf("queued Add err = %v, want %v", err, roc.ErrConnLost)
+	}
+	close(obj.wait)
+	if err := <-waitRes; err != nil {
+		t.Fatalf("Wait err: %v", err)
+	}
+	// 停止后到达的调用同样返回错误
+	if _, err := a.ROCCallBlock(roc.O(objType, "1").F("Add"),
+		[]byte("1")); !errors.Is(err, roc.ErrConnLost) {
+		t.Fatalf("Add after stop err = %v, want %v", err, roc.ErrConnLost)
+	}
+	if calls := obj.getCalls(); len(calls) != 1 {
+		t.Fatalf("calls = %q, want only Wait", calls)
+	}
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/liasece/micserver/metrics"
+	"github.com/liasece/micserver/roc"
+)
+
+// ROC指标的调用方及被调用方
+const (
+	rocMetricsClient = "client"
+	rocMetricsServer = "server"
+	// 被调用方指标中未知的ROC对象类型或函数名的标签值
+	rocMetricsUnknown = "unknown"
+)
+
+// 一个ROC对象类型及函数名在调用方或被调用方的指标
+type rocPathMetrics struct {
+	side     string
+	labels   metrics.Labels
+	calls    *metrics.Counter
+	latency  *metrics.Histogram
+	inFlight *metrics.Gauge
+	// 只有被调用方记录请求的排队时间
+	queue *metrics.Histogram
+}
+
+// 获取目标ROC调用路径的指标，标签为模块ID、ROC对象类型及调用的函数名。
+// 被调用方的调用路径来自其他模块，为了避免标签无限增长，本模块没有注册的类型，
+// 以及没有执行成功过的函数名，标签值为 unknown ，见 learnROCFunc
+func (this *ROCServer) getROCPathMetrics(side string,
+	path *roc.ROCPath) *rocPathMetrics {
+	objType := string(path.GetObjType())
+	funcName := path.Get(0)
+	if side == rocMetricsServer {
+		if this.GetROC(path.GetObjType()) == nil {
+			objType = rocMetricsUnknown
+		}
+		if _, ok := this.knownFuncs.Load(objType + "." + funcName); !ok {
+			funcName = rocMetricsUnknown
+		}
+	}
+	key := side + ":" + objType + "." + funcName
+	if v, ok := this.pathMetrics.Load(key); ok {
+		return v.(*rocPathMetrics)
+	}
+	labels := metrics.Labels{
+		"module": this.server.moduleid,
+		"type":   objType,
+		"func":   funcName,
+	}
+	reg := metrics.GetRegistry()
+	m := &rocPathMetrics{
+		side:   side,
+		labels: labels,
+		calls: reg.Counter("roc_"+side+"_calls_total",
+			"Number of finished ROC calls.", labels),
+		latency: reg.Histogram("roc_"+side+"_latency_seconds",
+			"Latency of ROC calls.", labels, nil),
+		inFlight: reg.Gauge("roc_"+side+"_in_flight",
+			"Number of ROC calls in progress.", labels),
+	}
+	if side == rocMetricsServer {
+		m.queue = reg.Histogram("roc_server_queue_seconds",
+			"Time ROC requests wait in the object mailbox.", labels, nil)
+	}
+	v, _ := this.pathMetrics.LoadOrStore(key, m)
+	return v.(*rocPathMetrics)
+}
+
+// 记录被调用方执行过的函数名，之后该函数的调用以函数名作为指标的标签。
+// 调用成功，或者以目标对象返回的非未知的错误码失败时，认为该函数存在
+func (this *ROCServer) learnROCFunc(objType roc.ROCObjType, funcName string,
+	err error) {
+	if err != nil {
+		switch code, _, _ := roc.ErrorToCode(err); code {
+		case roc.ErrCodeUnknown, roc.ErrCodeUnknownFunc, roc.ErrCodeUnknowObj,
+			roc.ErrCodeBadPath, roc.ErrCodeUnregisterROC:
+			return
+		}
+	}
+	if this.GetROC(objType) == nil {
+		return
+	}
+	this.knownFuncs.Store(string(objType)+"."+funcName, struct{}{})
+}
+
+// 开始一次调用
+func (this *rocPathMetrics) begin() time.Time {
+	this.inFlight.Add(1)
+	return time.Now()
+}
+
+// 结束一次调用，调用失败时按错误码记录错误数
+func (this *rocPathMetrics) end(start time.Time, err error) {
+	this.inFlight.Add(-1)
+	this.calls.Inc()
+	this.latency.ObserveDuration(time.Since(start))
+	if err == nil {
+		return
+	}
+	code, _, _ := roc.ErrorToCode(err)
+	labels := make(metrics.Labels, len(this.labels)+1)
+	for k, v := range this.labels {
+		labels[k] = v
+	}
+	labels["code"] = fmt.Sprint(int32(code))
+	metrics.GetRegistry().Counter("roc_"+this.side+"_errors_total",
+		"Number of failed ROC calls by error code.", labels).Inc()
+}
+
+// 注册本模块ROC调度器的指标
+func (this *ROCServer) registerDispatcherMetrics() {
+	metrics.GetRegistry().GaugeFunc("roc_server_queue_depth",
+		"Number of ROC requests waiting in object mailboxes.",
+		metrics.Labels{
+			"module": this.server.moduleid,
+		}, func() float64 {
+			return float64(this.rocDispatcher.queueDepth())
+		})
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/liasece/micserver/roc"
+)
+
+// 判断是否存在目标调用路径的指标
+func hasTestPathMetrics(s *Server, key string) bool {
+	_, ok := s.pathMetrics.Load(key)
+	return ok
+}
+
+func TestROCPathMetricsUnknown(t *testing.T) {
+	a := newTestServer(t, "testmetrics", nil)
+	b := newTestServer(t, "testmetrics", nil)
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestMetricsObj")
+	b.NewROC(objType).GetOrRegObj("1", newTestObj(objType, "1", 0))
+
+	// 被调用方不以不存在的函数名作为标签
+	if _, err := a.ROCCallBlock(roc.O(objType, "1").F("Bogus"),
+		nil); err == nil {
+		t.Fatalf("call Bogus err = nil")
+	}
+	if _, err := a.ROCCallBlock(roc.O(objType, "1").F("Bogus"),
+		nil); err == nil {
+		t.Fatalf("call Bogus err = nil")
+	}
+	if hasTestPathMetrics(b, "server:"+string(objType)+".Bogus") {
+		t.Fatalf("server metrics labeled by unknown func")
+	}
+	// 执行成功过的函数之后以函数名作为标签
+	for i := 0; i < 2; i++ {
+		if _, err := callTestInt(a, roc.O(objType, "1").F("Add"),
+			"1"); err != nil {
+			t.Fatalf("Add err: %v", err)
+		}
+	}
+	if !hasTestPathMetrics(b, "server:"+string(objType)+".Add") {
+		t.Fatalf("server metrics not labeled by known func")
+	}
+	if !hasTestPathMetrics(b,
+		"server:"+string(objType)+"."+rocMetricsUnknown) {
+		t.Fatalf("server metrics of unknown func missing")
+	}
+}
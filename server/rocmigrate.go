@@ -0,0 +1,376 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/servercomm"
+)
+
+// 已迁出的ROC对象的记录保留的时间，迁移完成后所有模块的ROC缓存将很快指向目标模块，
+// 该时间内仍然发送到本模块的调用将被转发到目标模块
+const rocMigratedTTL = time.Minute
+
+// 一个已迁出的ROC对象
+type rocMigrated struct {
+	moduleid   string
+	expireTime time.Time
+}
+
+// 迁移状态中记录ROC对象使用的键
+func migrateKey(objType roc.ROCObjType, objID string) string {
+	return roc.O(objType, objID).String()
+}
+
+// 将本模块中的一个ROC对象迁移到目标模块中，目标对象需要实现 roc.Migratable
+// 接口，目标模块中该类型的ROC需要通过 roc.ROC.SetObjLoader 设置对象加载器。
+// 迁移期间对该对象的调用将被缓存，迁移成功后，所有模块的ROC缓存将直接指向目标模块，
+// 缓存的调用以及之后仍然发送到本模块的调用将被转发到目标模块；迁移失败时，
+// 对象保留在本模块中，缓存的调用将在本模块中继续执行。
+// ctx 超时或被取消时迁移失败，如果此时目标模块已经完成了加载，对象可能同时存在于
+// 两个模块中，但本模块将取得更新的围栏令牌，所有模块的ROC缓存都将指向本模块，
+// 目标模块中的对象将拒绝之后的调用。
+// 迁移需要等待目标对象邮箱中的调用处理完毕，因此不可以在该对象自身的调用处理中
+// 同步迁移该对象，否则迁移将在 ctx 结束时失败，需要在新的协程中发起迁移。
+func (this *ROCServer) MigrateROCObj(ctx context.Context,
+	objType roc.ROCObjType, objID string, toModuleID string) error {
+	if toModuleID == this.server.moduleid {
+		return fmt.Errorf("Can't migrate roc obj to its own module %s",
+			toModuleID)
+	}
+	r := this.GetROC(objType)
+	if r == nil {
+		return roc.ErrUnregisterROC
+	}
+	obj, ok := r.GetObj(objID)
+	if !ok || obj == nil {
+		return roc.ErrUnknowObj
+	}
+	migratable, ok := obj.(roc.Migratable)
+	if !ok {
+		return roc.ErrNotMigratable
+	}
+
+	// 标记为迁移中，之后处理到的该对象的调用请求将被缓存
+	key := migrateKey(objType, objID)
+	this.migrateMutex.Lock()
+	if this.migratingObj == nil {
+		this.migratingObj = make(map[string][]*requestAgent)
+	}
+	if _, ok := this.migratingObj[key]; ok {
+		this.migrateMutex.Unlock()
+		return roc.ErrObjMigrating
+	}
+	this.migratingObj[key] = make([]*requestAgent, 0)
+	this.migrateMutex.Unlock()
+
+	// 等待已经开始处理的调用请求执行完毕
+	err := this.waitObjMailbox(ctx, objType, objID)
+	var state []byte
+	if err == nil {
+		state, err = migratable.MarshalROCState()
+	}
+	if err == nil {
+		err = this.checkPayloadSize(int64(len(state)))
+	}
+	if err == nil {
+		err = this.sendROCMigrate(ctx, &servercomm.SROCMigrate{
+			FromModuleID: this.server.moduleid,
+			ToModuleID:   toModuleID,
+			ObjType:      string(objType),
+			ObjID:        objID,
+			State:        state,
+			Token:        this.getLocalObjToken(objType, objID) + 1,
+		})
+	}
+	if err != nil {
+		this.Warn("MigrateROCObj %s to %s err:%s",
+			key, toModuleID, err.Error())
+	} else {
+		this.Syslog("MigrateROCObj %s to %s", key, toModuleID)
+	}
+
+	// 在目标对象的邮箱中结束迁移，保证缓存的调用请求先于之后的请求被处理。
+	// 迁移的结果此时已经确定，ctx 结束时不再等待，迁移将在邮箱中继续结束
+	done := make(chan struct{})
+	this.pushROCRequest(&requestAgent{
+		objType: objType,
+		objID:   objID,
+		fn: func() {
+			this.finishMigrate(objType, objID, toModuleID, err)
+			close(done)
+		},
+	})
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	return err
+}
+
+// 等待目标对象邮箱中当前的ROC请求全部处理完毕
+func (this *ROCServer) waitObjMailbox(ctx context.Context,
+	objType roc.ROCObjType, objID string) error {
+	done := make(chan struct{})
+	this.pushROCRequest(&requestAgent{
+		objType: objType,
+		objID:   objID,
+		fn: func() {
+			close(done)
+		},
+	})
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return roc.ContextErr(ctx)
+	}
+}
+
+// 向目标模块发送迁移请求，并等待迁移结果
+func (this *ROCServer) sendROCMigrate(ctx context.Context,
+	sendmsg *servercomm.SROCMigrate) error {
+	sendmsg.Seq = this.newSeq()
+	ch := this.addBlockChan(sendmsg.Seq)
+	if local := this.getLocalROCServer(sendmsg.ToModuleID); local != nil {
+		local.onROCMigrate(sendmsg, this)
+	} else {
+		server := this.server.subnetManager.GetServer(sendmsg.ToModuleID)
+		if server == nil {
+			this.rocBlockChanMap.Delete(sendmsg.Seq)
+			return fmt.Errorf("Can't find module %s", sendmsg.ToModuleID)
+		}
+		this.sendROCMsg(server, sendmsg)
+	}
+
+	select {
+	case agent := <-ch:
+		return agent.err
+	case <-ctx.Done():
+		this.rocBlockChanMap.Delete(sendmsg.Seq)
+		return roc.ContextErr(ctx)
+	}
+}
+
+// 结束一个ROC对象的迁移，只能在该对象的邮箱中调用
+func (this *ROCServer) finishMigrate(objType roc.ROCObjType, objID string,
+	toModuleID string, err error) {
+	key := migrateKey(objType, objID)
+
+	this.migrateMutex.Lock()
+	pending := this.migratingObj[key]
+	delete(this.migratingObj, key)
+	if err == nil {
+		if this.migratedObj == nil {
+			this.migratedObj = make(map[string]*rocMigrated)
+		}
+		now := time.Now()
+		this.dropExpiredMigratedNoLock(now)
+		this.migratedObj[key] = &rocMigrated{
+			moduleid:   toModuleID,
+			expireTime: now.Add(rocMigratedTTL),
+		}
+	}
+	this.migrateMutex.Unlock()
+
+	if err == nil {
+		// 删除本地对象，OnROCObjDel 将所有模块的缓存指向目标模块
+		this.GetROC(objType).DelObjByID(objID)
+		for _, agent := range pending {
+			this.forwardROCRequest(agent, toModuleID)
+		}
+		return
+	}
+
+	// 目标模块可能已经加载了该对象并通知了绑定信息，重新取得比目标模块使用的
+	// 令牌更大的令牌，将缓存重新指向本模块，目标模块中的对象将不再处理调用
+	if errors.Is(err, roc.ErrCallTimeout) ||
+		errors.Is(err, roc.ErrCallCanceled) {
+		this.localObjMutex.Lock()
+		token := this.acquireObjTokenNoLock(objType, objID,
+			this.localObj[string(objType)][objID]+2)
+		this.localObjMutex.Unlock()
+		this.sendROCBindMsg(&servercomm.SROCBind{
+			HostModuleID: this.server.moduleid,
+			IsDelete:     false,
+			ObjType:      string(objType),
+			ObjIDs:       []string{objID},
+			Tokens:       []uint64{token},
+		})
+	}
+	for _, agent := range pending {
+		this.handleROCRequest(agent)
+	}
+}
+
+// 如果目标对象正在迁出，缓存该请求；如果目标对象已迁出，将请求转发到目标模块。
+// 返回该请求是否已被处理
+func (this *ROCServer) holdMigratingRequest(agent *requestAgent) bool {
+	this.migrateMutex.Lock()
+	if len(this.migratingObj) == 0 && len(this.migratedObj) == 0 {
+		this.migrateMutex.Unlock()
+		return false
+	}
+	key := migrateKey(agent.objType, agent.objID)
+	if pending, ok := this.migratingObj[key]; ok {
+		this.migratingObj[key] = append(pending, agent)
+		this.migrateMutex.Unlock()
+		return true
+	}
+	toModuleID := this.getMigratedNoLock(key)
+	this.migrateMutex.Unlock()
+	if toModuleID == "" {
+		return false
+	}
+	this.forwardROCRequest(agent, toModuleID)
+	return true
+}
+
+// 获取已迁出的ROC对象所在的模块，没有记录或记录已过期时返回空字符串，
+// 调用方需要持有 migrateMutex
+func (this *ROCServer) getMigratedNoLock(key string) string {
+	migrated, ok := this.migratedObj[key]
+	if !ok {
+		return ""
+	}
+	if time.Now().After(migrated.expireTime) {
+		delete(this.migratedObj, key)
+		return ""
+	}
+	return migrated.moduleid
+}
+
+// 删除所有已过期的已迁出对象的记录，调用方需要持有 migrateMutex
+func (this *ROCServer) dropExpiredMigratedNoLock(now time.Time) {
+	for key, migrated := range this.migratedObj {
+		if now.After(migrated.expireTime) {
+			delete(this.migratedObj, key)
+		}
+	}
+}
+
+// 将ROC请求转发到目标模块，目标模块将直接向调用方返回结果
+func (this *ROCServer) forwardROCRequest(agent *requestAgent,
+	toModuleID string) {
+	this.Syslog("ROC Request[%s] forward to %s", agent.callpath, toModuleID)
+	if local := this.getLocalROCServer(toModuleID); local != nil {
+		local.pushROCRequest(agent)
+		return
+	}
+	server := this.server.subnetManager.GetServer(toModuleID)
+	if server == nil {
+		this.Warn("ROC forward target module does not exist "+
+			"ModuleID[%s] Path[%s]", toModuleID, agent.callpath)
+		if agent.needReturn {
+			this.sendROCResponse(agent, nil, roc.ErrUnknowObj)
+		}
+		return
+	}
+	this.sendROCMsg(server, &servercomm.SROCRequest{
+		FromModuleID: agent.fromModuleID,
+		ToModuleID:   toModuleID,
+		Seq:          agent.seq,
+		CallStr:      agent.callpath,
+		CallArg:      agent.callarg,
+		NeedReturn:   agent.needReturn,
+		TraceID:      agent.spanCtx.TraceID,
+		SpanID:       agent.spanCtx.SpanID,
+		Token:        agent.token,
+		StreamWindow: agent.streamWindow,
+		IdemKey:      agent.idemKey,
+		Reminder:     agent.reminder,
+	})
+}
+
+// 已迁出的对象被删除时，将缓存指向目标模块并通知其他模块，返回该对象是否已迁出，
+// token 为目标模块注册该对象使用的令牌
+func (this *ROCServer) onMigratedObjDel(obj roc.IObj, token uint64) bool {
+	this.migrateMutex.Lock()
+	toModuleID := this.getMigratedNoLock(migrateKey(obj.GetROCObjType(),
+		obj.GetROCObjID()))
+	this.migrateMutex.Unlock()
+	if toModuleID == "" {
+		return false
+	}
+	roc.GetCache().SetToken(obj.GetROCObjType(), obj.GetROCObjID(),
+		toModuleID, token)
+	this.Syslog("OnROCObjDel roc obj migrated type[%s] "+
+		"id[%s] host[%s]",
+		obj.GetROCObjType(), obj.GetROCObjID(), toModuleID)
+	this.sendROCBindMsg(&servercomm.SROCBind{
+		HostModuleID: toModuleID,
+		IsDelete:     false,
+		ObjType:      string(obj.GetROCObjType()),
+		ObjIDs:       []string{obj.GetROCObjID()},
+		Tokens:       []uint64{token},
+	})
+	return true
+}
+
+// 当收到ROC对象迁移请求时
+func (this *ROCServer) onMsgROCMigrate(msg *servercomm.SROCMigrate) {
+	this.onROCMigrate(msg, nil)
+}
+
+// 在目标对象的邮箱中加载迁移到本模块的ROC对象，并返回迁移结果
+func (this *ROCServer) onROCMigrate(msg *servercomm.SROCMigrate,
+	caller *ROCServer) {
+	this.pushROCRequest(&requestAgent{
+		objType: roc.ROCObjType(msg.ObjType),
+		objID:   msg.ObjID,
+		fn: func() {
+			err := this.loadROCObj(roc.ROCObjType(msg.ObjType),
+				msg.ObjID, msg.State, msg.Token)
+			if err != nil {
+				this.Error("Load migrated roc obj %s[%s] from %s err:%s",
+					msg.ObjType, msg.ObjID, msg.FromModuleID, err.Error())
+			} else {
+				this.Syslog("Load migrated roc obj %s[%s] from %s",
+					msg.ObjType, msg.ObjID, msg.FromModuleID)
+			}
+			this.sendROCResponse(&requestAgent{
+				fromModuleID: msg.FromModuleID,
+				seq:          msg.Seq,
+				needReturn:   true,
+				caller:       caller,
+			}, nil, err)
+		},
+	})
+}
+
+// 使用对象加载器重建并注册迁移或恢复到本模块的ROC对象，注册时使用的围栏令牌
+// 不小于 minToken
+func (this *ROCServer) loadROCObj(objType roc.ROCObjType, objID string,
+	state []byte, minToken uint64) error {
+	r := this.GetROC(objType)
+	if r == nil {
+		return roc.ErrUnregisterROC
+	}
+	obj, err := r.LoadObj(objID, state)
+	if err != nil {
+		return err
+	}
+	if obj == nil || obj.GetROCObjType() != objType ||
+		obj.GetROCObjID() != objID {
+		return fmt.Errorf("roc obj loader returned a mismatched obj for "+
+			"%s[%s]", objType, objID)
+	}
+	key := migrateKey(objType, objID)
+	this.localObjMutex.Lock()
+	if this.objTokenHint == nil {
+		this.objTokenHint = make(map[string]uint64)
+	}
+	this.objTokenHint[key] = minToken
+	this.localObjMutex.Unlock()
+	_, isLoad := r.GetOrRegObj(objID, obj)
+	this.localObjMutex.Lock()
+	delete(this.objTokenHint, key)
+	this.localObjMutex.Unlock()
+	if isLoad {
+		return roc.ErrObjExists
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/liasece/micserver/roc"
+)
+
+func TestMigrateROCObj(t *testing.T) {
+	a := newTestServer(t, "testmig", nil)
+	b := newTestServer(t, "testmig", nil)
+	c := newTestServer(t, "testmig", nil)
+	connectTestServers(t, a, b, c)
+
+	objType := newTestObjType("TestMigrateObj")
+	b.NewROC(objType).SetObjLoader(loadTestObj(objType))
+	c.NewROC(objType).SetObjLoader(loadTestObj(objType))
+	obj := newTestObj(objType, "1", 10)
+	b.GetROC(objType).GetOrRegObj("1", obj)
+
+	// 迁移等待正在处理的调用结束，迁移期间到达的调用在迁移完成后被转发到目标模块
+	waitRes := goCallTest(a, roc.O(objType, "1").F("Wait"), "")
+	obj.waitCall(t, "Wait ")
+	migrateRes := make(chan error, 1)
+	go func() {
+		migrateRes <- b.MigrateROCObj(context.Background(), objType, "1",
+			c.moduleid)
+	}()
+	waitTest(t, "migrating", func() bool {
+		b.migrateMutex.Lock()
+		defer b.migrateMutex.Unlock()
+		_, ok := b.migratingObj[migrateKey(objType, "1")]
+		return ok
+	})
+	addRes := make(chan int, 1)
+	go func() {
+		n, err := callTestInt(a, roc.O(objType, "1").F("Add"), "5")
+		if err != nil {
+			t.Errorf("Add during migration err: %v", err)
+		}
+		addRes <- n
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(obj.wait)
+	if err := <-waitRes; err != nil {
+		t.Fatalf("Wait err: %v", err)
+	}
+	if err := <-migrateRes; err != nil {
+		t.Fatalf("MigrateROCObj err: %v", err)
+	}
+	if n := <-addRes; n != 15 {
+		t.Fatalf("Add during migration = %d, want 15", n)
+	}
+	if calls := obj.getCalls(); len(calls) != 1 {
+		t.Fatalf("source obj calls = %q, want only Wait", calls)
+	}
+
+	if _, ok := b.GetROC(objType).GetObj("1"); ok {
+		t.Fatalf("migrated obj still in source module")
+	}
+	if _, ok := c.GetROC(objType).GetObj("1"); !ok {
+		t.Fatalf("migrated obj not in target module")
+	}
+	if got := a.GetROCCachedLocation(objType, "1"); got != c.moduleid {
+		t.Fatalf("cached location = %q, want %q", got, c.moduleid)
+	}
+	if n, err := callTestInt(a, roc.O(objType, "1").F("Get"), ""); err != nil ||
+		n != 15 {
+		t.Fatalf("Get after migration = %d, %v, want 15", n, err)
+	}
+}
+
+func TestMigrateROCObjFail(t *testing.T) {
+	a := newTestServer(t, "testmig", nil)
+	b := newTestServer(t, "testmig", nil)
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestMigrateFailObj")
+	obj := newTestObj(objType, "1", 10)
+	a.NewROC(objType).GetOrRegObj("1", obj)
+	a.GetROC(objType).GetOrRegObj("plain", struct{ roc.IObj }{
+		newTestObj(objType, "plain", 0)})
+	ctx := context.Background()
+
+	if err := a.MigrateROCObj(ctx, objType, "1", a.moduleid); err == nil {
+		t.Errorf("migrate to own module err = nil")
+	}
+	if err := a.MigrateROCObj(ctx, objType, "2",
+		b.moduleid); err != roc.ErrUnknowObj {
+		t.Errorf("migrate unknown obj err = %v, want %v", err,
+			roc.ErrUnknowObj)
+	}
+	if err := a.MigrateROCObj(ctx, objType, "plain",
+		b.moduleid); err != roc.ErrNotMigratable {
+		t.Errorf("migrate plain obj err = %v, want %v", err,
+			roc.ErrNotMigratable)
+	}
+	// 目标模块没有设置对象加载器，对象保留在本模块中
+	b.NewROC(objType)
+	if err := a.MigrateROCObj(ctx, objType, "1", b.moduleid); err == nil {
+		t.Fatalf("migrate without loader err = nil")
+	}
+	if n, err := callTestInt(b, roc.O(objType, "1").F("Add"), "1"); err != nil ||
+		n != 11 {
+		t.Fatalf("Add after failed migration = %d, %v, want 11", n, err)
+	}
+	if got := b.GetROCCachedLocation(objType, "1"); got != a.moduleid {
+		t.Fatalf("cached location = %q, want %q", got, a.moduleid)
+	}
+}
+
+// 在调用处理中同步迁移自身的测试对象
+type testMigrateSelfObj struct {
+	*testObj
+	server *Server
+	to     string
+}
+
+func (this *testMigrateSelfObj) OnROCCall(path *roc.ROCPath,
+	arg []byte) ([]byte, error) {
+	if path.Get(path.GetPos()) != "MigrateSelf" {
+		return this.testObj.OnROCCall(path, arg)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(),
+		50*time.Millisecond)
+	defer cancel()
+	return nil, this.server.MigrateROCObj(ctx, this.objType, this.objID,
+		this.to)
+}
+
+func TestMigrateROCObjFromHandler(t *testing.T) {
+	a := newTestServer(t, "testmig", nil)
+	b := newTestServer(t, "testmig", nil)
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestMigrateSelfObj")
+	b.NewROC(objType).SetObjLoader(loadTestObj(objType))
+	obj := &testMigrateSelfObj{
+		testObj: newTestObj(objType, "1", 10),
+		server:  a,
+		to:      b.moduleid,
+	}
+	a.NewROC(objType).GetOrRegObj("1", obj)
+
+	// 在对象自身的调用处理中同步迁移，迁移在 ctx 结束时失败，调用处理不会被阻塞
+	_, err := a.ROCCallBlock(roc.O(objType, "1").F("MigrateSelf"), nil)
+	if !errors.Is(err, roc.ErrCallTimeout) {
+		t.Fatalf("migrate from handler err = %v, want %v", err,
+			roc.ErrCallTimeout)
+	}
+	if n, err := callTestInt(a, roc.O(objType, "1").F("Add"), "1"); err != nil ||
+		n != 11 {
+		t.Fatalf("Add after failed migration = %d, %v, want 11", n, err)
+	}
+	if _, ok := b.GetROC(objType).GetObj("1"); ok {
+		t.Fatalf("obj migrated to target module")
+	}
+}
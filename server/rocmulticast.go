@@ -0,0 +1,262 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/liasece/micserver/connect"
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/servercomm"
+	"github.com/liasece/micserver/trace"
+)
+
+// 批量调用中发往一个模块的对象
+type multicastBatch struct {
+	moduleid string
+	objIDs   []string
+}
+
+// 对一个类型所有已知的ROC对象调用同一个方法，目标对象按所在的模块分组，
+// 每个模块只发送一个批量请求。
+// 任意对象调用失败时，返回的错误可以通过 errors.Is(err, roc.ErrMulticast) 判断，
+// 每个对象的调用结果见返回的报告。
+// 批量调用作为一次调用经过客户端拦截器，拦截器收到的 roc.CallInfo 中 Multicast
+// 为 true ，拦截器没有继续执行调用链时，返回的报告为 nil
+func (this *ROCServer) ROCMulticast(ctx context.Context,
+	objType roc.ROCObjType, callFunc string, callarg []byte,
+	opts *roc.MulticastOptions) (*roc.MulticastReport, error) {
+	if opts == nil {
+		opts = &roc.MulticastOptions{}
+	}
+	var report *roc.MulticastReport
+	_, err := this.invokeROCCall(ctx, &roc.CallInfo{
+		Path:       roc.O(objType, "").F(callFunc),
+		Arg:        callarg,
+		NeedReturn: opts.NeedReturn,
+		Multicast:  true,
+	}, func(ctx context.Context, info *roc.CallInfo) ([]byte, error) {
+		var err error
+		report, err = this.rocMulticast(ctx, info, opts)
+		return nil, err
+	})
+	return report, err
+}
+
+func (this *ROCServer) rocMulticast(ctx context.Context, info *roc.CallInfo,
+	opts *roc.MulticastOptions) (*roc.MulticastReport, error) {
+	objType := info.Path.GetObjType()
+	callFunc := info.Path.Get(0)
+	callarg := info.Arg
+	if err := this.checkPayloadSize(int64(len(callarg))); err != nil {
+		return nil, err
+	}
+	batches := this.groupMulticastTarget(objType, opts.Filter)
+	this.Syslog("ROCMulticast %s.%s to %d module", objType, callFunc,
+		len(batches))
+
+	report := &roc.MulticastReport{
+		Results: make([]*roc.MulticastResult, 0),
+	}
+	var reportMutex sync.Mutex
+	var wg sync.WaitGroup
+	var sem chan struct{}
+	if opts.NeedReturn && opts.Concurrency > 0 {
+		sem = make(chan struct{}, opts.Concurrency)
+	}
+	for _, batch := range batches {
+		if sem != nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				// 未能发送的对象全部失败
+				results := newMulticastResults(batch)
+				setMulticastErr(results, roc.ContextErr(ctx))
+				reportMutex.Lock()
+				report.Results = append(report.Results, results...)
+				reportMutex.Unlock()
+				continue
+			}
+		}
+		wg.Add(1)
+		go func(batch *multicastBatch) {
+			defer wg.Done()
+			if sem != nil {
+				defer func() { <-sem }()
+			}
+			results := this.sendMulticastBatch(ctx, objType, callFunc, callarg,
+				opts.NeedReturn, batch)
+			reportMutex.Lock()
+			report.Results = append(report.Results, results...)
+			reportMutex.Unlock()
+		}(batch)
+	}
+	wg.Wait()
+
+	if failed := report.Failed(); len(failed) > 0 {
+		this.Warn("ROCMulticast %s.%s %d/%d failed", objType, callFunc,
+			len(failed), len(report.Results))
+		return report, fmt.Errorf("%w: %d/%d failed", roc.ErrMulticast,
+			len(failed), len(report.Results))
+	}
+	return report, nil
+}
+
+// 将缓存中本模块可以访问的目标类型的对象按所在的模块分组
+func (this *ROCServer) groupMulticastTarget(objType roc.ROCObjType,
+	filter func(objID string) bool) []*multicastBatch {
+	reachable := map[string]bool{
+		this.server.moduleid: true,
+	}
+	this.server.subnetManager.RangeServer(func(server *connect.Server) bool {
+		if server.ModuleInfo != nil {
+			reachable[server.ModuleInfo.ModuleID] = true
+		}
+		return true
+	})
+	groups := make(map[string]*multicastBatch)
+	res := make([]*multicastBatch, 0)
+	roc.GetCache().RangeByType(objType, func(id string,
+		location string) bool {
+		if filter != nil && !filter(id) {
+			return true
+		}
+		batch, ok := groups[location]
+		if !ok {
+			batch = &multicastBatch{
+				moduleid: location,
+			}
+			groups[location] = batch
+			res = append(res, batch)
+		}
+		batch.objIDs = append(batch.objIDs, id)
+		return true
+	}, reachable)
+	return res
+}
+
+// 向一个模块发送批量调用请求，需要返回值时等待该模块中每个对象的返回值
+func (this *ROCServer) sendMulticastBatch(ctx context.Context,
+	objType roc.ROCObjType, callFunc string, callarg []byte,
+	needReturn bool, batch *multicastBatch) []*roc.MulticastResult {
+	results := newMulticastResults(batch)
+	sc := trace.SpanContextFromContext(ctx)
+	seqs := make([]int64, len(batch.objIDs))
+	chans := make([]chan *responseAgent, len(batch.objIDs))
+	for i := range batch.objIDs {
+		seqs[i] = this.newSeq()
+		if needReturn {
+			chans[i] = this.addBlockChan(seqs[i])
+		}
+	}
+
+	if local := this.getLocalROCServer(batch.moduleid); local != nil {
+		// 调用方是工作协程时，等待期间不占用工作协程的数量
+		if worker := rocWorkerFromContext(ctx); worker != nil && needReturn {
+			worker.blockBegin()
+			defer worker.blockEnd()
+		}
+		for i, id := range batch.objIDs {
+			local.pushROCRequest(&requestAgent{
+				fromModuleID: this.server.moduleid,
+				callpath:     roc.O(objType, id).F(callFunc).String(),
+				callarg:      callarg,
+				seq:          seqs[i],
+				needReturn:   needReturn,
+				caller:       this,
+				spanCtx:      sc,
+			})
+		}
+	} else {
+		server := this.server.subnetManager.GetServer(batch.moduleid)
+		if server == nil {
+			for i := range seqs {
+				this.rocBlockChanMap.Delete(seqs[i])
+			}
+			setMulticastErr(results, roc.ErrUnknowObj)
+			return results
+		}
+		// 与目标模块的连接断开时，等待中的调用以 roc.ErrConnLost 结束
+		if needReturn {
+			for _, seq := range seqs {
+				this.pendingCalls.Store(seq, batch.moduleid)
+			}
+			defer func() {
+				for _, seq := range seqs {
+					this.pendingCalls.Delete(seq)
+				}
+			}()
+		}
+		this.sendROCMsg(server, &servercomm.SROCMulticast{
+			FromModuleID: this.server.moduleid,
+			ToModuleID:   batch.moduleid,
+			ObjType:      string(objType),
+			ObjIDs:       batch.objIDs,
+			Seqs:         seqs,
+			CallFunc:     callFunc,
+			CallArg:      callarg,
+			NeedReturn:   needReturn,
+			TraceID:      sc.TraceID,
+			SpanID:       sc.SpanID,
+		})
+	}
+	if !needReturn {
+		return results
+	}
+
+	for i, ch := range chans {
+		select {
+		case agent := <-ch:
+			results[i].Data = agent.data
+			results[i].Err = agent.err
+		case <-ctx.Done():
+			// 不再等待剩余的返回值
+			for j := i; j < len(seqs); j++ {
+				this.rocBlockChanMap.Delete(seqs[j])
+			}
+			setMulticastErr(results[i:], roc.ContextErr(ctx))
+			return results
+		}
+	}
+	return results
+}
+
+// 当收到ROC批量调用请求时，将每个对象的调用分别投递到该对象的邮箱
+func (this *ROCServer) onMsgROCMulticast(msg *servercomm.SROCMulticast) {
+	if len(msg.Seqs) != len(msg.ObjIDs) {
+		this.Error("onMsgROCMulticast seqs num %d mismatch objs num %d",
+			len(msg.Seqs), len(msg.ObjIDs))
+		return
+	}
+	objType := roc.ROCObjType(msg.ObjType)
+	for i, id := range msg.ObjIDs {
+		this.pushROCRequest(&requestAgent{
+			fromModuleID: msg.FromModuleID,
+			callpath:     roc.O(objType, id).F(msg.CallFunc).String(),
+			callarg:      msg.CallArg,
+			seq:          msg.Seqs[i],
+			needReturn:   msg.NeedReturn,
+			spanCtx: trace.SpanContext{
+				TraceID: msg.TraceID,
+				SpanID:  msg.SpanID,
+			},
+		})
+	}
+}
+
+func newMulticastResults(batch *multicastBatch) []*roc.MulticastResult {
+	res := make([]*roc.MulticastResult, len(batch.objIDs))
+	for i, id := range batch.objIDs {
+		res[i] = &roc.MulticastResult{
+			ObjID:    id,
+			ModuleID: batch.moduleid,
+		}
+	}
+	return res
+}
+
+func setMulticastErr(results []*roc.MulticastResult, err error) {
+	for _, v := range results {
+		v.Err = err
+	}
+}
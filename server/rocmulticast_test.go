@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/trace"
+)
+
+// 记录调用处理中的调用链标识的测试对象
+type testTraceObj struct {
+	*testObj
+}
+
+func (this *testTraceObj) OnROCCall(path *roc.ROCPath,
+	arg []byte) ([]byte, error) {
+	this.record(trace.SpanContextFromContext(path.Context()).TraceID)
+	return nil, nil
+}
+
+func TestROCMulticastInterceptor(t *testing.T) {
+	a := newTestServer(t, "testmulticast", nil)
+	b := newTestServer(t, "testmulticast", nil)
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestMulticastObj")
+	b.NewROC(objType).GetOrRegObj("1", newTestObj(objType, "1", 0))
+	b.GetROC(objType).GetOrRegObj("2", newTestObj(objType, "2", 10))
+
+	// 批量调用作为一次调用经过客户端拦截器
+	infos := make([]*roc.CallInfo, 0)
+	errReject := errors.New("rejected by interceptor")
+	a.AddROCClientInterceptor(func(ctx context.Context, info *roc.CallInfo,
+		next roc.ClientInvoker) ([]byte, error) {
+		infos = append(infos, info)
+		if string(info.Arg) == "reject" {
+			return nil, errReject
+		}
+		return next(ctx, info)
+	})
+	report, err := a.ROCMulticast(context.Background(), objType, "Add",
+		[]byte("1"), &roc.MulticastOptions{NeedReturn: true})
+	if err != nil {
+		t.Fatalf("ROCMulticast err: %v", err)
+	}
+	if len(report.Results) != 2 {
+		t.Fatalf("results num = %d, want 2", len(report.Results))
+	}
+	if len(infos) != 1 || !infos[0].Multicast ||
+		infos[0].Path.GetObjType() != objType || infos[0].Path.Get(0) != "Add" {
+		t.Fatalf("intercepted calls = %+v, want one multicast Add", infos)
+	}
+
+	// 拦截器没有继续执行调用链时，调用不会发出
+	report, err = a.ROCMulticast(context.Background(), objType, "Add",
+		[]byte("reject"), &roc.MulticastOptions{NeedReturn: true})
+	if !errors.Is(err, errReject) || report != nil {
+		t.Fatalf("rejected multicast = %v, %v, want nil, %v", report, err,
+			errReject)
+	}
+}
+
+func TestROCMulticastTrace(t *testing.T) {
+	a := newTestServer(t, "testmulticast", nil)
+	b := newTestServer(t, "testmulticast", nil)
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestMulticastTraceObj")
+	obj := &testTraceObj{newTestObj(objType, "1", 0)}
+	b.NewROC(objType).GetOrRegObj("1", obj)
+
+	// 被调用方的调用处理延续调用方的调用链
+	ctx := trace.ContextWithSpanContext(context.Background(),
+		trace.SpanContext{TraceID: "multicasttrace", SpanID: "root"})
+	if _, err := a.ROCMulticast(ctx, objType, "Trace", nil,
+		&roc.MulticastOptions{NeedReturn: true}); err != nil {
+		t.Fatalf("ROCMulticast err: %v", err)
+	}
+	if calls := obj.getCalls(); len(calls) != 1 ||
+		calls[0] != "multicasttrace" {
+		t.Fatalf("callee trace ids = %q, want [multicasttrace]", calls)
+	}
+}
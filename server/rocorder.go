@@ -0,0 +1,197 @@
+package server
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/liasece/micserver/msg"
+	"github.com/liasece/micserver/servercomm"
+)
+
+// 向一个模块发送ROC消息的排序状态
+type rocOrderSender struct {
+	mutex sync.Mutex
+	// 排序会话，与目标模块的连接全部断开后重新开始
+	session int64
+	seq     int64
+}
+
+// 接收一个模块的ROC消息的排序状态
+type rocOrderReceiver struct {
+	session int64
+	// 下一个应该处理的序号
+	next int64
+	// 已到达但在等待之前的消息的处理函数，键为序号
+	pending map[int64]func()
+	// 不再等待顺序，按次序直接处理的处理函数
+	ready []func()
+	// 是否有协程正在依次处理该模块的消息
+	draining bool
+	// 与该模块的连接全部断开后，当前会话中延迟到达的消息不再排序
+	closed bool
+	// 开始等待 next 对应的消息的时间
+	waitTime time.Time
+}
+
+// 获取向目标模块发送ROC消息的排序状态
+func (this *ROCServer) getROCOrderSender(moduleid string) *rocOrderSender {
+	vi, _ := this.orderSenders.LoadOrStore(moduleid, &rocOrderSender{
+		session: time.Now().UnixNano(),
+	})
+	return vi.(*rocOrderSender)
+}
+
+// 设置ROC消息的排序会话及序号，不支持排序的消息返回 false
+func setROCMsgOrder(sendmsg msg.MsgStruct, session, seq int64) bool {
+	switch m := sendmsg.(type) {
+	case *servercomm.SROCRequest:
+		m.OrderSession, m.OrderSeq = session, seq
+	case *servercomm.SROCResponse:
+		m.OrderSession, m.OrderSeq = session, seq
+	case *servercomm.SROCMulticast:
+		m.OrderSession, m.OrderSeq = session, seq
+	case *servercomm.SROCStreamFrame:
+		m.OrderSession, m.OrderSeq = session, seq
+	case *servercomm.SROCMigrate:
+		m.OrderSession, m.OrderSeq = session, seq
+	default:
+		return false
+	}
+	return true
+}
+
+// 按发送的顺序处理来自一个模块的ROC消息。
+// 同一模块的消息可能经过不同的连接到达，分片传输的消息在所有分片到达后才能处理，
+// 因此先到达的消息需要等待发送方在其之前发送的消息处理完毕。
+// 等待超过 rocChunkTTL 仍未到达的消息被认为已丢失，不再等待
+func (this *ROCServer) recvOrderedROCMsg(moduleid string, session, seq int64,
+	handle func()) {
+	if seq == 0 {
+		handle()
+		return
+	}
+	now := time.Now()
+	this.orderMutex.Lock()
+	if this.orderReceivers == nil {
+		this.orderReceivers = make(map[string]*rocOrderReceiver)
+	}
+	receiver, ok := this.orderReceivers[moduleid]
+	if !ok {
+		receiver = &rocOrderReceiver{
+			pending: make(map[int64]func()),
+		}
+		this.orderReceivers[moduleid] = receiver
+	}
+	switch {
+	case session < receiver.session ||
+		(session == receiver.session && receiver.closed):
+		// 之前的会话中延迟到达的消息，该会话的顺序已无法保证
+		receiver.ready = append(receiver.ready, handle)
+	case session > receiver.session:
+		// 发送方开始了新的会话，之前会话中等待的消息不再等待
+		receiver.flushNoLock()
+		receiver.session = session
+		receiver.next = 1
+		receiver.closed = false
+		receiver.waitTime = now
+		receiver.pending[seq] = handle
+	case seq < receiver.next:
+		this.orderMutex.Unlock()
+		this.Warn("recvOrderedROCMsg drop stale msg from %s Seq[%d] Next[%d]",
+			moduleid, seq, receiver.next)
+		return
+	default:
+		if len(receiver.pending) == 0 {
+			receiver.waitTime = now
+		}
+		receiver.pending[seq] = handle
+	}
+	if _, ok := receiver.pending[receiver.next]; !ok &&
+		len(receiver.pending) > 0 && now.Sub(receiver.waitTime) > rocChunkTTL {
+		this.Warn("recvOrderedROCMsg msg from %s Seq[%d] lost, skip",
+			moduleid, receiver.next)
+		receiver.skipNoLock()
+	}
+	this.drainROCOrderNoLock(receiver)
+}
+
+// 依次处理已可以处理的消息，调用方需要持有 orderMutex ，返回时已释放。
+// 处理消息时不持有锁，同一模块的消息同时只在一个协程中处理
+func (this *ROCServer) drainROCOrderNoLock(receiver *rocOrderReceiver) {
+	if receiver.draining {
+		this.orderMutex.Unlock()
+		return
+	}
+	receiver.draining = true
+	for {
+		var handle func()
+		if len(receiver.ready) > 0 {
+			handle = receiver.ready[0]
+			receiver.ready = receiver.ready[1:]
+		} else if fn, ok := receiver.pending[receiver.next]; ok {
+			handle = fn
+			delete(receiver.pending, receiver.next)
+			receiver.next++
+			receiver.waitTime = time.Now()
+		} else {
+			break
+		}
+		this.orderMutex.Unlock()
+		handle()
+		this.orderMutex.Lock()
+	}
+	receiver.draining = false
+	this.orderMutex.Unlock()
+}
+
+// 等待中的消息按序号排列到可直接处理的消息之后，调用方需要持有 orderMutex
+func (this *rocOrderReceiver) flushNoLock() {
+	seqs := make([]int64, 0, len(this.pending))
+	for seq := range this.pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	for _, seq := range seqs {
+		this.ready = append(this.ready, this.pending[seq])
+		delete(this.pending, seq)
+	}
+}
+
+// 跳过丢失的消息，从已到达的最小序号继续处理，调用方需要持有 orderMutex
+func (this *rocOrderReceiver) skipNoLock() {
+	next := int64(0)
+	for seq := range this.pending {
+		if next == 0 || seq < next {
+			next = seq
+		}
+	}
+	if next > this.next {
+		this.next = next
+	}
+}
+
+// 收到分片传输的分片时，该传输仍在进行中，推迟认为其已丢失的时间
+func (this *ROCServer) touchROCOrder(moduleid string, session, seq int64) {
+	this.orderMutex.Lock()
+	defer this.orderMutex.Unlock()
+	if receiver, ok := this.orderReceivers[moduleid]; ok &&
+		receiver.session == session && receiver.next == seq {
+		receiver.waitTime = time.Now()
+	}
+}
+
+// 与一个模块的连接全部断开时，之后发送的消息使用新的排序会话，
+// 该模块已到达的消息不再等待之前丢失的消息
+func (this *ROCServer) onOrderModuleLeave(moduleid string) {
+	this.orderSenders.Delete(moduleid)
+	this.orderMutex.Lock()
+	receiver, ok := this.orderReceivers[moduleid]
+	if !ok {
+		this.orderMutex.Unlock()
+		return
+	}
+	receiver.closed = true
+	receiver.flushNoLock()
+	this.drainROCOrderNoLock(receiver)
+}
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/liasece/micserver/conf"
+	"github.com/liasece/micserver/connect"
+	"github.com/liasece/micserver/process"
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/roc/persist"
+)
+
+// ROC对象快照等待对象邮箱的默认超时时间
+const defaultROCSnapshotTimeout = 10 * time.Second
+
+// 快照中的一个ROC对象
+type rocSnapshotItem struct {
+	ObjType string `json:"type"`
+	ObjID   string `json:"id"`
+	State   []byte `json:"state"`
+}
+
+// 快照时一个ROC对象状态的取得结果
+type rocSnapshotSlot struct {
+	objType roc.ROCObjType
+	objID   string
+	// 是否已在邮箱中取得状态，对象已被删除或迁出时 item 为空
+	done bool
+	item *rocSnapshotItem
+	err  error
+}
+
+// 设置ROC对象的持久化存储，需要在模块连接子网之前设置，例如在 AfterInitModule 中，
+// 设置后，直到 RestoreROCObj 恢复完成之前，本模块的ROC对象绑定信息都不会发送给
+// 其他模块
+func (this *ROCServer) SetROCStorage(storage persist.Storage) {
+	this.localObjMutex.Lock()
+	defer this.localObjMutex.Unlock()
+	this.persistStorage = storage
+	this.restoringObj = storage != nil
+}
+
+// 获取ROC对象的持久化存储
+func (this *ROCServer) GetROCStorage() persist.Storage {
+	this.localObjMutex.Lock()
+	defer this.localObjMutex.Unlock()
+	return this.persistStorage
+}
+
+// 获取ROC对象快照等待对象邮箱的超时时间
+func (this *ROCServer) getSnapshotTimeout() time.Duration {
+	timeout := this.server.moduleConfig.GetInt64(conf.ROCSnapshotTimeout)
+	if timeout <= 0 {
+		return defaultROCSnapshotTimeout
+	}
+	return time.Duration(timeout) * time.Millisecond
+}
+
+// 将本模块中所有实现了 roc.Persistable 接口的ROC对象的状态保存到持久化存储中，
+// 没有设置持久化存储时不做任何事。每个对象的状态在其邮箱中取得，不会与该对象
+// 正在处理的调用并发执行，所有对象的状态都取得后才写入存储。
+// 等待超过 conf.ROCSnapshotTimeout 时，保存已取得的对象的状态，其余对象保留
+// 上一次快照中的状态，并返回 roc.ErrCallTimeout
+func (this *ROCServer) SnapshotROCObj() error {
+	storage := this.GetROCStorage()
+	if storage == nil {
+		return nil
+	}
+	// 每个对象的状态写入各自的位置，等待超时后迟到的结果被丢弃
+	slots := make([]*rocSnapshotSlot, 0)
+	closed := false
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	this._ROCManager.RangeROC(func(objtype roc.ROCObjType, r *roc.ROC) bool {
+		r.RangeObj(func(obj roc.IObj) bool {
+			p, ok := obj.(roc.Persistable)
+			if !ok || !p.ROCPersistent() {
+				return true
+			}
+			objID := obj.GetROCObjID()
+			slot := &rocSnapshotSlot{
+				objType: objtype,
+				objID:   objID,
+			}
+			mutex.Lock()
+			slots = append(slots, slot)
+			mutex.Unlock()
+			wg.Add(1)
+			this.pushROCRequest(&requestAgent{
+				objType: objtype,
+				objID:   objID,
+				fn: func() {
+					defer wg.Done()
+					var state []byte
+					var err error
+					// 对象在等待期间被删除或迁出时不保存
+					cur, ok := r.GetObj(objID)
+					if ok && cur == obj {
+						state, err = p.MarshalROCState()
+					}
+					mutex.Lock()
+					defer mutex.Unlock()
+					if closed {
+						return
+					}
+					slot.done = true
+					if err != nil {
+						slot.err = err
+						this.Error("SnapshotROCObj %s[%s] err:%s",
+							objtype, objID, err.Error())
+						return
+					}
+					if ok && cur == obj {
+						slot.item = &rocSnapshotItem{
+							ObjType: string(objtype),
+							ObjID:   objID,
+							State:   state,
+						}
+					}
+				},
+			})
+			return true
+		})
+		return true
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		this.getSnapshotTimeout())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = roc.ContextErr(ctx)
+		this.Error("SnapshotROCObj wait roc obj mailbox err:%s", err.Error())
+	}
+
+	mutex.Lock()
+	closed = true
+	items := make([]*rocSnapshotItem, 0, len(slots))
+	// 未取得状态的对象保留上一次快照中的状态
+	var last map[string]*rocSnapshotItem
+	for _, slot := range slots {
+		if !slot.done {
+			if last == nil {
+				last = this.loadLastSnapshot(storage)
+			}
+			if item, ok := last[migrateKey(slot.objType, slot.objID)]; ok {
+				items = append(items, item)
+			}
+			continue
+		}
+		if slot.err != nil && err == nil {
+			err = slot.err
+		}
+		if slot.item != nil {
+			items = append(items, slot.item)
+		}
+	}
+	mutex.Unlock()
+	data, marshalErr := json.Marshal(items)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	if saveErr := storage.Save(this.server.moduleid, data); saveErr != nil {
+		return saveErr
+	}
+	this.Syslog("SnapshotROCObj saved %d roc obj", len(items))
+	return err
+}
+
+// 读取上一次保存的快照，键为 roc.O(objType, objID).String() ，读取失败时返回空
+func (this *ROCServer) loadLastSnapshot(
+	storage persist.Storage) map[string]*rocSnapshotItem {
+	res := make(map[string]*rocSnapshotItem)
+	data, err := storage.Load(this.server.moduleid)
+	if err != nil {
+		if !errors.Is(err, persist.ErrNotExist) {
+			this.Error("SnapshotROCObj load last snapshot err:%s", err.Error())
+		}
+		return res
+	}
+	items := make([]*rocSnapshotItem, 0)
+	if err := json.Unmarshal(data, &items); err != nil {
+		this.Error("SnapshotROCObj unmarshal last snapshot err:%s",
+			err.Error())
+		return res
+	}
+	for _, item := range items {
+		res[migrateKey(roc.ROCObjType(item.ObjType), item.ObjID)] = item
+	}
+	return res
+}
+
+// 从持久化存储中恢复本模块的ROC对象，需要在模块连接子网之前调用，对象所属类型的ROC
+// 需要已经通过 roc.ROC.SetObjLoader 设置了对象加载器。
+// 恢复完成后，已连接的模块才会开始同步本模块的ROC对象绑定信息
+func (this *ROCServer) RestoreROCObj() error {
+	defer this.finishRestore()
+	storage := this.GetROCStorage()
+	if storage == nil {
+		return nil
+	}
+	data, err := storage.Load(this.server.moduleid)
+	if errors.Is(err, persist.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	items := make([]*rocSnapshotItem, 0)
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	num := 0
+	for _, item := range items {
+		loadErr := this.loadROCObj(roc.ROCObjType(item.ObjType), item.ObjID,
+			item.State, 0)
+		if loadErr != nil {
+			this.Error("RestoreROCObj %s[%s] err:%s",
+				item.ObjType, item.ObjID, loadErr.Error())
+			err = loadErr
+			continue
+		}
+		num++
+	}
+	this.Syslog("RestoreROCObj restored %d/%d roc obj", num, len(items))
+	return err
+}
+
+// 结束恢复，向已连接的模块发送绑定信息的版本，对方将请求同步本模块所有的ROC对象
+func (this *ROCServer) finishRestore() {
+	this.localObjMutex.Lock()
+	defer this.localObjMutex.Unlock()
+	if !this.restoringObj {
+		return
+	}
+	this.restoringObj = false
+	// 恢复期间注册的对象没有发送过绑定信息，递增版本使已同步过的模块重新同步
+	this.bindMutex.Lock()
+	this.bindVersion++
+	this.bindMutex.Unlock()
+	if this.server.subnetManager == nil {
+		return
+	}
+	this.server.subnetManager.RangeServer(func(s *connect.Server) bool {
+		if s.ModuleInfo != nil && !process.HasModule(s.ModuleInfo.ModuleID) {
+			this.sendROCBindVersion(s)
+		}
+		return true
+	})
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/liasece/micserver/conf"
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/roc/persist"
+)
+
+// 需要持久化的测试对象
+type testPersistObj struct {
+	*testObj
+}
+
+func (this *testPersistObj) ROCPersistent() bool {
+	return true
+}
+
+// 读取存储中保存的快照，键为对象ID
+func loadTestSnapshot(t *testing.T, s *Server) map[string]string {
+	t.Helper()
+	data, err := s.GetROCStorage().Load(s.moduleid)
+	if err != nil {
+		t.Fatalf("load snapshot err: %v", err)
+	}
+	items := make([]*rocSnapshotItem, 0)
+	if err := json.Unmarshal(data, &items); err != nil {
+		t.Fatalf("unmarshal snapshot err: %v", err)
+	}
+	res := make(map[string]string)
+	for _, item := range items {
+		res[item.ObjID] = string(item.State)
+	}
+	return res
+}
+
+func TestSnapshotROCObjTimeout(t *testing.T) {
+	a := newTestServer(t, "testpersist", conf.BaseConfig{
+		string(conf.ROCSnapshotTimeout): 50,
+	})
+	storage, err := persist.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStorage err: %v", err)
+	}
+	a.SetROCStorage(storage)
+	if err := a.RestoreROCObj(); err != nil {
+		t.Fatalf("RestoreROCObj err: %v", err)
+	}
+
+	objType := newTestObjType("TestPersistObj")
+	obj1 := newTestObj(objType, "1", 1)
+	obj2 := newTestObj(objType, "2", 2)
+	a.NewROC(objType).GetOrRegObj("1", &testPersistObj{obj1})
+	a.GetROC(objType).GetOrRegObj("2", &testPersistObj{obj2})
+	if err := a.SnapshotROCObj(); err != nil {
+		t.Fatalf("SnapshotROCObj err: %v", err)
+	}
+
+	// 超时时保存已取得的状态，未取得状态的对象保留上一次快照中的状态
+	if _, err := callTestInt(a, roc.O(objType, "1").F("Add"), "10"); err != nil {
+		t.Fatalf("Add err: %v", err)
+	}
+	waitRes := goCallTest(a, roc.O(objType, "2").F("Wait"), "")
+	obj2.waitCall(t, "Wait ")
+	if err := a.SnapshotROCObj(); !errors.Is(err, roc.ErrCallTimeout) {
+		t.Fatalf("SnapshotROCObj err = %v, want %v", err, roc.ErrCallTimeout)
+	}
+	if got := loadTestSnapshot(t, a); got["1"] != "11" || got["2"] != "2" {
+		t.Fatalf("snapshot = %v, want 1:11 2:2", got)
+	}
+	close(obj2.wait)
+	if err := <-waitRes; err != nil {
+		t.Fatalf("Wait err: %v", err)
+	}
+	// 对象的调用结束后，之后的快照正常取得所有对象的状态
+	if err := a.SnapshotROCObj(); err != nil {
+		t.Fatalf("SnapshotROCObj err: %v", err)
+	}
+	if got := loadTestSnapshot(t, a); got["1"] != "11" || got["2"] != "2" {
+		t.Fatalf("snapshot after wait = %v, want 1:11 2:2", got)
+	}
+}
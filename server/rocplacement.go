@@ -0,0 +1,174 @@
+package server
+
+import (
+	"github.com/liasece/micserver/conf"
+	"github.com/liasece/micserver/connect"
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/util"
+	"github.com/liasece/micserver/util/hash"
+)
+
+// 一个模块类型的在线模块发生变化时的通知
+type PlacementChange struct {
+	ModuleType string
+	// 变化后该类型在线的模块，按模块ID排序
+	Members []string
+	// 加入或离开的模块，二者只有一个不为空
+	Joined string
+	Left   string
+	// 本模块中按一致性哈希已不再属于本模块的ROC对象，键为ROC对象类型，
+	// 可以通过 MigrateROCObj 迁移到新的所属模块
+	Misplaced map[roc.ROCObjType][]string
+}
+
+// 放置变化的监听函数，在处理模块加入或离开子网的协程中调用，不应长时间阻塞
+type PlacementListener func(change *PlacementChange)
+
+// 获取模块类型的一致性哈希环，第一次获取时使用当前在线的该类型的模块构建
+func (this *ROCServer) getPlacementRing(moduleType string) *hash.Ring {
+	this.placementMutex.Lock()
+	defer this.placementMutex.Unlock()
+	if ring, ok := this.placementRings[moduleType]; ok {
+		return ring
+	}
+	if this.placementRings == nil {
+		this.placementRings = make(map[string]*hash.Ring)
+	}
+	replicas := 0
+	if this.server.moduleConfig != nil {
+		replicas = int(this.server.moduleConfig.GetInt64(
+			conf.ROCPlacementReplicas))
+	}
+	ring := hash.NewRing(replicas)
+	if util.GetModuleIDType(this.server.moduleid) == moduleType {
+		ring.Add(this.server.moduleid)
+	}
+	if this.server.subnetManager != nil {
+		this.server.subnetManager.RangeServer(func(s *connect.Server) bool {
+			if s.ModuleInfo != nil &&
+				util.GetModuleIDType(s.ModuleInfo.ModuleID) == moduleType {
+				ring.Add(s.ModuleInfo.ModuleID)
+			}
+			return true
+		})
+	}
+	this.placementRings[moduleType] = ring
+	return ring
+}
+
+// 按一致性哈希获取 key 所属的该类型的在线模块，与 GetBalanceModuleID 不同，
+// 在线模块不变时相同的 key 总是得到相同的模块，没有该类型的在线模块时返回空字符串
+func (this *ROCServer) GetConsistentModuleID(moduleType string,
+	key string) string {
+	return this.getPlacementRing(moduleType).Get(key)
+}
+
+// 设置该类型的ROC对象按一致性哈希放置在 moduleType 类型的模块中，
+// GetROCObjOwner 将返回对象所属的模块，按需激活的对象也将在所属的模块中激活，
+// 见 roc.ROC.SetObjFactory
+func (this *ROCServer) SetROCPlacement(objType roc.ROCObjType,
+	moduleType string) {
+	this.getPlacementRing(moduleType)
+	this.placementMutex.Lock()
+	if this.placementTypes == nil {
+		this.placementTypes = make(map[roc.ROCObjType]string)
+	}
+	this.placementTypes[objType] = moduleType
+	this.placementMutex.Unlock()
+	this.NewROC(objType).SetPlacement(
+		func(objID string, candidates []string) string {
+			owner := this.GetROCObjOwner(objType, objID)
+			for _, moduleid := range candidates {
+				if moduleid == owner {
+					return owner
+				}
+			}
+			// 所属的模块不能激活该对象
+			return roc.PlaceByHash(objID, candidates)
+		})
+}
+
+// 获取按一致性哈希该ROC对象所属的模块，该类型没有通过 SetROCPlacement 设置
+// 放置规则或者没有在线的模块时返回空字符串。创建对象时可以在所属的模块中创建，
+// 代替随机选择模块的 GetBalanceModuleID
+func (this *ROCServer) GetROCObjOwner(objType roc.ROCObjType,
+	objID string) string {
+	this.placementMutex.Lock()
+	moduleType, ok := this.placementTypes[objType]
+	this.placementMutex.Unlock()
+	if !ok {
+		return ""
+	}
+	return this.GetConsistentModuleID(moduleType,
+		roc.O(objType, objID).String())
+}
+
+// 添加放置变化的监听函数，已经构建了一致性哈希环的模块类型的在线模块变化时调用
+func (this *ROCServer) AddROCPlacementListener(listener PlacementListener) {
+	this.placementMutex.Lock()
+	defer this.placementMutex.Unlock()
+	this.placementListeners = append(this.placementListeners, listener)
+}
+
+// 当一个模块加入或离开子网时，更新其类型的一致性哈希环并通知监听函数
+func (this *ROCServer) onPlacementModuleChange(moduleid string,
+	isLeave bool) {
+	moduleType := util.GetModuleIDType(moduleid)
+	this.placementMutex.Lock()
+	ring, ok := this.placementRings[moduleType]
+	listeners := this.placementListeners
+	this.placementMutex.Unlock()
+	if !ok {
+		return
+	}
+	change := &PlacementChange{
+		ModuleType: moduleType,
+	}
+	if isLeave {
+		if !ring.Remove(moduleid) {
+			return
+		}
+		change.Left = moduleid
+	} else {
+		if !ring.Add(moduleid) {
+			return
+		}
+		change.Joined = moduleid
+	}
+	change.Members = ring.Nodes()
+	change.Misplaced = this.getMisplacedObj(moduleType)
+	this.Syslog("onPlacementModuleChange ModuleType[%s] Members%v "+
+		"Misplaced[%d]", moduleType, change.Members, len(change.Misplaced))
+	for _, listener := range listeners {
+		listener(change)
+	}
+}
+
+// 获取本模块中放置在该模块类型中，但按一致性哈希已不再属于本模块的ROC对象
+func (this *ROCServer) getMisplacedObj(
+	moduleType string) map[roc.ROCObjType][]string {
+	objTypes := make([]roc.ROCObjType, 0)
+	this.placementMutex.Lock()
+	for objType, t := range this.placementTypes {
+		if t == moduleType {
+			objTypes = append(objTypes, objType)
+		}
+	}
+	this.placementMutex.Unlock()
+	res := make(map[roc.ROCObjType][]string)
+	for _, objType := range objTypes {
+		r := this.GetROC(objType)
+		if r == nil {
+			continue
+		}
+		r.RangeObj(func(obj roc.IObj) bool {
+			id := obj.GetROCObjID()
+			if owner := this.GetROCObjOwner(objType, id); owner != "" &&
+				owner != this.server.moduleid {
+				res[objType] = append(res[objType], id)
+			}
+			return true
+		})
+	}
+	return res
+}
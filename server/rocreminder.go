@@ -0,0 +1,287 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/roc/persist"
+)
+
+const (
+	// 一次提醒触发失败后最多重试的次数，以及重试的间隔
+	rocReminderRetryNum      = 5
+	rocReminderRetryInterval = time.Second
+	// 没有即将触发的提醒时，调度协程最长的等待时间
+	rocReminderMaxWait = time.Minute
+	// 一次提醒触发的超时时间，超时后该次触发失败并被重试
+	rocReminderFireTimeout = 30 * time.Second
+)
+
+type reminderCtxKey struct{}
+
+// 判断调用是否由提醒调度发起
+func isReminderContext(ctx context.Context) bool {
+	reminder, _ := ctx.Value(reminderCtxKey{}).(bool)
+	return reminder
+}
+
+// 将提醒交给调用路径指向的本地ROC对象处理，调用参数为提醒的名称
+func (this *ROCServer) callROCReminder(path *roc.ROCPath,
+	arg []byte) ([]byte, error) {
+	return nil, this._ROCManager.RemindPath(path, string(arg))
+}
+
+// 一个ROC对象的提醒
+type rocReminder struct {
+	ObjType string        `json:"type"`
+	ObjID   string        `json:"id"`
+	Name    string        `json:"name"`
+	Due     time.Time     `json:"due"`
+	Period  time.Duration `json:"period"`
+
+	// 正在触发，以及本次触发失败的次数和下一次重试的时间
+	firing  bool
+	failed  int
+	retryAt time.Time
+}
+
+// 获取提醒下一次触发的时间
+func (this *rocReminder) nextTime() time.Time {
+	if this.failed > 0 {
+		return this.retryAt
+	}
+	return this.Due
+}
+
+// 提醒使用的键
+func reminderKey(objType roc.ROCObjType, objID string, name string) string {
+	return roc.O(objType, objID).F(name).String()
+}
+
+// 持久化存储中保存本模块提醒使用的键
+func (this *ROCServer) reminderStorageKey() string {
+	return this.server.moduleid + ".reminders"
+}
+
+// 注册一个ROC对象的提醒，dueTime 后第一次触发，之后每隔 period 触发一次，
+// period 不大于0时只触发一次，已存在的同名提醒将被替换。
+// 提醒触发时通过ROC调用交给目标对象的 OnROCReminder 处理，见 roc.Remindable ，
+// 调用总是发送到对象当前所在的模块，对象迁移后提醒仍然有效，调用失败时将被重试。
+// 提醒属于注册它的模块，设置了ROC对象的持久化存储时被保存，模块重启后继续生效，
+// 模块停止期间错过的提醒在恢复后立即触发一次，见 RestoreROCReminder
+func (this *ROCServer) RegisterReminder(objType roc.ROCObjType, objID string,
+	name string, dueTime time.Duration, period time.Duration) error {
+	if objID == "" || name == "" {
+		return fmt.Errorf("roc reminder needs obj id and name")
+	}
+	key := reminderKey(objType, objID, name)
+	this.reminderMutex.Lock()
+	if this.reminders == nil {
+		this.reminders = make(map[string]*rocReminder)
+	}
+	this.reminders[key] = &rocReminder{
+		ObjType: string(objType),
+		ObjID:   objID,
+		Name:    name,
+		Due:     time.Now().Add(dueTime),
+		Period:  period,
+	}
+	this.reminderMutex.Unlock()
+	this.Syslog("RegisterReminder %s due %s period %s", key, dueTime, period)
+	this.startReminder()
+	this.wakeReminder()
+	return this.saveReminders()
+}
+
+// 注销一个ROC对象的提醒，提醒不存在时不返回错误
+func (this *ROCServer) UnregisterReminder(objType roc.ROCObjType,
+	objID string, name string) error {
+	key := reminderKey(objType, objID, name)
+	this.reminderMutex.Lock()
+	_, ok := this.reminders[key]
+	delete(this.reminders, key)
+	this.reminderMutex.Unlock()
+	if !ok {
+		return nil
+	}
+	this.Syslog("UnregisterReminder %s", key)
+	return this.saveReminders()
+}
+
+// 从ROC对象的持久化存储中恢复本模块的提醒，恢复前已注册的同名提醒不会被覆盖，
+// 没有设置持久化存储时不做任何事
+func (this *ROCServer) RestoreROCReminder() error {
+	storage := this.GetROCStorage()
+	if storage == nil {
+		return nil
+	}
+	data, err := storage.Load(this.reminderStorageKey())
+	if errors.Is(err, persist.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	items := make([]*rocReminder, 0)
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	this.reminderMutex.Lock()
+	if this.reminders == nil {
+		this.reminders = make(map[string]*rocReminder)
+	}
+	for _, item := range items {
+		key := reminderKey(roc.ROCObjType(item.ObjType), item.ObjID,
+			item.Name)
+		if _, ok := this.reminders[key]; !ok {
+			this.reminders[key] = item
+		}
+	}
+	this.reminderMutex.Unlock()
+	this.Syslog("RestoreROCReminder restored %d reminder", len(items))
+	this.startReminder()
+	this.wakeReminder()
+	return nil
+}
+
+// 将本模块所有的提醒保存到ROC对象的持久化存储中，
+// 保存期间持有锁，避免较旧的数据覆盖较新的数据
+func (this *ROCServer) saveReminders() error {
+	storage := this.GetROCStorage()
+	if storage == nil {
+		return nil
+	}
+	this.reminderMutex.Lock()
+	defer this.reminderMutex.Unlock()
+	items := make([]*rocReminder, 0, len(this.reminders))
+	for _, r := range this.reminders {
+		items = append(items, r)
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	if err := storage.Save(this.reminderStorageKey(), data); err != nil {
+		this.Error("saveReminders err:%s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// 启动提醒的调度协程
+func (this *ROCServer) startReminder() {
+	this.reminderOnce.Do(func() {
+		this.reminderWake = make(chan struct{}, 1)
+		go this.reminderProcess()
+	})
+}
+
+// 提醒发生变化时唤醒调度协程
+func (this *ROCServer) wakeReminder() {
+	select {
+	case this.reminderWake <- struct{}{}:
+	default:
+	}
+}
+
+// 提醒的调度协程，触发所有到期的提醒，之后等待下一个提醒到期
+func (this *ROCServer) reminderProcess() {
+	for !this.server.isStop {
+		wait := this.fireDueReminders()
+		select {
+		case <-time.After(wait):
+		case <-this.reminderWake:
+		}
+	}
+}
+
+// 触发所有到期的提醒，返回距离下一个提醒到期的时间
+func (this *ROCServer) fireDueReminders() time.Duration {
+	now := time.Now()
+	wait := rocReminderMaxWait
+	this.reminderMutex.Lock()
+	defer this.reminderMutex.Unlock()
+	for key, r := range this.reminders {
+		if r.firing {
+			continue
+		}
+		next := r.nextTime()
+		if next.After(now) {
+			if d := next.Sub(now); d < wait {
+				wait = d
+			}
+			continue
+		}
+		r.firing = true
+		go this.fireReminder(key, r, r.Due)
+	}
+	return wait
+}
+
+// 以ROC调用的方式触发一个提醒，同一次触发的重试使用相同的幂等键，
+// 目标模块不会重复执行已经成功的触发。每次触发的时间不超过
+// rocReminderFireTimeout ，目标对象失去响应时该提醒仍会被重试
+func (this *ROCServer) fireReminder(key string, r *rocReminder,
+	due time.Time) {
+	ctx, cancel := this.newCallContext()
+	defer cancel()
+	ctx, fireCancel := context.WithTimeout(ctx, rocReminderFireTimeout)
+	defer fireCancel()
+	ctx = context.WithValue(ctx, reminderCtxKey{}, true)
+	ctx = roc.WithIdempotencyKey(ctx, fmt.Sprintf("reminder:%s:%d", key,
+		due.UnixNano()))
+	path := roc.O(roc.ROCObjType(r.ObjType), r.ObjID).F(roc.ReminderFuncName)
+	_, err := this.ROCCallContext(ctx, path, []byte(r.Name))
+	this.finishReminder(key, r, err)
+}
+
+// 提醒的触发失败后是否需要重试，目标对象已经执行并返回的错误不重试，
+// 已经执行的触发被重试时目标模块会按幂等键直接返回第一次执行的结果
+func needRetryReminder(err error) bool {
+	return roc.IsTransportError(err) || errors.Is(err, roc.ErrUnknowObj) ||
+		errors.Is(err, roc.ErrCallTimeout)
+}
+
+// 结束一次提醒的触发，失败时稍后重试，成功或不再重试时计算下一次触发的时间，
+// 只触发一次的提醒将被删除
+func (this *ROCServer) finishReminder(key string, r *rocReminder,
+	err error) {
+	now := time.Now()
+	this.reminderMutex.Lock()
+	// 触发期间提醒被注销或替换
+	if this.reminders[key] != r {
+		this.reminderMutex.Unlock()
+		return
+	}
+	r.firing = false
+	if err != nil && needRetryReminder(err) &&
+		r.failed+1 < rocReminderRetryNum {
+		r.failed++
+		r.retryAt = now.Add(rocReminderRetryInterval * time.Duration(r.failed))
+		this.reminderMutex.Unlock()
+		this.Warn("ROC reminder %s fire failed %d times, err:%s",
+			key, r.failed, err.Error())
+		this.wakeReminder()
+		return
+	}
+	if err != nil {
+		this.Error("ROC reminder %s fire failed, err:%s",
+			key, err.Error())
+	}
+	r.failed = 0
+	if r.Period <= 0 {
+		delete(this.reminders, key)
+	} else {
+		// 跳过错过的周期
+		for !r.Due.After(now) {
+			r.Due = r.Due.Add(r.Period)
+		}
+	}
+	this.reminderMutex.Unlock()
+	this.saveReminders()
+	this.wakeReminder()
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/liasece/micserver/roc"
+)
+
+// 接收提醒的测试对象
+type testRemindObj struct {
+	*testObj
+}
+
+func (this *testRemindObj) OnROCReminder(name string) error {
+	this.record("Remind " + name)
+	return nil
+}
+
+// 获取对象收到的提醒次数
+func (this *testRemindObj) remindCount() int {
+	n := 0
+	for _, call := range this.getCalls() {
+		if call == "Remind tick" {
+			n++
+		}
+	}
+	return n
+}
+
+func TestROCReminder(t *testing.T) {
+	a := newTestServer(t, "testreminder", nil)
+	b := newTestServer(t, "testreminder", nil)
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestReminderObj")
+	obj := &testRemindObj{newTestObj(objType, "1", 0)}
+	b.NewROC(objType).GetOrRegObj("1", obj)
+
+	// 提醒由注册的模块调度，按周期触发到对象所在的模块
+	if err := a.RegisterReminder(objType, "1", "tick", 10*time.Millisecond,
+		10*time.Millisecond); err != nil {
+		t.Fatalf("RegisterReminder err: %v", err)
+	}
+	waitTest(t, "reminder fired twice", func() bool {
+		return obj.remindCount() >= 2
+	})
+	// 以提醒的函数名发起的普通调用不会被当作提醒
+	if _, err := b.ROCCallBlock(roc.O(objType, "1").F(roc.ReminderFuncName),
+		[]byte("tick")); err == nil {
+		t.Fatalf("call %s err = nil, want unknown function",
+			roc.ReminderFuncName)
+	}
+	if calls := obj.getCalls(); calls[len(calls)-1] != "OnROCReminder tick" {
+		t.Fatalf("last call = %q, want OnROCCall", calls[len(calls)-1])
+	}
+
+	// 注销后不再触发，允许注销时正在进行的一次触发
+	if err := a.UnregisterReminder(objType, "1", "tick"); err != nil {
+		t.Fatalf("UnregisterReminder err: %v", err)
+	}
+	n := obj.remindCount()
+	time.Sleep(100 * time.Millisecond)
+	if got := obj.remindCount(); got > n+1 {
+		t.Fatalf("reminder fired %d times after unregister", got-n)
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/liasece/micserver/roc"
+)
+
+// 设置本模块 ROCCallBlock 、 ROCCallContext 等有返回值的调用的重试策略，
+// 为 nil 时不重试，默认不重试。启用重试时，调用的 context 中没有幂等键的调用将
+// 自动携带幂等键，目标模块对重复的请求直接返回第一次执行的结果，不会重复执行
+func (this *ROCServer) SetROCRetryPolicy(policy *roc.RetryPolicy) {
+	this.retryMutex.Lock()
+	defer this.retryMutex.Unlock()
+	this.retryPolicy = policy
+}
+
+func (this *ROCServer) getROCRetryPolicy() *roc.RetryPolicy {
+	this.retryMutex.Lock()
+	defer this.retryMutex.Unlock()
+	return this.retryPolicy
+}
+
+// 生成一个在所有模块中唯一的幂等键，目标模块按 调用方:幂等键 去重
+func (this *ROCServer) newIdempotencyKey() string {
+	return fmt.Sprintf("%d:%d", this.bindEpoch, this.newSeq())
+}
+
+// 按重试策略发起有返回值的调用，只有传输错误及单次尝试超时会被重试，
+// 所有尝试使用相同的幂等键
+func (this *ROCServer) rocCallRetry(ctx context.Context,
+	info *roc.CallInfo) ([]byte, error) {
+	policy := this.getROCRetryPolicy()
+	if policy == nil || policy.MaxAttempts <= 1 {
+		return this.rocCallContext(ctx, info)
+	}
+	if roc.IdempotencyKeyFromContext(ctx) == "" {
+		ctx = roc.WithIdempotencyKey(ctx, this.newIdempotencyKey())
+	}
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if policy.AttemptTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx,
+				policy.AttemptTimeout)
+		}
+		res, err := this.rocCallContext(attemptCtx, info)
+		// 单次尝试超时，但调用本身还未超时
+		attemptTimeout := attemptCtx.Err() != nil && ctx.Err() == nil
+		cancel()
+		if attempt >= policy.MaxAttempts ||
+			(!roc.IsTransportError(err) && !attemptTimeout) {
+			return res, err
+		}
+		backoff := policy.GetBackoff(attempt)
+		this.Warn("ROC call Path[%s] attempt %d failed, retry after %s, "+
+			"err:%s", info.Path.String(), attempt, backoff, err.Error())
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, roc.ContextErr(ctx)
+		}
+	}
+}
+
+// 与一个模块的连接全部断开时，等待该模块返回的调用以 roc.ErrConnLost 结束
+func (this *ROCServer) onCallModuleLeave(moduleid string) {
+	this.pendingCalls.Range(func(ki, vi interface{}) bool {
+		if vi.(string) != moduleid {
+			return true
+		}
+		this.pendingCalls.Delete(ki)
+		this.rocResponseChan <- &responseAgent{
+			fromModuleID: moduleid,
+			seq:          ki.(int64),
+			err: fmt.Errorf("%w: module %s left", roc.ErrConnLost,
+				moduleid),
+		}
+		return true
+	})
+}
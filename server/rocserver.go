@@ -4,8 +4,10 @@ micserver中的ROC调用发生时，处理调用以及返回值。
 package server
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
 	"sync"
 	"time"
 
@@ -15,7 +17,9 @@ import (
 	"github.com/liasece/micserver/msg"
 	"github.com/liasece/micserver/process"
 	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/roc/persist"
 	"github.com/liasece/micserver/servercomm"
+	"github.com/liasece/micserver/trace"
 	"github.com/liasece/micserver/util/hash"
 )
 
@@ -26,6 +30,25 @@ type requestAgent struct {
 	callarg      []byte
 	seq          int64
 	needReturn   bool
+	// 调用的目标对象，决定请求投递到哪个邮箱
+	objType roc.ROCObjType
+	objID   string
+	// 同一进程中发起的调用，直接将返回值交给调用方的ROC服务
+	caller *ROCServer
+	// 收到请求的时间
+	recvTime time.Time
+	// 调用方的调用链追踪信息
+	spanCtx trace.SpanContext
+	// 调用方所知的目标对象的围栏令牌
+	token uint64
+	// 流式调用时调用方的接收窗口，为0时不是流式调用
+	streamWindow int32
+	// 调用的幂等键，不为空时按该键去重
+	idemKey string
+	// 是否为提醒调度发起的提醒触发
+	reminder bool
+	// ROC服务内部的操作，在目标对象的邮箱中执行，以保证与该对象的ROC调用之间的顺序
+	fn func()
 }
 
 // ROC响应信息
@@ -33,7 +56,7 @@ type responseAgent struct {
 	fromModuleID string
 	seq          int64
 	data         []byte
-	err          string
+	err          error
 }
 
 // ROC服务
@@ -42,21 +65,104 @@ type ROCServer struct {
 	server *Server
 
 	// 记录在本地的缓存信息
-	// 第一层键为ROCObj类型，第二层键为ROCObj的ID
-	localObj      map[string]map[string]struct{}
+	// 第一层键为ROCObj类型，第二层键为ROCObj的ID，值为该对象注册的围栏令牌
+	localObj      map[string]map[string]uint64
 	localObjMutex sync.Mutex
+	// 迁入本模块的对象注册时至少使用的围栏令牌，键为 roc.O(objType, objID).String()
+	objTokenHint map[string]uint64
+
+	// ROC对象的持久化存储，以及是否正在等待从存储中恢复ROC对象，
+	// 恢复完成前不向其他模块发送ROC对象绑定信息
+	persistStorage persist.Storage
+	restoringObj   bool
 
 	// 远程对象调用支持
 	_ROCManager     roc.ROCManager
 	rocAddCacheChan chan roc.IObj
 	rocDelCacheChan chan roc.IObj
 
-	rocRequestChan  chan *requestAgent
+	rocDispatcher   rocDispatcher
 	rocResponseChan chan *responseAgent
 	rocBlockChanMap sync.Map
+	// 等待其他模块返回的调用，键为请求的序号，值为目标模块，
+	// 与目标模块的连接断开时这些调用以 roc.ErrConnLost 结束
+	pendingCalls sync.Map
+
+	// 有返回值的调用的重试策略
+	retryPolicy *roc.RetryPolicy
+	retryMutex  sync.Mutex
+	// 按幂等键去重的ROC请求的执行结果
+	rocDedup rocDedupCache
 
 	seqMutex sync.Mutex
 	lastSeq  int64
+
+	// 本模块ROC绑定信息的纪元及版本，每次向其他模块发送本模块的绑定变化时版本递增
+	bindEpoch   uint64
+	bindVersion uint64
+	bindMutex   sync.Mutex
+	// 正在与之同步ROC绑定信息的模块，以及同步期间收到的该模块上的对象
+	bindSyncing      map[string]map[roc.ROCObjType]map[string]struct{}
+	bindSyncingMutex sync.Mutex
+
+	// 各ROC调用路径的指标，键为 调用方或被调用方:类型.函数名
+	pathMetrics sync.Map
+	// 本模块作为被调用方执行过的函数，键为 类型.函数名
+	knownFuncs sync.Map
+
+	// 本模块发起的流式调用，键为请求的序号
+	streams sync.Map
+	// 本模块处理中的流式调用的写入器，键为 调用方:请求的序号
+	streamWriters sync.Map
+
+	// ROC调用拦截器
+	serverInterceptors []roc.ServerInterceptor
+	clientInterceptors []roc.ClientInterceptor
+	interceptorMutex   sync.RWMutex
+
+	// 进行中的ROC对象位置查询，键为 roc.O(objType, objID).String()
+	locating    map[string]*rocLocating
+	locateMutex sync.Mutex
+
+	// 正在迁出的ROC对象在迁移期间收到的调用请求，以及已迁出的ROC对象所在的模块，
+	// 键为 roc.O(objType, objID).String()
+	migratingObj map[string][]*requestAgent
+	migratedObj  map[string]*rocMigrated
+	migrateMutex sync.Mutex
+
+	// 可以按需激活的类型的ROC对象最后一次处理调用的时间，用于停用空闲的对象，
+	// 键为 roc.O(objType, objID).String()
+	activeTime  map[string]time.Time
+	activeMutex sync.Mutex
+
+	// 各模块类型的一致性哈希环，节点为该类型在线的模块，以及按一致性哈希放置的
+	// ROC对象类型所在的模块类型
+	placementRings     map[string]*hash.Ring
+	placementTypes     map[roc.ROCObjType]string
+	placementListeners []PlacementListener
+	placementMutex     sync.Mutex
+
+	// 本模块注册的ROC对象提醒，键为 roc.O(objType, objID).F(name).String()
+	reminders     map[string]*rocReminder
+	reminderMutex sync.Mutex
+	reminderWake  chan struct{}
+	reminderOnce  sync.Once
+
+	// 进行中的集群ROC对象统计查询，键为查询的序号
+	statsQueries sync.Map
+	// ROC管理接口的HTTP服务
+	adminListener net.Listener
+	adminMutex    sync.Mutex
+
+	// 接收中的分片传输，键为 发送方:传输序号
+	chunkBuffers map[string]*rocChunkBuffer
+	chunkMutex   sync.Mutex
+
+	// 向其他模块发送ROC消息的排序状态，以及接收其他模块ROC消息的排序状态，
+	// 键为对方的模块ID
+	orderSenders   sync.Map
+	orderReceivers map[string]*rocOrderReceiver
+	orderMutex     sync.Mutex
 }
 
 // 初始化ROC服务
@@ -65,14 +171,14 @@ func (this *ROCServer) Init(server *Server) {
 	this.Logger = server.Logger.Clone()
 	this.Logger.SetTopic("ROCServer")
 
+	this.bindEpoch = uint64(time.Now().UnixNano())
 	this.rocAddCacheChan = make(chan roc.IObj, 10000)
 	this.rocDelCacheChan = make(chan roc.IObj, 10000)
 	go this.rocObjNoticeProcess(this.rocAddCacheChan, false)
 	go this.rocObjNoticeProcess(this.rocDelCacheChan, true)
 	this._ROCManager.HookObjEvent(this)
 
-	this.rocRequestChan = make(chan *requestAgent, 10000)
-	go this.rocRequestProcess()
+	this.rocDispatcher.Init(this)
 	this.rocResponseChan = make(chan *responseAgent, 10000)
 	go this.rocResponseProcess()
 }
@@ -96,36 +202,82 @@ func (this *ROCServer) NewROC(objtype roc.ROCObjType) *roc.ROC {
 	return this._ROCManager.NewROC(objtype)
 }
 
-// 无返回值的ROC调用
+// 无返回值的ROC调用，缓存中没有目标对象的位置时，会先阻塞查询目标对象的位置，
+// 查询的超时时间见 conf.ROCLocateTimeout ，不会超过 ROCCallBlock 的超时时间
 func (this *ROCServer) ROCCallNR(callpath *roc.ROCPath, callarg []byte) error {
+	ctx, cancel := this.newCallContext()
+	defer cancel()
+	return this.ROCCallNRContext(ctx, callpath, callarg)
+}
+
+// 同 ROCCallNR ，查询目标对象的位置时在 ctx 超时或被取消后返回对应的错误，
+// ctx 中的调用链追踪信息及幂等键将随调用传递
+func (this *ROCServer) ROCCallNRContext(ctx context.Context,
+	callpath *roc.ROCPath, callarg []byte) error {
+	_, err := this.invokeROCCall(ctx, &roc.CallInfo{
+		Path: callpath,
+		Arg:  callarg,
+	}, this.rocCallNR)
+	return err
+}
+
+func (this *ROCServer) rocCallNR(ctx context.Context,
+	info *roc.CallInfo) ([]byte, error) {
+	callpath := info.Path
+	callarg := info.Arg
+	if err := this.checkPayloadSize(int64(len(callarg))); err != nil {
+		return nil, err
+	}
 	objType := callpath.GetObjType()
 	objID := callpath.GetObjID()
-	moduleid := roc.GetCache().Get(objType, objID)
+	// 调用方不等待调用结果，查询位置的时间不能没有限制
+	locateCtx, cancel := context.WithTimeout(ctx, this.getLocateTimeout())
+	defer cancel()
+	moduleid, token, err := this.locateROCObj(locateCtx, objType, objID)
+	if err != nil {
+		this.Warn("Can't find roc object location %s err:%s",
+			callpath.String(), err.Error())
+		return nil, fmt.Errorf("Can't find roc object location %s: %w",
+			callpath.String(), err)
+	}
 	this.Syslog("ROCCallNR {%s:%s(%s:%s):%X}",
 		moduleid, callpath, objType, objID, callarg)
-	// 构造消息
-	sendmsg := &servercomm.SROCRequest{
-		FromModuleID: this.server.moduleid,
-		Seq:          this.newSeq(),
-		CallStr:      callpath.String(),
-		CallArg:      callarg,
-	}
-	if moduleid == this.server.moduleid {
-		sendmsg.ToModuleID = moduleid
-		this.onMsgROCRequest(sendmsg)
+	// 目标在本进程中，直接交给目标模块处理
+	if local := this.getLocalROCServer(moduleid); local != nil {
+		local.pushROCRequest(&requestAgent{
+			fromModuleID: this.server.moduleid,
+			callpath:     callpath.String(),
+			callarg:      callarg,
+			seq:          this.newSeq(),
+			spanCtx:      trace.SpanContextFromContext(ctx),
+			token:        token,
+			idemKey:      roc.IdempotencyKeyFromContext(ctx),
+		})
 	} else {
+		// 构造消息
+		sendmsg := &servercomm.SROCRequest{
+			FromModuleID: this.server.moduleid,
+			Seq:          this.newSeq(),
+			CallStr:      callpath.String(),
+			CallArg:      callarg,
+			Token:        token,
+			IdemKey:      roc.IdempotencyKeyFromContext(ctx),
+		}
+		sc := trace.SpanContextFromContext(ctx)
+		sendmsg.TraceID = sc.TraceID
+		sendmsg.SpanID = sc.SpanID
 		server := this.server.subnetManager.GetServer(moduleid)
 		if server != nil {
 			sendmsg.ToModuleID = moduleid
-			server.SendCmd(sendmsg)
+			this.sendROCMsg(server, sendmsg)
 		} else {
 			this.Warn("Can't find roc object location %s",
 				callpath.String())
-			return fmt.Errorf("Can't find roc object location %s",
+			return nil, fmt.Errorf("Can't find roc object location %s",
 				callpath.String())
 		}
 	}
-	return nil
+	return nil, nil
 }
 
 // 获取ROC缓存中的位置信息
@@ -168,42 +320,155 @@ func (this *ROCServer) addBlockChan(seq int64) chan *responseAgent {
 	return ch
 }
 
-// 有返回值的RPC调用
+// 有返回值的RPC调用，如果模块配置了 conf.ROCCallTimeout ，超时后将返回
+// roc.ErrCallTimeout 。在ROC对象的调用处理中阻塞调用自身，或者形成循环的阻塞调用
+// 时，由于每个对象的调用按顺序执行，这些调用只能在超时后结束，因此在ROC对象中
+// 发起阻塞调用的模块需要配置 conf.ROCCallTimeout
 func (this *ROCServer) ROCCallBlock(callpath *roc.ROCPath,
 	callarg []byte) ([]byte, error) {
+	ctx, cancel := this.newCallContext()
+	defer cancel()
+	return this.ROCCallContext(ctx, callpath, callarg)
+}
+
+// 构造ROC调用使用的 context ，如果模块配置了 conf.ROCCallTimeout ，将在超时后结束
+func (this *ROCServer) newCallContext() (context.Context,
+	context.CancelFunc) {
+	timeout := this.server.moduleConfig.GetInt64(conf.ROCCallTimeout)
+	if timeout > 0 {
+		return context.WithTimeout(context.Background(),
+			time.Duration(timeout)*time.Millisecond)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// 有返回值的RPC调用，在 ctx 超时或被取消时不再等待返回值，分别返回
+// roc.ErrCallTimeout 或 roc.ErrCallCanceled ，之后到达的返回值将被丢弃。
+// 缓存中没有目标对象的位置时，先查询目标对象的位置，目标对象不存在时返回
+// roc.ErrUnknowObj 。与目标模块的连接断开时返回 roc.ErrConnLost ，
+// 设置了重试策略时将重试，见 SetROCRetryPolicy 。
+// 在ROC对象的调用处理中以 path.Context() 发起调用时，等待本进程中的调用期间
+// 该调用处理不占用ROC调用处理协程的数量，见 conf.ROCWorkerNum
+func (this *ROCServer) ROCCallContext(ctx context.Context,
+	callpath *roc.ROCPath, callarg []byte) ([]byte, error) {
+	return this.invokeROCCall(ctx, &roc.CallInfo{
+		Path:       callpath,
+		Arg:        callarg,
+		NeedReturn: true,
+	}, this.rocCallRetry)
+}
+
+// 异步调用目标ROC对象，立即返回调用结果的 Future ，超时时间与 ROCCallBlock 相同，
+// 结果可以通过 Future.OnComplete 投递到指定的执行器中处理，例如模块的定时器协程
+func (this *ROCServer) ROCCallAsync(callpath *roc.ROCPath,
+	callarg []byte) *roc.Future {
+	ctx, cancel := this.newCallContext()
+	future := this.ROCCallAsyncContext(ctx, callpath, callarg)
+	future.OnComplete(nil, func([]byte, error) {
+		cancel()
+	})
+	return future
+}
+
+// 同 ROCCallAsync ，在 ctx 超时或被取消时以对应的错误完成
+func (this *ROCServer) ROCCallAsyncContext(ctx context.Context,
+	callpath *roc.ROCPath, callarg []byte) *roc.Future {
+	future := roc.NewFuture()
+	go func() {
+		future.Complete(this.ROCCallContext(ctx, callpath, callarg))
+	}()
+	return future
+}
+
+func (this *ROCServer) rocCallContext(ctx context.Context,
+	info *roc.CallInfo) ([]byte, error) {
+	callpath := info.Path
+	callarg := info.Arg
+	if err := this.checkPayloadSize(int64(len(callarg))); err != nil {
+		return nil, err
+	}
 	objType := callpath.GetObjType()
 	objID := callpath.GetObjID()
-	moduleid := roc.GetCache().Get(objType, objID)
+	moduleid, token, err := this.locateROCObj(ctx, objType, objID)
+	if err != nil {
+		this.Warn("ROCCallBlock can't find roc object location %s err:%s",
+			callpath.String(), err.Error())
+		return nil, err
+	}
 	this.Syslog("ROCCallBlock {%s:%s(%s:%s:%d):%X}",
 		moduleid, callpath, objType, objID, hash.GetStringHash(string(objID)),
 		callarg)
-	// 构造消息
-	sendmsg := &servercomm.SROCRequest{
-		FromModuleID: this.server.moduleid,
-		Seq:          this.newSeq(),
-		CallStr:      callpath.String(),
-		CallArg:      callarg,
-		NeedReturn:   true,
-	}
-
-	ch := this.addBlockChan(sendmsg.Seq)
-
-	if moduleid == this.server.moduleid {
-		sendmsg.ToModuleID = moduleid
-		this.onMsgROCRequest(sendmsg)
+	seq := this.newSeq()
+	ch := this.addBlockChan(seq)
+	idemKey := roc.IdempotencyKeyFromContext(ctx)
+
+	// 目标在本进程中，直接交给目标模块处理，不需要构造消息
+	if local := this.getLocalROCServer(moduleid); local != nil {
+		// 调用方是工作协程时，等待期间不占用工作协程的数量
+		if worker := rocWorkerFromContext(ctx); worker != nil {
+			worker.blockBegin()
+			defer worker.blockEnd()
+		}
+		local.pushROCRequest(&requestAgent{
+			fromModuleID: this.server.moduleid,
+			callpath:     callpath.String(),
+			callarg:      callarg,
+			seq:          seq,
+			needReturn:   true,
+			caller:       this,
+			spanCtx:      trace.SpanContextFromContext(ctx),
+			token:        token,
+			idemKey:      idemKey,
+			reminder:     isReminderContext(ctx),
+		})
 	} else {
+		// 构造消息
+		sendmsg := &servercomm.SROCRequest{
+			FromModuleID: this.server.moduleid,
+			Seq:          seq,
+			CallStr:      callpath.String(),
+			CallArg:      callarg,
+			NeedReturn:   true,
+			Token:        token,
+			IdemKey:      idemKey,
+			Reminder:     isReminderContext(ctx),
+		}
+		sc := trace.SpanContextFromContext(ctx)
+		sendmsg.TraceID = sc.TraceID
+		sendmsg.SpanID = sc.SpanID
 		server := this.server.subnetManager.GetServer(moduleid)
-		if server != nil {
-			sendmsg.ToModuleID = moduleid
-			server.SendCmd(sendmsg)
-		} else {
-			this.server.subnetManager.BroadcastCmd(sendmsg)
+		if server == nil {
+			this.rocBlockChanMap.Delete(seq)
+			this.Warn("ROCCallBlock target module does not exist "+
+				"ModuleID[%s] Path[%s]", moduleid, callpath.String())
+			return nil, roc.ErrConnLost
 		}
+		this.pendingCalls.Store(seq, moduleid)
+		defer this.pendingCalls.Delete(seq)
+		sendmsg.ToModuleID = moduleid
+		this.sendROCMsg(server, sendmsg)
 	}
 
 	// 等待返回值
-	agent := <-ch
-	return agent.data, errors.New(agent.err)
+	select {
+	case agent := <-ch:
+		// 目标模块的注册已失效或对象已被删除，删除缓存使之后的调用重新查询
+		// 对象的位置
+		if errors.Is(agent.err, roc.ErrStaleOwner) ||
+			errors.Is(agent.err, roc.ErrUnknowObj) {
+			roc.GetCache().Del(objType, objID, moduleid)
+		}
+		return agent.data, agent.err
+	case <-ctx.Done():
+		// 不再等待返回值，之后到达的返回值会因为找不到目标请求而被丢弃
+		this.rocBlockChanMap.Delete(seq)
+		err := roc.ContextErr(ctx)
+		if errors.Is(err, roc.ErrCallTimeout) {
+			this.Warn("ROCCallBlock timeout Seq[%d] Path[%s]",
+				seq, callpath.String())
+		}
+		return nil, err
+	}
 }
 
 // 当收到ROC调用请求时
@@ -214,8 +479,68 @@ func (this *ROCServer) onMsgROCRequest(msg *servercomm.SROCRequest) {
 		seq:          msg.Seq,
 		needReturn:   msg.NeedReturn,
 		fromModuleID: msg.FromModuleID,
+		spanCtx: trace.SpanContext{
+			TraceID: msg.TraceID,
+			SpanID:  msg.SpanID,
+		},
+		token:        msg.Token,
+		streamWindow: msg.StreamWindow,
+		idemKey:      msg.IdemKey,
+		reminder:     msg.Reminder,
+	}
+	this.pushROCRequest(agent)
+}
+
+// 将ROC请求投递到目标对象的邮箱，本地及远程的请求共用同一个邮箱以保证调用顺序
+func (this *ROCServer) pushROCRequest(agent *requestAgent) {
+	if agent.recvTime.IsZero() {
+		agent.recvTime = time.Now()
+	}
+	if agent.fn == nil {
+		path, err := roc.ParseROCPath(agent.callpath)
+		if err != nil {
+			this.Warn("ROC Request[%s] from %s err:%s",
+				agent.callpath, agent.fromModuleID, err.Error())
+			if agent.needReturn {
+				this.sendROCResponse(agent, nil, err)
+			}
+			return
+		}
+		agent.objType = path.GetObjType()
+		agent.objID = path.GetObjID()
 	}
-	this.rocRequestChan <- agent
+	this.rocDispatcher.push(agent)
+}
+
+// 设置一个ROC类型同时执行调用的对象数量，不大于0时不限制，默认不限制。
+// 同一个ROC对象的调用总是按顺序执行的，设置为1时该类型所有对象的调用都将按顺序执行
+func (this *ROCServer) SetROCTypeConcurrency(objType roc.ROCObjType, n int) {
+	this.rocDispatcher.setConcurrency(objType, n)
+}
+
+// 获取与本模块处于同一进程中的目标模块的ROC服务，目标模块不在本进程中时返回 nil
+func (this *ROCServer) getLocalROCServer(moduleid string) *ROCServer {
+	if moduleid == "" {
+		return nil
+	}
+	if moduleid == this.server.moduleid {
+		return this
+	}
+	if m := process.GetModule(moduleid); m != nil {
+		if getter, ok := m.(rocServerGetter); ok {
+			return getter.getROCServer()
+		}
+	}
+	return nil
+}
+
+// 获取模块的ROC服务，继承了 Server 的模块均实现了该接口
+type rocServerGetter interface {
+	getROCServer() *ROCServer
+}
+
+func (this *ROCServer) getROCServer() *ROCServer {
+	return this
 }
 
 // 当收到ROC调用返回时
@@ -224,57 +549,112 @@ func (this *ROCServer) onMsgROCResponse(msg *servercomm.SROCResponse) {
 		fromModuleID: msg.FromModuleID,
 		seq:          msg.ReqSeq,
 		data:         msg.ResData,
-		err:          msg.Error,
+		err: roc.CodeToError(roc.ErrCode(msg.ErrCode), msg.Error,
+			msg.ErrDetails),
 	}
 	this.rocResponseChan <- agent
 }
 
-// 处理带返回值的ROC调用返回的线程
-func (this *ROCServer) rocRequestProcess() {
-	tm := time.NewTimer(time.Millisecond * 300)
-	for !this.server.isStop {
-		select {
-		case <-this.server.stopChan:
-			break
-		case agent := <-this.rocRequestChan:
-			// 处理ROC请求
-			this.Syslog("ROC Request[%s]", agent.callpath)
-			res, err := this._ROCManager.Call(agent.callpath, agent.callarg)
-			if err != nil {
-				if err != roc.ErrUnknowObj {
-					this.Error("ROCManager.Call err:%s", err.Error())
-				} else {
-					this.Syslog("ROCManager.Call Path[%s] Err[%s]",
-						agent.callpath, err.Error())
-				}
-			} else {
-				// this.Debug("ROC调用成功 res:%+v", res)
-			}
-			if agent.needReturn {
-				if agent.fromModuleID == this.server.moduleid {
-					// 本地服务器调用结果
+// 处理一个ROC请求
+func (this *ROCServer) handleROCRequest(agent *requestAgent) {
+	if agent.fn != nil {
+		agent.fn()
+		return
+	}
+	// 目标对象正在迁出或已迁出
+	if this.holdMigratingRequest(agent) {
+		return
+	}
+	this.touchIdleObj(agent.objType, agent.objID)
+	// 目标对象已被其他模块以更新的注册取得
+	if this.isStaleOwner(agent.objType, agent.objID, agent.token) {
+		this.Warn("ROC Request[%s] refused by stale owner, Token[%d]",
+			agent.callpath, agent.token)
+		if agent.needReturn {
+			this.sendROCResponse(agent, nil, roc.ErrStaleOwner)
+		}
+		return
+	}
+	// 重复的请求直接返回第一次执行的结果
+	if this.replyDedupROCRequest(agent) {
+		return
+	}
+	// 流式调用在独立的协程中执行
+	if agent.streamWindow > 0 {
+		this.Syslog("ROC Stream Request[%s]", agent.callpath)
+		this.runROCStream(agent)
+		return
+	}
+	// 处理ROC请求，提醒交给对象的 OnROCReminder 处理
+	this.Syslog("ROC Request[%s]", agent.callpath)
+	call := this._ROCManager.CallPath
+	if agent.reminder {
+		call = this.callROCReminder
+	}
+	res, err := this.callROCObj(agent, call)
+	if err != nil {
+		if !errors.Is(err, roc.ErrUnknowObj) {
+			this.Error("ROCManager.Call err:%s", err.Error())
+		} else {
+			this.Syslog("ROCManager.Call Path[%s] Err[%s]",
+				agent.callpath, err.Error())
+		}
+	} else {
+		// this.Debug("ROC调用成功 res:%+v", res)
+	}
+	this.saveDedupROCResult(agent, res, err)
+	if agent.needReturn {
+		this.sendROCResponse(agent, res, err)
+	}
+}
 
-				} else {
-					server := this.server.subnetManager.GetServer(
-						agent.fromModuleID)
-					if server != nil {
-						// 返回执行结果
-						sendmsg := &servercomm.SROCResponse{
-							FromModuleID: this.server.moduleid,
-							ToModuleID:   agent.fromModuleID,
-							ReqSeq:       agent.seq,
-							ResData:      res,
-							Error:        err.Error(),
-						}
-						server.SendCmd(sendmsg)
-					}
-				}
-			}
-		case <-tm.C:
-			tm.Reset(time.Millisecond * 300)
-			break
+// 返回ROC调用的执行结果
+func (this *ROCServer) sendROCResponse(agent *requestAgent, res []byte,
+	err error) {
+	// 返回值超过大小上限时调用失败
+	if sizeErr := this.checkPayloadSize(int64(len(res))); sizeErr != nil {
+		this.Warn("ROC Request[%s] from %s result dropped, err:%s",
+			agent.callpath, agent.fromModuleID, sizeErr.Error())
+		res, err = nil, sizeErr
+	}
+	code, errMsg, details := roc.ErrorToCode(err)
+	// 流式调用以结束帧返回
+	if agent.streamWindow > 0 {
+		this.sendROCStreamFrame(agent, &servercomm.SROCStreamFrame{
+			End:        true,
+			Error:      errMsg,
+			ErrCode:    int32(code),
+			ErrDetails: details,
+		})
+		return
+	}
+	if agent.caller != nil {
+		// 本进程中的调用，错误同样经过错误码转换，与远程调用的语义保持一致
+		agent.caller.rocResponseChan <- &responseAgent{
+			fromModuleID: this.server.moduleid,
+			seq:          agent.seq,
+			data:         res,
+			err:          roc.CodeToError(code, errMsg, details),
 		}
+		return
+	}
+	server := this.server.subnetManager.GetServer(agent.fromModuleID)
+	if server == nil {
+		this.Warn("ROC response target module does not exist "+
+			"ModuleID[%s] Seq[%d]", agent.fromModuleID, agent.seq)
+		return
+	}
+	// 返回执行结果
+	sendmsg := &servercomm.SROCResponse{
+		FromModuleID: this.server.moduleid,
+		ToModuleID:   agent.fromModuleID,
+		ReqSeq:       agent.seq,
+		ResData:      res,
+		Error:        errMsg,
+		ErrCode:      int32(code),
+		ErrDetails:   details,
 	}
+	this.sendROCMsg(server, sendmsg)
 }
 
 // 处理ROC带返回值电泳调用的线程
@@ -287,7 +667,7 @@ func (this *ROCServer) rocResponseProcess() {
 		case agent := <-this.rocResponseChan:
 			// 处理ROC相应
 			this.Syslog("rocResponseProcess %+v", agent)
-			chi, ok := this.rocBlockChanMap.Load(agent.seq)
+			chi, ok := this.rocBlockChanMap.LoadAndDelete(agent.seq)
 			if ok {
 				if ch, ok := chi.(chan *responseAgent); ok {
 					// 写入返回值
@@ -296,7 +676,8 @@ func (this *ROCServer) rocResponseProcess() {
 					this.Error("ROC返回 chi.(chan *responseAgent) 错误")
 				}
 			} else {
-				this.Error("ROC返回 不存在目标ROC请求 %+v", agent)
+				// 请求方已超时或取消等待，丢弃迟到的返回值
+				this.Syslog("ROC返回 不存在目标ROC请求，已丢弃 %+v", agent)
 			}
 		case <-tm.C:
 			tm.Reset(time.Millisecond * 300)
@@ -307,12 +688,23 @@ func (this *ROCServer) rocResponseProcess() {
 
 // 当一个服务器加入了子网时
 func (this *ROCServer) onServerJoinSubnet(server *connect.Server) {
+	this.onPlacementModuleChange(server.ModuleInfo.ModuleID, false)
 	if process.HasModule(server.ModuleInfo.ModuleID) {
 		return
 	}
 	this.localObjMutex.Lock()
 	defer this.localObjMutex.Unlock()
 
+	// 恢复完成后会统一发送
+	if this.restoringObj {
+		return
+	}
+	// 只发送绑定信息的版本，由对方决定是否需要同步
+	this.sendROCBindVersion(server)
+}
+
+// 向目标服务器发送本地所有的ROC对象绑定信息，调用方需要持有 localObjMutex
+func (this *ROCServer) sendLocalObjBind(server *connect.Server) {
 	if this.localObj == nil {
 		return
 	}
@@ -324,12 +716,14 @@ func (this *ROCServer) onServerJoinSubnet(server *connect.Server) {
 		leftnum := len(typemap)
 		// 临时变量
 		tmplist := make([]string, 0)
+		tmptokens := make([]uint64, 0)
 		tmpsize := 0
 		// 遍历所有对象
-		for objid, _ := range typemap {
+		for objid, token := range typemap {
 			leftnum--
 			tmplist = append(tmplist, objid)
-			tmpsize += len(objid) + 4
+			tmptokens = append(tmptokens, token)
+			tmpsize += len(objid) + 12
 			// 分包发送
 			if leftnum == 0 ||
 				(tmpsize > (msg.MessageMaxSize/2) && tmpsize > 32*1024) {
@@ -338,10 +732,12 @@ func (this *ROCServer) onServerJoinSubnet(server *connect.Server) {
 					IsDelete:     false,
 					ObjType:      objtype,
 					ObjIDs:       tmplist,
+					Tokens:       tmptokens,
 				}
 				server.SendCmd(sendmsg)
 				if leftnum > 0 {
 					tmplist = make([]string, 0)
+					tmptokens = make([]uint64, 0)
 					tmpsize = 0
 				}
 			}
@@ -351,16 +747,21 @@ func (this *ROCServer) onServerJoinSubnet(server *connect.Server) {
 
 // 记录本地的ROC对象
 func (this *ROCServer) recordLocalObj(objtype string, objid string,
-	isDelete bool) {
+	token uint64, isDelete bool) {
 	this.localObjMutex.Lock()
 	defer this.localObjMutex.Unlock()
+	this.recordLocalObjNoLock(objtype, objid, token, isDelete)
+}
 
+// 记录本地的ROC对象，调用方需要持有 localObjMutex
+func (this *ROCServer) recordLocalObjNoLock(objtype string, objid string,
+	token uint64, isDelete bool) {
 	// 初始化内存
 	if this.localObj == nil {
-		this.localObj = make(map[string]map[string]struct{})
+		this.localObj = make(map[string]map[string]uint64)
 	}
 	if v, ok := this.localObj[objtype]; !ok || v == nil {
-		this.localObj[objtype] = make(map[string]struct{})
+		this.localObj[objtype] = make(map[string]uint64)
 	}
 
 	// 记录
@@ -370,18 +771,34 @@ func (this *ROCServer) recordLocalObj(objtype string, objid string,
 			delete(this.localObj[objtype], objid)
 		}
 	} else {
-		this.localObj[objtype][objid] = struct{}{}
+		this.localObj[objtype][objid] = token
 	}
 }
 
 // 当ROC对象发生注册行为时
 func (this *ROCServer) OnROCObjAdd(obj roc.IObj) {
-	// 保存本地映射缓存
-	roc.GetCache().Set(obj.GetROCObjType(), obj.GetROCObjID(),
-		this.server.moduleid)
+	// 迁出的对象重新注册到了本模块
+	this.migrateMutex.Lock()
+	delete(this.migratedObj,
+		migrateKey(obj.GetROCObjType(), obj.GetROCObjID()))
+	this.migrateMutex.Unlock()
+
+	// 取得围栏令牌并保存本地映射缓存，迁入的对象至少使用迁出模块指定的令牌。
+	// 先记录本地对象，保证之后发送的全量同步中包含该对象
+	this.localObjMutex.Lock()
+	token := this.acquireObjTokenNoLock(obj.GetROCObjType(),
+		obj.GetROCObjID(), this.objTokenHint[migrateKey(obj.GetROCObjType(),
+			obj.GetROCObjID())])
+	restoring := this.restoringObj
+	this.localObjMutex.Unlock()
+	this.trackIdleObj(obj.GetROCObjType(), obj.GetROCObjID(), false)
 	this.Syslog("OnROCObjAdd roc cache set type[%s] "+
-		"id[%s] host[%s]",
-		obj.GetROCObjType(), obj.GetROCObjID(), this.server.moduleid)
+		"id[%s] host[%s] token[%d]",
+		obj.GetROCObjType(), obj.GetROCObjID(), this.server.moduleid, token)
+	// 恢复完成后会统一发送绑定信息
+	if restoring {
+		return
+	}
 
 	// 由于ROC绑定消息与ROC调用之间存在异步问题，除非经过设置，
 	// 否则使用同步方式同步ROC对象绑定
@@ -393,15 +810,24 @@ func (this *ROCServer) OnROCObjAdd(obj roc.IObj) {
 			IsDelete:     false,
 			ObjType:      string(obj.GetROCObjType()),
 			ObjIDs:       []string{obj.GetROCObjID()},
+			Tokens:       []uint64{token},
 		}
 		this.sendROCBindMsg(sendmsg)
 	}
-	this.recordLocalObj(string(obj.GetROCObjType()), obj.GetROCObjID(),
-		false)
 }
 
 // 当ROC对象发生注册行为时
 func (this *ROCServer) OnROCObjDel(obj roc.IObj) {
+	// 先删除本地对象记录，保证之后发送的全量同步中不包含该对象
+	token := this.getLocalObjToken(obj.GetROCObjType(), obj.GetROCObjID())
+	this.recordLocalObj(string(obj.GetROCObjType()), obj.GetROCObjID(), 0,
+		true)
+	this.trackIdleObj(obj.GetROCObjType(), obj.GetROCObjID(), true)
+	// 迁出的对象，直接将缓存指向新的位置，不需要经过删除，
+	// 迁入的模块使用的令牌比本模块的大1
+	if this.onMigratedObjDel(obj, token+1) {
+		return
+	}
 	// 保存本地映射缓存
 	roc.GetCache().Del(obj.GetROCObjType(), obj.GetROCObjID(),
 		this.server.moduleid)
@@ -422,8 +848,6 @@ func (this *ROCServer) OnROCObjDel(obj roc.IObj) {
 		}
 		this.sendROCBindMsg(sendmsg)
 	}
-	this.recordLocalObj(string(obj.GetROCObjType()), obj.GetROCObjID(),
-		true)
 }
 
 // 向其他模块通知ROC注册信息的线程
@@ -472,11 +896,18 @@ func (this *ROCServer) rocObjNoticeProcess(rocCacheChan chan roc.IObj,
 					ObjType:      string(tmpList[0].GetROCObjType()),
 					ObjIDs:       make([]string, tmpListI),
 				}
+				if !isDelete {
+					sendmsg.Tokens = make([]uint64, tmpListI)
+				}
 				for i, obj := range tmpList {
 					if i >= tmpListI {
 						break
 					}
 					sendmsg.ObjIDs[i] = string(obj.GetROCObjID())
+					if !isDelete {
+						sendmsg.Tokens[i] = this.getLocalObjToken(
+							obj.GetROCObjType(), obj.GetROCObjID())
+					}
 					tmpList[i] = nil
 				}
 				this.sendROCBindMsg(sendmsg)
@@ -488,6 +919,14 @@ func (this *ROCServer) rocObjNoticeProcess(rocCacheChan chan roc.IObj,
 
 // 发送ROC对象绑定信息
 func (this *ROCServer) sendROCBindMsg(sendmsg *servercomm.SROCBind) {
+	// 本模块自身的绑定变化计入版本，版本的递增与发送需要保持相同的顺序
+	if sendmsg.HostModuleID == this.server.moduleid {
+		this.bindMutex.Lock()
+		defer this.bindMutex.Unlock()
+		this.bindVersion++
+		sendmsg.Epoch = this.bindEpoch
+		sendmsg.Version = this.bindVersion
+	}
 	this.server.subnetManager.RangeServer(
 		func(s *connect.Server) bool {
 			if !process.HasModule(s.ModuleInfo.ModuleID) {
@@ -499,12 +938,29 @@ func (this *ROCServer) sendROCBindMsg(sendmsg *servercomm.SROCBind) {
 
 // 当收到ROC绑定信息时
 func (this *ROCServer) onMsgROCBind(msg *servercomm.SROCBind) {
+	objType := roc.ROCObjType(msg.ObjType)
 	if !msg.IsDelete {
-		roc.GetCache().SetM(roc.ROCObjType(msg.ObjType), msg.ObjIDs, msg.HostModuleID)
+		rejected := roc.GetCache().SetMToken(objType, msg.ObjIDs, msg.Tokens,
+			msg.HostModuleID)
+		if len(rejected) > 0 {
+			this.Warn("onMsgROCBind roc obj conflict type[%s] ids%+v "+
+				"host[%s] rejected by newer registration",
+				msg.ObjType, rejected, msg.HostModuleID)
+		}
+		this.checkFencedObj(objType, msg.ObjIDs, msg.HostModuleID)
 	} else {
-		roc.GetCache().DelM(roc.ROCObjType(msg.ObjType), msg.ObjIDs, msg.HostModuleID)
+		roc.GetCache().DelM(objType, msg.ObjIDs, msg.HostModuleID)
 	}
 	this.Syslog("onMsgROCBind roc cache setm type[%s] "+
 		"ids%+v host[%s]",
 		msg.ObjType, msg.ObjIDs, msg.HostModuleID)
+
+	if this.recordSyncingBind(msg) || msg.Epoch == 0 {
+		return
+	}
+	// 错过了该模块的绑定信息，需要重新同步
+	if !roc.GetCache().AdvanceModuleVersion(msg.HostModuleID, msg.Epoch,
+		msg.Version) {
+		this.startBindSync(msg.HostModuleID)
+	}
 }
@@ -0,0 +1,389 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/liasece/micserver/conf"
+	"github.com/liasece/micserver/roc"
+	"github.com/liasece/micserver/servercomm"
+	"github.com/liasece/micserver/trace"
+	"github.com/liasece/micserver/util/sysutil"
+)
+
+// ROC流式调用中调用方默认的接收窗口
+const defaultROCStreamWindow = 16
+
+// 调用方的一个ROC流，Recv 不可以并发调用
+type rocStreamReader struct {
+	server *ROCServer
+	seq    int64
+	window int32
+	// 已接收但还未向被调用方确认的帧数，只在 Recv 中访问
+	unacked int32
+	frames  chan *servercomm.SROCStreamFrame
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	mutex sync.Mutex
+	// 被调用方所在的模块，对象迁移后以收到的数据帧的来源为准
+	moduleid string
+	// 流结束的原因，结束后 Recv 总是返回该错误
+	err error
+}
+
+// 按顺序接收一帧数据
+func (this *rocStreamReader) Recv() ([]byte, error) {
+	if err := this.getErr(); err != nil {
+		return nil, err
+	}
+	select {
+	case frame := <-this.frames:
+		if frame.End {
+			err := roc.CodeToError(roc.ErrCode(frame.ErrCode), frame.Error,
+				frame.ErrDetails)
+			if err == nil {
+				err = io.EOF
+			}
+			this.finish(err, false)
+			return nil, this.getErr()
+		}
+		this.mutex.Lock()
+		this.moduleid = frame.FromModuleID
+		this.mutex.Unlock()
+		// 消费了半个窗口后确认，被调用方可以继续发送
+		this.unacked++
+		if this.unacked >= (this.window+1)/2 {
+			this.sendCtrl(this.unacked, false)
+			this.unacked = 0
+		}
+		return frame.Data, nil
+	case <-this.ctx.Done():
+		this.finish(roc.ContextErr(this.ctx), true)
+		return nil, this.getErr()
+	}
+}
+
+// 在 ctx 结束时结束该流并通知被调用方取消，不需要等待调用方调用 Recv 。
+// 流结束时 ctx 总是被取消，该协程随之退出
+func (this *rocStreamReader) watchContext() {
+	<-this.ctx.Done()
+	this.finish(roc.ContextErr(this.ctx), true)
+}
+
+// 不再接收数据
+func (this *rocStreamReader) Close() {
+	this.finish(roc.ErrCallCanceled, true)
+}
+
+func (this *rocStreamReader) getErr() error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.err
+}
+
+// 结束该流，notify 为 true 时通知被调用方取消
+func (this *rocStreamReader) finish(err error, notify bool) {
+	this.mutex.Lock()
+	if this.err != nil {
+		this.mutex.Unlock()
+		return
+	}
+	this.err = err
+	this.mutex.Unlock()
+	this.server.streams.Delete(this.seq)
+	this.cancel()
+	if notify {
+		this.sendCtrl(0, true)
+	}
+}
+
+// 向被调用方发送控制信息
+func (this *rocStreamReader) sendCtrl(credit int32, cancel bool) {
+	this.mutex.Lock()
+	moduleid := this.moduleid
+	this.mutex.Unlock()
+	this.server.sendROCStreamCtrl(&servercomm.SROCStreamCtrl{
+		FromModuleID: this.server.server.moduleid,
+		ToModuleID:   moduleid,
+		Seq:          this.seq,
+		Credit:       credit,
+		Cancel:       cancel,
+	})
+}
+
+// 被调用方的一个ROC流的写入器
+type rocStreamWriter struct {
+	server *ROCServer
+	agent  *requestAgent
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mutex sync.Mutex
+	// 还可以发送的帧数
+	credit int32
+	// 收到确认时通知等待中的 Send
+	notify chan struct{}
+}
+
+// 发送一帧数据
+func (this *rocStreamWriter) Send(data []byte) error {
+	if err := this.server.checkPayloadSize(int64(len(data))); err != nil {
+		return err
+	}
+	for {
+		this.mutex.Lock()
+		if this.credit > 0 {
+			this.credit--
+			this.mutex.Unlock()
+			break
+		}
+		this.mutex.Unlock()
+		select {
+		case <-this.notify:
+		case <-this.ctx.Done():
+			return roc.ErrCallCanceled
+		}
+	}
+	if this.ctx.Err() != nil {
+		return roc.ErrCallCanceled
+	}
+	this.server.sendROCStreamFrame(this.agent, &servercomm.SROCStreamFrame{
+		Data: data,
+	})
+	return nil
+}
+
+// 调用方取消或者流结束时被取消
+func (this *rocStreamWriter) Context() context.Context {
+	return this.ctx
+}
+
+// 增加可以发送的帧数
+func (this *rocStreamWriter) addCredit(n int32) {
+	this.mutex.Lock()
+	this.credit += n
+	this.mutex.Unlock()
+	select {
+	case this.notify <- struct{}{}:
+	default:
+	}
+}
+
+// 被调用方记录写入器使用的键
+func streamWriterKey(fromModuleID string, seq int64) string {
+	return fmt.Sprintf("%s:%d", fromModuleID, seq)
+}
+
+// 获取ROC流式调用中调用方的接收窗口
+func (this *ROCServer) getStreamWindow() int32 {
+	window := this.server.moduleConfig.GetInt64(conf.ROCStreamWindow)
+	if window <= 0 {
+		return defaultROCStreamWindow
+	}
+	return int32(window)
+}
+
+// 以流式调用的方式调用目标ROC对象，目标对象需要实现 roc.StreamObj 接口，
+// 被调用方发送的数据通过返回的读取器按顺序接收。被调用方最多发送接收窗口大小
+// 的未确认帧，见 conf.ROCStreamWindow 。ctx 超时或被取消时流结束，
+// 流式调用不经过客户端拦截器
+func (this *ROCServer) ROCStream(ctx context.Context, callpath *roc.ROCPath,
+	callarg []byte) (roc.StreamReader, error) {
+	if err := this.checkPayloadSize(int64(len(callarg))); err != nil {
+		return nil, err
+	}
+	objType := callpath.GetObjType()
+	objID := callpath.GetObjID()
+	moduleid, token, err := this.locateROCObj(ctx, objType, objID)
+	if err != nil {
+		this.Warn("ROCStream can't find roc object location %s err:%s",
+			callpath.String(), err.Error())
+		return nil, err
+	}
+	window := this.getStreamWindow()
+	reader := &rocStreamReader{
+		server:   this,
+		seq:      this.newSeq(),
+		window:   window,
+		frames:   make(chan *servercomm.SROCStreamFrame, window+1),
+		moduleid: moduleid,
+	}
+	reader.ctx, reader.cancel = context.WithCancel(ctx)
+	this.streams.Store(reader.seq, reader)
+	this.Syslog("ROCStream {%s:%s} Seq[%d] Window[%d]",
+		moduleid, callpath, reader.seq, window)
+
+	sc := trace.SpanContextFromContext(ctx)
+	// 目标在本进程中，直接交给目标模块处理
+	if local := this.getLocalROCServer(moduleid); local != nil {
+		local.pushROCRequest(&requestAgent{
+			fromModuleID: this.server.moduleid,
+			callpath:     callpath.String(),
+			callarg:      callarg,
+			seq:          reader.seq,
+			needReturn:   true,
+			caller:       this,
+			spanCtx:      sc,
+			token:        token,
+			streamWindow: window,
+		})
+		go reader.watchContext()
+		return reader, nil
+	}
+	server := this.server.subnetManager.GetServer(moduleid)
+	if server == nil {
+		this.streams.Delete(reader.seq)
+		reader.cancel()
+		this.Warn("ROCStream target module does not exist "+
+			"ModuleID[%s] Path[%s]", moduleid, callpath.String())
+		return nil, roc.ErrUnknowObj
+	}
+	this.sendROCMsg(server, &servercomm.SROCRequest{
+		FromModuleID: this.server.moduleid,
+		ToModuleID:   moduleid,
+		Seq:          reader.seq,
+		CallStr:      callpath.String(),
+		CallArg:      callarg,
+		NeedReturn:   true,
+		TraceID:      sc.TraceID,
+		SpanID:       sc.SpanID,
+		Token:        token,
+		StreamWindow: window,
+	})
+	go reader.watchContext()
+	return reader, nil
+}
+
+// 在独立的协程中执行流式调用，结束时向调用方发送流结束帧
+func (this *ROCServer) runROCStream(agent *requestAgent) {
+	w := &rocStreamWriter{
+		server: this,
+		agent:  agent,
+		credit: agent.streamWindow,
+		notify: make(chan struct{}, 1),
+	}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	key := streamWriterKey(agent.fromModuleID, agent.seq)
+	this.streamWriters.Store(key, w)
+	go func() {
+		var err error
+		defer func() {
+			if perr, stackInfo := sysutil.GetPanicInfo(recover()); perr != nil {
+				this.Error("[ROCServer.runROCStream] "+
+					"Panic: Path[%s] Err[%v] \n Stack[%s]",
+					agent.callpath, perr, stackInfo)
+				err = fmt.Errorf("roc stream panic: %v", perr)
+			}
+			this.streamWriters.Delete(key)
+			canceled := w.ctx.Err() != nil
+			w.cancel()
+			// 调用方已经取消，不需要发送结束帧
+			if !canceled {
+				this.sendROCResponse(agent, nil, err)
+			}
+		}()
+		_, err = this.callROCObj(agent,
+			func(path *roc.ROCPath, arg []byte) ([]byte, error) {
+				return nil, this._ROCManager.StreamPath(path, arg, w)
+			})
+	}()
+}
+
+// 向调用方发送一帧流式调用的数据
+func (this *ROCServer) sendROCStreamFrame(agent *requestAgent,
+	frame *servercomm.SROCStreamFrame) {
+	frame.FromModuleID = this.server.moduleid
+	frame.ToModuleID = agent.fromModuleID
+	frame.Seq = agent.seq
+	if agent.caller != nil {
+		agent.caller.onMsgROCStreamFrame(frame)
+		return
+	}
+	server := this.server.subnetManager.GetServer(agent.fromModuleID)
+	if server == nil {
+		this.Warn("ROC stream target module does not exist "+
+			"ModuleID[%s] Seq[%d]", agent.fromModuleID, agent.seq)
+		return
+	}
+	this.sendROCMsg(server, frame)
+}
+
+// 向被调用方发送流式调用的控制信息
+func (this *ROCServer) sendROCStreamCtrl(ctrl *servercomm.SROCStreamCtrl) {
+	if local := this.getLocalROCServer(ctrl.ToModuleID); local != nil {
+		local.onMsgROCStreamCtrl(ctrl)
+		return
+	}
+	server := this.server.subnetManager.GetServer(ctrl.ToModuleID)
+	if server == nil {
+		return
+	}
+	server.SendCmd(ctrl)
+}
+
+// 当收到流式调用的数据帧时
+func (this *ROCServer) onMsgROCStreamFrame(frame *servercomm.SROCStreamFrame) {
+	vi, ok := this.streams.Load(frame.Seq)
+	if !ok {
+		// 调用方已不再接收，通知被调用方取消
+		if !frame.End {
+			this.sendROCStreamCtrl(&servercomm.SROCStreamCtrl{
+				FromModuleID: this.server.moduleid,
+				ToModuleID:   frame.FromModuleID,
+				Seq:          frame.Seq,
+				Cancel:       true,
+			})
+		}
+		return
+	}
+	reader := vi.(*rocStreamReader)
+	select {
+	case reader.frames <- frame:
+	default:
+		this.Warn("ROC stream Seq[%d] from %s exceeds window %d",
+			frame.Seq, frame.FromModuleID, reader.window)
+		reader.finish(fmt.Errorf("roc stream exceeds window %d",
+			reader.window), true)
+	}
+}
+
+// 当收到流式调用的控制信息时
+func (this *ROCServer) onMsgROCStreamCtrl(ctrl *servercomm.SROCStreamCtrl) {
+	vi, ok := this.streamWriters.Load(streamWriterKey(ctrl.FromModuleID,
+		ctrl.Seq))
+	if !ok {
+		return
+	}
+	w := vi.(*rocStreamWriter)
+	if ctrl.Cancel {
+		w.cancel()
+		return
+	}
+	w.addCredit(ctrl.Credit)
+}
+
+// 与一个模块的连接全部断开时，结束与该模块之间的所有流，调用方的流以
+// roc.ErrConnLost 结束
+func (this *ROCServer) onStreamModuleLeave(moduleid string) {
+	this.streams.Range(func(ki, vi interface{}) bool {
+		reader := vi.(*rocStreamReader)
+		reader.mutex.Lock()
+		host := reader.moduleid
+		reader.mutex.Unlock()
+		if host == moduleid {
+			reader.finish(fmt.Errorf("%w: module %s left",
+				roc.ErrConnLost, moduleid), false)
+		}
+		return true
+	})
+	this.streamWriters.Range(func(ki, vi interface{}) bool {
+		w := vi.(*rocStreamWriter)
+		if w.agent.fromModuleID == moduleid {
+			w.cancel()
+		}
+		return true
+	})
+}
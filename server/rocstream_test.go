@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/liasece/micserver/conf"
+	"github.com/liasece/micserver/roc"
+)
+
+// 支持流式调用的测试对象。Count 按顺序发送参数指定数量的帧，
+// Fail 发送一帧后返回错误，Block 持续发送直到调用方取消
+type testStreamObj struct {
+	*testObj
+	// 流结束时写入写入器最后一次发送的错误
+	done chan error
+}
+
+func (this *testStreamObj) OnROCStream(path *roc.ROCPath, arg []byte,
+	w roc.StreamWriter) error {
+	switch path.Move() {
+	case "Count":
+		n, err := strconv.Atoi(string(arg))
+		if err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			if err := w.Send([]byte(strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "Fail":
+		if err := w.Send([]byte("0")); err != nil {
+			return err
+		}
+		return errors.New("test stream fail")
+	case "Block":
+		for {
+			if err := w.Send([]byte("0")); err != nil {
+				this.done <- err
+				return err
+			}
+		}
+	}
+	return errors.New("unknown stream function")
+}
+
+func TestROCStream(t *testing.T) {
+	a := newTestServer(t, "teststream", conf.BaseConfig{
+		string(conf.ROCStreamWindow): 2,
+	})
+	b := newTestServer(t, "teststream", nil)
+	connectTestServers(t, a, b)
+
+	objType := newTestObjType("TestStreamObj")
+	obj := &testStreamObj{
+		testObj: newTestObj(objType, "1", 0),
+		done:    make(chan error, 1),
+	}
+	b.NewROC(objType).GetOrRegObj("1", obj)
+	ctx := context.Background()
+
+	// 帧数远大于窗口，按顺序全部收到后以 io.EOF 结束
+	r, err := a.ROCStream(ctx, roc.O(objType, "1").F("Count"), []byte("20"))
+	if err != nil {
+		t.Fatalf("ROCStream err: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		data, err := r.Recv()
+		if err != nil || string(data) != strconv.Itoa(i) {
+			t.Fatalf("Recv %d = %q, %v", i, data, err)
+		}
+	}
+	if _, err := r.Recv(); err != io.EOF {
+		t.Fatalf("Recv after end err = %v, want EOF", err)
+	}
+
+	// 被调用方返回的错误传递给调用方
+	r, err = a.ROCStream(ctx, roc.O(objType, "1").F("Fail"), nil)
+	if err != nil {
+		t.Fatalf("ROCStream err: %v", err)
+	}
+	if data, err := r.Recv(); err != nil || string(data) != "0" {
+		t.Fatalf("Recv = %q, %v", data, err)
+	}
+	if _, err := r.Recv(); err == nil || err == io.EOF {
+		t.Fatalf("Recv after fail err = %v, want stream error", err)
+	}
+
+	// 调用方关闭后，被调用方的写入器返回错误
+	r, err = a.ROCStream(ctx, roc.O(objType, "1").F("Block"), nil)
+	if err != nil {
+		t.Fatalf("ROCStream err: %v", err)
+	}
+	if _, err := r.Recv(); err != nil {
+		t.Fatalf("Recv err: %v", err)
+	}
+	r.Close()
+	if err := <-obj.done; err == nil {
+		t.Fatalf("writer err after close = nil")
+	}
+	if _, err := r.Recv(); !errors.Is(err, roc.ErrCallCanceled) {
+		t.Fatalf("Recv after close err = %v, want %v", err,
+			roc.ErrCallCanceled)
+	}
+
+	// 调用方的 ctx 结束后，不需要调用 Recv 被调用方的写入器也返回错误
+	cctx, cancel := context.WithCancel(ctx)
+	r, err = a.ROCStream(cctx, roc.O(objType, "1").F("Block"), nil)
+	if err != nil {
+		t.Fatalf("ROCStream err: %v", err)
+	}
+	if _, err := r.Recv(); err != nil {
+		t.Fatalf("Recv err: %v", err)
+	}
+	cancel()
+	select {
+	case err := <-obj.done:
+		if err == nil {
+			t.Fatalf("writer err after cancel = nil")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("writer not canceled after ctx done")
+	}
+	if _, err := r.Recv(); !errors.Is(err, roc.ErrCallCanceled) {
+		t.Fatalf("Recv after cancel err = %v, want %v", err,
+			roc.ErrCallCanceled)
+	}
+}
@@ -4,6 +4,9 @@ micserver中管理与其他服务器连接的管理器
 package server
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/liasece/micserver/conf"
 	"github.com/liasece/micserver/connect"
 	"github.com/liasece/micserver/log"
@@ -14,6 +17,7 @@ import (
 	"github.com/liasece/micserver/server/subnet"
 	"github.com/liasece/micserver/servercomm"
 	"github.com/liasece/micserver/session"
+	"github.com/liasece/micserver/trace"
 	"github.com/liasece/micserver/util"
 )
 
@@ -120,12 +124,26 @@ func (this *Server) onServerJoinSubnet(server *connect.Server) {
 	this.ROCServer.onServerJoinSubnet(server)
 }
 
+// 当与一个服务器的所有连接都已断开时调用
+func (this *Server) onServerLeaveSubnet(server *connect.Server) {
+	this.Debug("服务器 ModuleID[%s] 离开子网",
+		server.ModuleInfo.ModuleID)
+	this.ROCServer.onServerLeaveSubnet(server)
+}
+
 // 发送一个服务器消息到另一个服务器
 func (this *Server) SendModuleMsg(
+	to string, msgstr msg.MsgStruct) {
+	this.SendModuleMsgContext(context.Background(), to, msgstr)
+}
+
+// 同 SendModuleMsg ，消息将携带 ctx 中的调用链追踪信息
+func (this *Server) SendModuleMsgContext(ctx context.Context,
 	to string, msgstr msg.MsgStruct) {
 	conn := this.subnetManager.GetServer(to)
 	if conn != nil {
-		conn.SendCmd(this.getModuleMsgPack(msgstr, conn))
+		conn.SendCmd(this.getModuleMsgPack(msgstr, conn,
+			trace.SpanContextFromContext(ctx)))
 	}
 }
 
@@ -193,7 +211,14 @@ func (this *Server) ForwardClientMsgToModule(fromconn *connect.Client,
 	to string, msgid uint16, data []byte) {
 	conn := this.subnetManager.GetServer(to)
 	if conn != nil {
-		conn.SendCmd(this.getFarwardFromGateMsgPack(msgid, data, fromconn, conn))
+		// 客户端消息是调用链的起点
+		_, span := trace.StartSpan(context.Background(), this.moduleid,
+			"gate.forward")
+		span.SetAttr("msg_id", fmt.Sprint(msgid))
+		span.SetAttr("to_module_id", to)
+		conn.SendCmd(this.getFarwardFromGateMsgPack(msgid, data, fromconn, conn,
+			span.SpanContext()))
+		span.Finish(nil)
 	} else {
 		this.Error("Server.ForwardClientMsgToServer conn == nil [%s]",
 			to)
@@ -202,7 +227,8 @@ func (this *Server) ForwardClientMsgToModule(fromconn *connect.Client,
 
 // 广播一个消息到连接到本服务器的所有服务器
 func (this *Server) BroadcastModuleCmd(msgstr msg.MsgStruct) {
-	this.subnetManager.BroadcastCmd(this.getModuleMsgPack(msgstr, nil))
+	this.subnetManager.BroadcastCmd(this.getModuleMsgPack(msgstr, nil,
+		trace.SpanContext{}))
 }
 
 // 获取一个均衡的负载服务器
@@ -295,9 +321,11 @@ func (this *Server) DoSendBytesToClient(fromserver string, gateid string,
 
 // 获取一个服务器消息的服务器间转发协议
 func (this *Server) getModuleMsgPack(msgstr msg.MsgStruct,
-	tarconn *connect.Server) msg.MsgStruct {
+	tarconn *connect.Server, sc trace.SpanContext) msg.MsgStruct {
 	res := &servercomm.SForwardToModule{}
 	res.FromModuleID = this.moduleid
+	res.TraceID = sc.TraceID
+	res.SpanID = sc.SpanID
 	if tarconn != nil {
 		res.ToModuleID = tarconn.ModuleInfo.ModuleID
 	}
@@ -310,9 +338,12 @@ func (this *Server) getModuleMsgPack(msgstr msg.MsgStruct,
 
 // 获取一个客户端消息到其他服务器间的转发协议
 func (this *Server) getFarwardFromGateMsgPack(msgid uint16, data []byte,
-	fromconn *connect.Client, tarconn *connect.Server) msg.MsgStruct {
+	fromconn *connect.Client, tarconn *connect.Server,
+	sc trace.SpanContext) msg.MsgStruct {
 	res := &servercomm.SForwardFromGate{}
 	res.FromModuleID = this.moduleid
+	res.TraceID = sc.TraceID
+	res.SpanID = sc.SpanID
 	if tarconn != nil {
 		res.ToModuleID = tarconn.ModuleInfo.ModuleID
 	}
@@ -329,4 +360,6 @@ func (this *Server) getFarwardFromGateMsgPack(msgid uint16, data []byte,
 
 func (this *Server) Stop() {
 	this.isStop = true
+	this.ROCServer.rocDispatcher.stop()
+	this.ROCServer.stopROCAdmin()
 }
@@ -1,11 +1,15 @@
 package server
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/liasece/micserver/connect"
 	"github.com/liasece/micserver/msg"
 	"github.com/liasece/micserver/server/base"
 	"github.com/liasece/micserver/servercomm"
 	"github.com/liasece/micserver/session"
+	"github.com/liasece/micserver/trace"
 )
 
 // 服务消息处理
@@ -24,12 +28,17 @@ func (this *serverCmdHandler) HookServer(serverHook base.ServerHook) {
 func (this *serverCmdHandler) onForwardToModule(conn *connect.Server,
 	smsg *servercomm.SForwardToModule) {
 	if this.serverHook != nil {
+		span, sc := this.startMsgSpan("module.msg", smsg.MsgID, conn,
+			smsg.TraceID, smsg.SpanID)
 		msg := &servercomm.ModuleMessage{
 			FromModule: conn.ModuleInfo,
 			MsgID:      smsg.MsgID,
 			Data:       smsg.Data,
+			TraceID:    sc.TraceID,
+			SpanID:     sc.SpanID,
 		}
 		this.serverHook.OnModuleMessage(msg)
+		span.Finish(nil)
 	}
 }
 
@@ -37,11 +46,16 @@ func (this *serverCmdHandler) onForwardToModule(conn *connect.Server,
 func (this *serverCmdHandler) onForwardFromGate(conn *connect.Server,
 	smsg *servercomm.SForwardFromGate) {
 	if this.serverHook != nil {
+		span, sc := this.startMsgSpan("client.msg", smsg.MsgID, conn,
+			smsg.TraceID, smsg.SpanID)
+		defer span.Finish(nil)
 		msg := &servercomm.ClientMessage{
 			FromModule:   conn.ModuleInfo,
 			ClientConnID: smsg.ClientConnID,
 			MsgID:        smsg.MsgID,
 			Data:         smsg.Data,
+			TraceID:      sc.TraceID,
+			SpanID:       sc.SpanID,
 		}
 		uuid := session.GetUUIDFromMap(smsg.Session)
 		var se *session.Session
@@ -55,6 +69,22 @@ func (this *serverCmdHandler) onForwardFromGate(conn *connect.Server,
 	}
 }
 
+// 开始处理一个消息的调用链片段，返回该片段及其标识
+func (this *serverCmdHandler) startMsgSpan(name string, msgid uint16,
+	conn *connect.Server, traceID string,
+	spanID string) (*trace.Span, trace.SpanContext) {
+	ctx, span := trace.StartSpanWithParent(context.Background(),
+		this.server.moduleid, name, trace.SpanContext{
+			TraceID: traceID,
+			SpanID:  spanID,
+		})
+	span.SetAttr("msg_id", fmt.Sprint(msgid))
+	if conn.ModuleInfo != nil {
+		span.SetAttr("from_module_id", conn.ModuleInfo.ModuleID)
+	}
+	return span, trace.SpanContextFromContext(ctx)
+}
+
 // 当收到转发一个消息到客户端时调用
 func (this *serverCmdHandler) onForwardToClient(smsg *servercomm.SForwardToClient) {
 	err := this.server.DoSendBytesToClient(smsg.FromModuleID, smsg.ToGateID,
@@ -125,6 +155,11 @@ func (this *serverCmdHandler) OnServerJoinSubnet(server *connect.Server) {
 	this.server.onServerJoinSubnet(server)
 }
 
+// 当与一个服务器的所有连接都已断开时调用
+func (this *serverCmdHandler) OnServerLeaveSubnet(server *connect.Server) {
+	this.server.onServerLeaveSubnet(server)
+}
+
 // 当收到一个其他服务发过来的消息时调用
 func (this *serverCmdHandler) OnRecvSubnetMsg(conn *connect.Server,
 	msgbinary *msg.MessageBinary) {
@@ -174,12 +209,97 @@ func (this *serverCmdHandler) OnRecvSubnetMsg(conn *connect.Server,
 		// ROC 调用请求
 		layerMsg := &servercomm.SROCRequest{}
 		layerMsg.ReadBinary(msgbinary.ProtoData)
-		this.server.ROCServer.onMsgROCRequest(layerMsg)
+		this.server.ROCServer.recvOrderedROCMsg(conn.ModuleInfo.ModuleID,
+			layerMsg.OrderSession, layerMsg.OrderSeq, func() {
+				this.server.ROCServer.onMsgROCRequest(layerMsg)
+			})
 	case servercomm.SROCResponseID:
 		// ROC 调用返回
 		layerMsg := &servercomm.SROCResponse{}
 		layerMsg.ReadBinary(msgbinary.ProtoData)
-		this.server.ROCServer.onMsgROCResponse(layerMsg)
+		this.server.ROCServer.recvOrderedROCMsg(conn.ModuleInfo.ModuleID,
+			layerMsg.OrderSession, layerMsg.OrderSeq, func() {
+				this.server.ROCServer.onMsgROCResponse(layerMsg)
+			})
+	case servercomm.SROCMigrateID:
+		// ROC 对象迁移
+		layerMsg := &servercomm.SROCMigrate{}
+		layerMsg.ReadBinary(msgbinary.ProtoData)
+		this.server.ROCServer.recvOrderedROCMsg(conn.ModuleInfo.ModuleID,
+			layerMsg.OrderSession, layerMsg.OrderSeq, func() {
+				this.server.ROCServer.onMsgROCMigrate(layerMsg)
+			})
+	case servercomm.SROCLocateReqID:
+		// ROC 对象位置查询
+		layerMsg := &servercomm.SROCLocateReq{}
+		layerMsg.ReadBinary(msgbinary.ProtoData)
+		this.server.ROCServer.onMsgROCLocateReq(layerMsg)
+	case servercomm.SROCLocateResID:
+		// ROC 对象位置查询的回答
+		layerMsg := &servercomm.SROCLocateRes{}
+		layerMsg.ReadBinary(msgbinary.ProtoData)
+		this.server.ROCServer.onMsgROCLocateRes(layerMsg)
+	case servercomm.SROCMulticastID:
+		// ROC 批量调用请求
+		layerMsg := &servercomm.SROCMulticast{}
+		layerMsg.ReadBinary(msgbinary.ProtoData)
+		this.server.ROCServer.recvOrderedROCMsg(conn.ModuleInfo.ModuleID,
+			layerMsg.OrderSession, layerMsg.OrderSeq, func() {
+				this.server.ROCServer.onMsgROCMulticast(layerMsg)
+			})
+	case servercomm.SROCStreamFrameID:
+		// ROC 流式调用数据帧
+		layerMsg := &servercomm.SROCStreamFrame{}
+		layerMsg.ReadBinary(msgbinary.ProtoData)
+		this.server.ROCServer.recvOrderedROCMsg(conn.ModuleInfo.ModuleID,
+			layerMsg.OrderSession, layerMsg.OrderSeq, func() {
+				this.server.ROCServer.onMsgROCStreamFrame(layerMsg)
+			})
+	case servercomm.SROCStreamCtrlID:
+		// ROC 流式调用控制信息
+		layerMsg := &servercomm.SROCStreamCtrl{}
+		layerMsg.ReadBinary(msgbinary.ProtoData)
+		this.server.ROCServer.onMsgROCStreamCtrl(layerMsg)
+	case servercomm.SROCActivateReqID:
+		// ROC 对象按需激活请求
+		layerMsg := &servercomm.SROCActivateReq{}
+		layerMsg.ReadBinary(msgbinary.ProtoData)
+		this.server.ROCServer.onMsgROCActivateReq(layerMsg)
+	case servercomm.SROCActivateResID:
+		// ROC 对象按需激活的结果
+		layerMsg := &servercomm.SROCActivateRes{}
+		layerMsg.ReadBinary(msgbinary.ProtoData)
+		this.server.ROCServer.onMsgROCActivateRes(layerMsg)
+	case servercomm.SROCStatsReqID:
+		// ROC 对象统计查询
+		layerMsg := &servercomm.SROCStatsReq{}
+		layerMsg.ReadBinary(msgbinary.ProtoData)
+		this.server.ROCServer.onMsgROCStatsReq(layerMsg)
+	case servercomm.SROCStatsResID:
+		// ROC 对象统计查询的回答
+		layerMsg := &servercomm.SROCStatsRes{}
+		layerMsg.ReadBinary(msgbinary.ProtoData)
+		this.server.ROCServer.onMsgROCStatsRes(layerMsg)
+	case servercomm.SROCChunkID:
+		// ROC 消息分片
+		layerMsg := &servercomm.SROCChunk{}
+		layerMsg.ReadBinary(msgbinary.ProtoData)
+		this.server.ROCServer.onMsgROCChunk(layerMsg)
+	case servercomm.SROCBindVersionID:
+		// ROC 绑定信息版本
+		layerMsg := &servercomm.SROCBindVersion{}
+		layerMsg.ReadBinary(msgbinary.ProtoData)
+		this.server.ROCServer.onMsgROCBindVersion(layerMsg)
+	case servercomm.SROCBindSyncReqID:
+		// ROC 绑定信息同步请求
+		layerMsg := &servercomm.SROCBindSyncReq{}
+		layerMsg.ReadBinary(msgbinary.ProtoData)
+		this.server.ROCServer.onMsgROCBindSyncReq(layerMsg)
+	case servercomm.SROCBindSyncEndID:
+		// ROC 绑定信息同步结束
+		layerMsg := &servercomm.SROCBindSyncEnd{}
+		layerMsg.ReadBinary(msgbinary.ProtoData)
+		this.server.ROCServer.onMsgROCBindSyncEnd(layerMsg)
 	default:
 		msgid := msgbinary.GetMsgID()
 		msgname := servercomm.MsgIdToString(msgid)
@@ -12,6 +12,8 @@ import (
 type SubnetHook interface {
 	// 当一个服务器成功加入网络时调用
 	OnServerJoinSubnet(server *connect.Server)
+	// 当与一个服务器的所有连接都已断开时调用
+	OnServerLeaveSubnet(server *connect.Server)
 	// 收到子网消息
 	OnRecvSubnetMsg(server *connect.Server, msgbin *msg.MessageBinary)
 }
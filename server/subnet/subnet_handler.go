@@ -21,6 +21,11 @@ type ConnectMsgQueueStruct struct {
 // 当TCP连接被移除时调用
 func (this *SubnetManager) onConnectClose(conn *connect.Server) {
 	this.RemoveServer(conn.GetTempID())
+	// 与该服务器已没有其他连接时，通知该服务器离开了子网
+	if conn.ModuleInfo != nil && this.subnetHook != nil &&
+		this.GetServer(conn.ModuleInfo.ModuleID) == nil {
+		this.subnetHook.OnServerLeaveSubnet(conn)
+	}
 }
 
 // 当收到TCP消息时调用
@@ -108,6 +108,9 @@ type SForwardToModule struct {
 	ToModuleID   string
 	MsgID        uint16
 	Data         []byte
+	// 调用链追踪信息，见 trace 包
+	TraceID string
+	SpanID  string
 }
 
 // 模块间传递的消息
@@ -115,6 +118,9 @@ type ModuleMessage struct {
 	FromModule *ModuleInfo
 	MsgID      uint16
 	Data       []byte
+	// 调用链追踪信息，见 trace 包
+	TraceID string
+	SpanID  string
 }
 
 // 请求转发一个客户端消息
@@ -134,6 +140,9 @@ type SForwardFromGate struct {
 	Session      map[string]string
 	MsgID        uint16
 	Data         []byte
+	// 调用链追踪信息，见 trace 包
+	TraceID string
+	SpanID  string
 }
 
 // 客户端消息
@@ -142,6 +151,9 @@ type ClientMessage struct {
 	ClientConnID string
 	MsgID        uint16
 	Data         []byte
+	// 调用链追踪信息，见 trace 包
+	TraceID string
+	SpanID  string
 }
 
 // ROC调用请求
@@ -154,6 +166,24 @@ type SROCRequest struct {
 	CallStr    string
 	CallArg    []byte
 	NeedReturn bool
+	// 调用链追踪信息，见 trace 包
+	TraceID string
+	SpanID  string
+	// 调用方所知的目标对象的围栏令牌，目标模块持有的注册更旧时拒绝该调用，
+	// 为0时不检查
+	Token uint64
+	// 流式调用时调用方的接收窗口，为0时不是流式调用，
+	// 被调用方通过 SROCStreamFrame 返回数据
+	StreamWindow int32
+	// 调用的幂等键，不为空时目标模块对同一调用方相同幂等键的重复请求直接返回
+	// 第一次执行的结果，重试的请求使用相同的幂等键
+	IdemKey string
+	// 是否为提醒调度发起的提醒触发，此时调用交给目标对象的 OnROCReminder 处理
+	Reminder bool
+	// 发送方向接收方发送的ROC消息的会话及在该会话中的序号，接收方按序号依次处理，
+	// 为0时不排序
+	OrderSession int64
+	OrderSeq     int64
 }
 
 // ROC调用响应
@@ -165,6 +195,13 @@ type SROCResponse struct {
 	// 响应数据
 	ResData []byte
 	Error   string
+	// 错误码及错误详情，由 roc.ErrorToCode 生成，用于在调用方还原错误
+	ErrCode    int32
+	ErrDetails map[string]string
+	// 发送方向接收方发送的ROC消息的会话及在该会话中的序号，接收方按序号依次处理，
+	// 为0时不排序
+	OrderSession int64
+	OrderSeq     int64
 }
 
 // ROC绑定信息
@@ -173,4 +210,185 @@ type SROCBind struct {
 	IsDelete     bool
 	ObjType      string
 	ObjIDs       []string
+	// 绑定信息的版本，由 HostModuleID 发送自身的绑定变化时递增，
+	// 同步或代为发送的绑定信息 Epoch 为0，不计入版本
+	Epoch   uint64
+	Version uint64
+	// 与 ObjIDs 一一对应的围栏令牌
+	Tokens []uint64
+}
+
+// ROC对象迁移请求，携带对象序列化后的状态，迁移结果通过 SROCResponse 返回
+type SROCMigrate struct {
+	FromModuleID string
+	ToModuleID   string
+	Seq          int64
+	ObjType      string
+	ObjID        string
+	State        []byte
+	// 目标模块注册该对象时至少使用的围栏令牌
+	Token uint64
+	// 发送方向接收方发送的ROC消息的会话及在该会话中的序号，接收方按序号依次处理，
+	// 为0时不排序
+	OrderSession int64
+	OrderSeq     int64
+}
+
+// ROC绑定信息的版本，模块加入子网时发送给对方，对方据此判断是否需要同步
+type SROCBindVersion struct {
+	HostModuleID string
+	// 模块启动时确定的纪元，模块重启后绑定信息需要重新同步
+	Epoch   uint64
+	Version uint64
+}
+
+// 请求同步目标模块所有的ROC绑定信息
+type SROCBindSyncReq struct {
+	FromModuleID string
+	HostModuleID string
+}
+
+// ROC绑定信息同步结束，在此之前发送的 SROCBind 包含了该模块所有的ROC对象
+type SROCBindSyncEnd struct {
+	HostModuleID string
+	Epoch        uint64
+	Version      uint64
+}
+
+// 查询ROC对象所在的模块，收到的模块通过 SROCLocateRes 回答
+type SROCLocateReq struct {
+	FromModuleID string
+	Seq          int64
+	ObjType      string
+	ObjID        string
+}
+
+// ROC对象位置查询的回答， HostModuleID 为空表示回答方不知道该对象的位置
+type SROCLocateRes struct {
+	FromModuleID string
+	ToModuleID   string
+	Seq          int64
+	ObjType      string
+	ObjID        string
+	HostModuleID string
+	// HostModuleID 上该对象注册的围栏令牌
+	Token uint64
+	// 回答的模块是否可以按需激活该类型的对象
+	Activatable bool
+}
+
+// 对同一模块中多个ROC对象的批量调用请求，每个对象的调用与 SROCRequest 相同，
+// Seqs 中为每个对象调用的序号，返回值分别通过 SROCResponse 返回
+type SROCMulticast struct {
+	FromModuleID string
+	ToModuleID   string
+	ObjType      string
+	ObjIDs       []string
+	Seqs         []int64
+	CallFunc     string
+	CallArg      []byte
+	NeedReturn   bool
+	// 调用链追踪信息，见 trace 包
+	TraceID string
+	SpanID  string
+	// 发送方向接收方发送的ROC消息的会话及在该会话中的序号，接收方按序号依次处理，
+	// 为0时不排序
+	OrderSession int64
+	OrderSeq     int64
+}
+
+// ROC流式调用中被调用方发送的一帧数据，End 为 true 时流结束，此时携带错误信息
+type SROCStreamFrame struct {
+	FromModuleID string
+	ToModuleID   string
+	// 流式调用请求的序号
+	Seq  int64
+	Data []byte
+	End  bool
+	// 流结束的原因
+	Error      string
+	ErrCode    int32
+	ErrDetails map[string]string
+	// 发送方向接收方发送的ROC消息的会话及在该会话中的序号，接收方按序号依次处理，
+	// 为0时不排序
+	OrderSession int64
+	OrderSeq     int64
+}
+
+// ROC流式调用中调用方发送的控制信息，确认已接收的帧数，或者取消该流
+type SROCStreamCtrl struct {
+	FromModuleID string
+	ToModuleID   string
+	// 流式调用请求的序号
+	Seq    int64
+	Credit int32
+	Cancel bool
+}
+
+// 按需激活ROC对象的请求，目标模块使用该类型的对象工厂激活对象，
+// 对象已存在时直接回答其位置
+type SROCActivateReq struct {
+	FromModuleID string
+	ToModuleID   string
+	// 发起激活的位置查询的序号
+	Seq     int64
+	ObjType string
+	ObjID   string
+}
+
+// 按需激活ROC对象的结果，成功时 HostModuleID 为对象所在的模块
+type SROCActivateRes struct {
+	FromModuleID string
+	ToModuleID   string
+	Seq          int64
+	ObjType      string
+	ObjID        string
+	HostModuleID string
+	// HostModuleID 上该对象注册的围栏令牌
+	Token   uint64
+	Error   string
+	ErrCode int32
+}
+
+// 查询目标模块中ROC对象的统计，收到的模块通过 SROCStatsRes 回答
+type SROCStatsReq struct {
+	FromModuleID string
+	ToModuleID   string
+	Seq          int64
+	// 只统计该类型的对象，为空时统计所有类型
+	ObjType   string
+	SampleNum int32
+}
+
+// ROC对象统计的回答， Stats 为 JSON 编码的 roc.ModuleStats
+type SROCStatsRes struct {
+	FromModuleID string
+	ToModuleID   string
+	Seq          int64
+	Stats        []byte
+}
+
+// ROC消息编码后超过单个消息的大小上限时被分片发送，接收方重组后按 MsgID 处理
+type SROCChunk struct {
+	// 发送分片的模块，转发的请求中与被分片的消息的 FromModuleID 不同
+	FromModuleID string
+	ToModuleID   string
+	// 分片传输的序号，同一个发送方中唯一
+	TransferID int64
+	// 被分片的消息的ID、编码后的总大小，以及其中调用参数或返回值的大小
+	MsgID       uint16
+	TotalSize   int64
+	PayloadSize int64
+	// 分片的序号及总数，以及该分片在编码后的消息中的偏移
+	Index  int32
+	Total  int32
+	Offset int64
+	Data   []byte
+	// 被分片的消息所属ROC调用的调用方及序号，接收方拒绝重组时用于返回错误
+	CallerModuleID string
+	CallSeq        int64
+	// 被分片的消息的排序会话及序号，同一传输的所有分片相同，接收方在重组后按序号处理，
+	// 以保证与发送方之前及之后发送的ROC消息之间的顺序
+	OrderSession int64
+	OrderSeq     int64
 }
@@ -28,6 +28,20 @@ const (
 	SROCRequestID             = 54
 	SROCResponseID            = 55
 	SROCBindID                = 56
+	SROCMigrateID             = 57
+	SROCBindVersionID         = 58
+	SROCBindSyncReqID         = 59
+	SROCBindSyncEndID         = 60
+	SROCLocateReqID           = 61
+	SROCLocateResID           = 62
+	SROCMulticastID           = 63
+	SROCStreamFrameID         = 64
+	SROCStreamCtrlID          = 65
+	SROCActivateReqID         = 66
+	SROCActivateResID         = 67
+	SROCStatsReqID            = 68
+	SROCStatsResID            = 69
+	SROCChunkID               = 70
 )
 
 const (
@@ -52,6 +66,20 @@ const (
 	SROCRequestName             = "servercomm.SROCRequest"
 	SROCResponseName            = "servercomm.SROCResponse"
 	SROCBindName                = "servercomm.SROCBind"
+	SROCMigrateName             = "servercomm.SROCMigrate"
+	SROCBindVersionName         = "servercomm.SROCBindVersion"
+	SROCBindSyncReqName         = "servercomm.SROCBindSyncReq"
+	SROCBindSyncEndName         = "servercomm.SROCBindSyncEnd"
+	SROCLocateReqName           = "servercomm.SROCLocateReq"
+	SROCLocateResName           = "servercomm.SROCLocateRes"
+	SROCMulticastName           = "servercomm.SROCMulticast"
+	SROCStreamFrameName         = "servercomm.SROCStreamFrame"
+	SROCStreamCtrlName          = "servercomm.SROCStreamCtrl"
+	SROCActivateReqName         = "servercomm.SROCActivateReq"
+	SROCActivateResName         = "servercomm.SROCActivateRes"
+	SROCStatsReqName            = "servercomm.SROCStatsReq"
+	SROCStatsResName            = "servercomm.SROCStatsRes"
+	SROCChunkName               = "servercomm.SROCChunk"
 )
 
 func (this *ModuleInfo) WriteBinary(data []byte) int {
@@ -138,6 +166,62 @@ func (this *SROCBind) WriteBinary(data []byte) int {
 	return WriteMsgSROCBindByObj(data, this)
 }
 
+func (this *SROCMigrate) WriteBinary(data []byte) int {
+	return WriteMsgSROCMigrateByObj(data, this)
+}
+
+func (this *SROCBindVersion) WriteBinary(data []byte) int {
+	return WriteMsgSROCBindVersionByObj(data, this)
+}
+
+func (this *SROCBindSyncReq) WriteBinary(data []byte) int {
+	return WriteMsgSROCBindSyncReqByObj(data, this)
+}
+
+func (this *SROCBindSyncEnd) WriteBinary(data []byte) int {
+	return WriteMsgSROCBindSyncEndByObj(data, this)
+}
+
+func (this *SROCLocateReq) WriteBinary(data []byte) int {
+	return WriteMsgSROCLocateReqByObj(data, this)
+}
+
+func (this *SROCLocateRes) WriteBinary(data []byte) int {
+	return WriteMsgSROCLocateResByObj(data, this)
+}
+
+func (this *SROCMulticast) WriteBinary(data []byte) int {
+	return WriteMsgSROCMulticastByObj(data, this)
+}
+
+func (this *SROCStreamFrame) WriteBinary(data []byte) int {
+	return WriteMsgSROCStreamFrameByObj(data, this)
+}
+
+func (this *SROCStreamCtrl) WriteBinary(data []byte) int {
+	return WriteMsgSROCStreamCtrlByObj(data, this)
+}
+
+func (this *SROCActivateReq) WriteBinary(data []byte) int {
+	return WriteMsgSROCActivateReqByObj(data, this)
+}
+
+func (this *SROCActivateRes) WriteBinary(data []byte) int {
+	return WriteMsgSROCActivateResByObj(data, this)
+}
+
+func (this *SROCStatsReq) WriteBinary(data []byte) int {
+	return WriteMsgSROCStatsReqByObj(data, this)
+}
+
+func (this *SROCStatsRes) WriteBinary(data []byte) int {
+	return WriteMsgSROCStatsResByObj(data, this)
+}
+
+func (this *SROCChunk) WriteBinary(data []byte) int {
+	return WriteMsgSROCChunkByObj(data, this)
+}
+
 func (this *ModuleInfo) ReadBinary(data []byte) int {
 	size, _ := ReadMsgModuleInfoByBytes(data, this)
 	return size
@@ -243,6 +327,76 @@ func (this *SROCBind) ReadBinary(data []byte) int {
 	return size
 }
 
+func (this *SROCMigrate) ReadBinary(data []byte) int {
+	size, _ := ReadMsgSROCMigrateByBytes(data, this)
+	return size
+}
+
+func (this *SROCBindVersion) ReadBinary(data []byte) int {
+	size, _ := ReadMsgSROCBindVersionByBytes(data, this)
+	return size
+}
+
+func (this *SROCBindSyncReq) ReadBinary(data []byte) int {
+	size, _ := ReadMsgSROCBindSyncReqByBytes(data, this)
+	return size
+}
+
+func (this *SROCBindSyncEnd) ReadBinary(data []byte) int {
+	size, _ := ReadMsgSROCBindSyncEndByBytes(data, this)
+	return size
+}
+
+func (this *SROCLocateReq) ReadBinary(data []byte) int {
+	size, _ := ReadMsgSROCLocateReqByBytes(data, this)
+	return size
+}
+
+func (this *SROCLocateRes) ReadBinary(data []byte) int {
+	size, _ := ReadMsgSROCLocateResByBytes(data, this)
+	return size
+}
+
+func (this *SROCMulticast) ReadBinary(data []byte) int {
+	size, _ := ReadMsgSROCMulticastByBytes(data, this)
+	return size
+}
+
+func (this *SROCStreamFrame) ReadBinary(data []byte) int {
+	size, _ := ReadMsgSROCStreamFrameByBytes(data, this)
+	return size
+}
+
+func (this *SROCStreamCtrl) ReadBinary(data []byte) int {
+	size, _ := ReadMsgSROCStreamCtrlByBytes(data, this)
+	return size
+}
+
+func (this *SROCActivateReq) ReadBinary(data []byte) int {
+	size, _ := ReadMsgSROCActivateReqByBytes(data, this)
+	return size
+}
+
+func (this *SROCActivateRes) ReadBinary(data []byte) int {
+	size, _ := ReadMsgSROCActivateResByBytes(data, this)
+	return size
+}
+
+func (this *SROCStatsReq) ReadBinary(data []byte) int {
+	size, _ := ReadMsgSROCStatsReqByBytes(data, this)
+	return size
+}
+
+func (this *SROCStatsRes) ReadBinary(data []byte) int {
+	size, _ := ReadMsgSROCStatsResByBytes(data, this)
+	return size
+}
+
+func (this *SROCChunk) ReadBinary(data []byte) int {
+	size, _ := ReadMsgSROCChunkByBytes(data, this)
+	return size
+}
+
 func MsgIdToString(id uint16) string {
 	switch id {
 	case ModuleInfoID:
@@ -287,6 +441,34 @@ func MsgIdToString(id uint16) string {
 		return SROCResponseName
 	case SROCBindID:
 		return SROCBindName
+	case SROCMigrateID:
+		return SROCMigrateName
+	case SROCBindVersionID:
+		return SROCBindVersionName
+	case SROCBindSyncReqID:
+		return SROCBindSyncReqName
+	case SROCBindSyncEndID:
+		return SROCBindSyncEndName
+	case SROCLocateReqID:
+		return SROCLocateReqName
+	case SROCLocateResID:
+		return SROCLocateResName
+	case SROCMulticastID:
+		return SROCMulticastName
+	case SROCStreamFrameID:
+		return SROCStreamFrameName
+	case SROCStreamCtrlID:
+		return SROCStreamCtrlName
+	case SROCActivateReqID:
+		return SROCActivateReqName
+	case SROCActivateResID:
+		return SROCActivateResName
+	case SROCStatsReqID:
+		return SROCStatsReqName
+	case SROCStatsResID:
+		return SROCStatsResName
+	case SROCChunkID:
+		return SROCChunkName
 	default:
 		return ""
 	}
@@ -336,6 +518,34 @@ func StringToMsgId(msgname string) uint16 {
 		return SROCResponseID
 	case SROCBindName:
 		return SROCBindID
+	case SROCMigrateName:
+		return SROCMigrateID
+	case SROCBindVersionName:
+		return SROCBindVersionID
+	case SROCBindSyncReqName:
+		return SROCBindSyncReqID
+	case SROCBindSyncEndName:
+		return SROCBindSyncEndID
+	case SROCLocateReqName:
+		return SROCLocateReqID
+	case SROCLocateResName:
+		return SROCLocateResID
+	case SROCMulticastName:
+		return SROCMulticastID
+	case SROCStreamFrameName:
+		return SROCStreamFrameID
+	case SROCStreamCtrlName:
+		return SROCStreamCtrlID
+	case SROCActivateReqName:
+		return SROCActivateReqID
+	case SROCActivateResName:
+		return SROCActivateResID
+	case SROCStatsReqName:
+		return SROCStatsReqID
+	case SROCStatsResName:
+		return SROCStatsResID
+	case SROCChunkName:
+		return SROCChunkID
 	default:
 		return 0
 	}
@@ -425,6 +635,62 @@ func (this *SROCBind) GetMsgId() uint16 {
 	return SROCBindID
 }
 
+func (this *SROCMigrate) GetMsgId() uint16 {
+	return SROCMigrateID
+}
+
+func (this *SROCBindVersion) GetMsgId() uint16 {
+	return SROCBindVersionID
+}
+
+func (this *SROCBindSyncReq) GetMsgId() uint16 {
+	return SROCBindSyncReqID
+}
+
+func (this *SROCBindSyncEnd) GetMsgId() uint16 {
+	return SROCBindSyncEndID
+}
+
+func (this *SROCLocateReq) GetMsgId() uint16 {
+	return SROCLocateReqID
+}
+
+func (this *SROCLocateRes) GetMsgId() uint16 {
+	return SROCLocateResID
+}
+
+func (this *SROCMulticast) GetMsgId() uint16 {
+	return SROCMulticastID
+}
+
+func (this *SROCStreamFrame) GetMsgId() uint16 {
+	return SROCStreamFrameID
+}
+
+func (this *SROCStreamCtrl) GetMsgId() uint16 {
+	return SROCStreamCtrlID
+}
+
+func (this *SROCActivateReq) GetMsgId() uint16 {
+	return SROCActivateReqID
+}
+
+func (this *SROCActivateRes) GetMsgId() uint16 {
+	return SROCActivateResID
+}
+
+func (this *SROCStatsReq) GetMsgId() uint16 {
+	return SROCStatsReqID
+}
+
+func (this *SROCStatsRes) GetMsgId() uint16 {
+	return SROCStatsResID
+}
+
+func (this *SROCChunk) GetMsgId() uint16 {
+	return SROCChunkID
+}
+
 func (this *ModuleInfo) GetMsgName() string {
 	return ModuleInfoName
 }
@@ -509,6 +775,62 @@ func (this *SROCBind) GetMsgName() string {
 	return SROCBindName
 }
 
+func (this *SROCMigrate) GetMsgName() string {
+	return SROCMigrateName
+}
+
+func (this *SROCBindVersion) GetMsgName() string {
+	return SROCBindVersionName
+}
+
+func (this *SROCBindSyncReq) GetMsgName() string {
+	return SROCBindSyncReqName
+}
+
+func (this *SROCBindSyncEnd) GetMsgName() string {
+	return SROCBindSyncEndName
+}
+
+func (this *SROCLocateReq) GetMsgName() string {
+	return SROCLocateReqName
+}
+
+func (this *SROCLocateRes) GetMsgName() string {
+	return SROCLocateResName
+}
+
+func (this *SROCMulticast) GetMsgName() string {
+	return SROCMulticastName
+}
+
+func (this *SROCStreamFrame) GetMsgName() string {
+	return SROCStreamFrameName
+}
+
+func (this *SROCStreamCtrl) GetMsgName() string {
+	return SROCStreamCtrlName
+}
+
+func (this *SROCActivateReq) GetMsgName() string {
+	return SROCActivateReqName
+}
+
+func (this *SROCActivateRes) GetMsgName() string {
+	return SROCActivateResName
+}
+
+func (this *SROCStatsReq) GetMsgName() string {
+	return SROCStatsReqName
+}
+
+func (this *SROCStatsRes) GetMsgName() string {
+	return SROCStatsResName
+}
+
+func (this *SROCChunk) GetMsgName() string {
+	return SROCChunkName
+}
+
 func (this *ModuleInfo) GetSize() int {
 	return GetSizeModuleInfo(this)
 }
@@ -593,6 +915,62 @@ func (this *SROCBind) GetSize() int {
 	return GetSizeSROCBind(this)
 }
 
+func (this *SROCMigrate) GetSize() int {
+	return GetSizeSROCMigrate(this)
+}
+
+func (this *SROCBindVersion) GetSize() int {
+	return GetSizeSROCBindVersion(this)
+}
+
+func (this *SROCBindSyncReq) GetSize() int {
+	return GetSizeSROCBindSyncReq(this)
+}
+
+func (this *SROCBindSyncEnd) GetSize() int {
+	return GetSizeSROCBindSyncEnd(this)
+}
+
+func (this *SROCLocateReq) GetSize() int {
+	return GetSizeSROCLocateReq(this)
+}
+
+func (this *SROCLocateRes) GetSize() int {
+	return GetSizeSROCLocateRes(this)
+}
+
+func (this *SROCMulticast) GetSize() int {
+	return GetSizeSROCMulticast(this)
+}
+
+func (this *SROCStreamFrame) GetSize() int {
+	return GetSizeSROCStreamFrame(this)
+}
+
+func (this *SROCStreamCtrl) GetSize() int {
+	return GetSizeSROCStreamCtrl(this)
+}
+
+func (this *SROCActivateReq) GetSize() int {
+	return GetSizeSROCActivateReq(this)
+}
+
+func (this *SROCActivateRes) GetSize() int {
+	return GetSizeSROCActivateRes(this)
+}
+
+func (this *SROCStatsReq) GetSize() int {
+	return GetSizeSROCStatsReq(this)
+}
+
+func (this *SROCStatsRes) GetSize() int {
+	return GetSizeSROCStatsRes(this)
+}
+
+func (this *SROCChunk) GetSize() int {
+	return GetSizeSROCChunk(this)
+}
+
 func (this *ModuleInfo) GetJson() string {
 	json, _ := json.Marshal(this)
 	return string(json)
@@ -698,6 +1076,76 @@ func (this *SROCBind) GetJson() string {
 	return string(json)
 }
 
+func (this *SROCMigrate) GetJson() string {
+	json, _ := json.Marshal(this)
+	return string(json)
+}
+
+func (this *SROCBindVersion) GetJson() string {
+	json, _ := json.Marshal(this)
+	return string(json)
+}
+
+func (this *SROCBindSyncReq) GetJson() string {
+	json, _ := json.Marshal(this)
+	return string(json)
+}
+
+func (this *SROCBindSyncEnd) GetJson() string {
+	json, _ := json.Marshal(this)
+	return string(json)
+}
+
+func (this *SROCLocateReq) GetJson() string {
+	json, _ := json.Marshal(this)
+	return string(json)
+}
+
+func (this *SROCLocateRes) GetJson() string {
+	json, _ := json.Marshal(this)
+	return string(json)
+}
+
+func (this *SROCMulticast) GetJson() string {
+	json, _ := json.Marshal(this)
+	return string(json)
+}
+
+func (this *SROCStreamFrame) GetJson() string {
+	json, _ := json.Marshal(this)
+	return string(json)
+}
+
+func (this *SROCStreamCtrl) GetJson() string {
+	json, _ := json.Marshal(this)
+	return string(json)
+}
+
+func (this *SROCActivateReq) GetJson() string {
+	json, _ := json.Marshal(this)
+	return string(json)
+}
+
+func (this *SROCActivateRes) GetJson() string {
+	json, _ := json.Marshal(this)
+	return string(json)
+}
+
+func (this *SROCStatsReq) GetJson() string {
+	json, _ := json.Marshal(this)
+	return string(json)
+}
+
+func (this *SROCStatsRes) GetJson() string {
+	json, _ := json.Marshal(this)
+	return string(json)
+}
+
+func (this *SROCChunk) GetJson() string {
+	json, _ := json.Marshal(this)
+	return string(json)
+}
+
 func readBinaryString(data []byte) string {
 	strfunclen := binary.LittleEndian.Uint32(data[:4])
 	if int(strfunclen)+4 > len(data) {
@@ -740,6 +1188,18 @@ func writeBinaryInt8(data []byte, num int8) {
 	data[0] = byte(num)
 }
 
+func writeBinaryInt16(data []byte, num int16) {
+	binary.LittleEndian.PutUint16(data, uint16(num))
+}
+
+func writeBinaryInt32(data []byte, num int32) {
+	binary.LittleEndian.PutUint32(data, uint32(num))
+}
+
+func writeBinaryInt64(data []byte, num int64) {
+	binary.LittleEndian.PutUint64(data, uint64(num))
+}
+
 func readBinaryBool(data []byte) bool {
 	// 大端模式
 	num := int8(0)
@@ -1698,6 +2158,16 @@ func ReadMsgSForwardToModuleByBytes(indata []byte, obj *SForwardToModule) (int,
 		copy(obj.Data, data[offset:offset+Data_slen])
 		offset += Data_slen
 	}
+	if offset+4+len(obj.TraceID) > data__len {
+		return endpos, obj
+	}
+	obj.TraceID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.TraceID)
+	if offset+4+len(obj.SpanID) > data__len {
+		return endpos, obj
+	}
+	obj.SpanID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.SpanID)
 
 	return endpos, obj
 }
@@ -1726,6 +2196,10 @@ func WriteMsgSForwardToModuleByObj(data []byte, obj *SForwardToModule) int {
 	Data_slen := len(obj.Data)
 	copy(data[offset:offset+Data_slen], obj.Data)
 	offset += Data_slen
+	writeBinaryString(data[offset:], obj.TraceID)
+	offset += 4 + len(obj.TraceID)
+	writeBinaryString(data[offset:], obj.SpanID)
+	offset += 4 + len(obj.SpanID)
 
 	return offset
 }
@@ -1735,7 +2209,8 @@ func GetSizeSForwardToModule(obj *SForwardToModule) int {
 		return 4
 	}
 
-	return 4 + 4 + len(obj.FromModuleID) + 4 + len(obj.ToModuleID) + 2 + 4 + len(obj.Data)*1
+	return 4 + 4 + len(obj.FromModuleID) + 4 + len(obj.ToModuleID) + 2 + 4 + len(obj.Data)*1 +
+		4 + len(obj.TraceID) + 4 + len(obj.SpanID)
 }
 
 func ReadMsgModuleMessageByBytes(indata []byte, obj *ModuleMessage) (int, *ModuleMessage) {
@@ -1782,6 +2257,16 @@ func ReadMsgModuleMessageByBytes(indata []byte, obj *ModuleMessage) (int, *Modul
 		copy(obj.Data, data[offset:offset+Data_slen])
 		offset += Data_slen
 	}
+	if offset+4+len(obj.TraceID) > data__len {
+		return endpos, obj
+	}
+	obj.TraceID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.TraceID)
+	if offset+4+len(obj.SpanID) > data__len {
+		return endpos, obj
+	}
+	obj.SpanID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.SpanID)
 
 	return endpos, obj
 }
@@ -1807,6 +2292,10 @@ func WriteMsgModuleMessageByObj(data []byte, obj *ModuleMessage) int {
 	Data_slen := len(obj.Data)
 	copy(data[offset:offset+Data_slen], obj.Data)
 	offset += Data_slen
+	writeBinaryString(data[offset:], obj.TraceID)
+	offset += 4 + len(obj.TraceID)
+	writeBinaryString(data[offset:], obj.SpanID)
+	offset += 4 + len(obj.SpanID)
 
 	return offset
 }
@@ -1816,7 +2305,8 @@ func GetSizeModuleMessage(obj *ModuleMessage) int {
 		return 4
 	}
 
-	return 4 + obj.FromModule.GetSize() + 2 + 4 + len(obj.Data)*1
+	return 4 + obj.FromModule.GetSize() + 2 + 4 + len(obj.Data)*1 + 4 + len(obj.TraceID) +
+		4 + len(obj.SpanID)
 }
 
 func ReadMsgSForwardToClientByBytes(indata []byte, obj *SForwardToClient) (int, *SForwardToClient) {
@@ -1991,6 +2481,16 @@ func ReadMsgSForwardFromGateByBytes(indata []byte, obj *SForwardFromGate) (int,
 		copy(obj.Data, data[offset:offset+Data_slen])
 		offset += Data_slen
 	}
+	if offset+4+len(obj.TraceID) > data__len {
+		return endpos, obj
+	}
+	obj.TraceID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.TraceID)
+	if offset+4+len(obj.SpanID) > data__len {
+		return endpos, obj
+	}
+	obj.SpanID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.SpanID)
 
 	return endpos, obj
 }
@@ -2033,6 +2533,10 @@ func WriteMsgSForwardFromGateByObj(data []byte, obj *SForwardFromGate) int {
 	Data_slen := len(obj.Data)
 	copy(data[offset:offset+Data_slen], obj.Data)
 	offset += Data_slen
+	writeBinaryString(data[offset:], obj.TraceID)
+	offset += 4 + len(obj.TraceID)
+	writeBinaryString(data[offset:], obj.SpanID)
+	offset += 4 + len(obj.SpanID)
 
 	return offset
 }
@@ -2048,7 +2552,7 @@ func GetSizeSForwardFromGate(obj *SForwardFromGate) int {
 	}
 
 	return 4 + 4 + len(obj.FromModuleID) + 4 + len(obj.ToModuleID) + 4 + len(obj.ClientConnID) + 4 + sizerelystring4 +
-		2 + 4 + len(obj.Data)*1
+		2 + 4 + len(obj.Data)*1 + 4 + len(obj.TraceID) + 4 + len(obj.SpanID)
 }
 
 func ReadMsgClientMessageByBytes(indata []byte, obj *ClientMessage) (int, *ClientMessage) {
@@ -2100,6 +2604,16 @@ func ReadMsgClientMessageByBytes(indata []byte, obj *ClientMessage) (int, *Clien
 		copy(obj.Data, data[offset:offset+Data_slen])
 		offset += Data_slen
 	}
+	if offset+4+len(obj.TraceID) > data__len {
+		return endpos, obj
+	}
+	obj.TraceID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.TraceID)
+	if offset+4+len(obj.SpanID) > data__len {
+		return endpos, obj
+	}
+	obj.SpanID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.SpanID)
 
 	return endpos, obj
 }
@@ -2127,6 +2641,10 @@ func WriteMsgClientMessageByObj(data []byte, obj *ClientMessage) int {
 	Data_slen := len(obj.Data)
 	copy(data[offset:offset+Data_slen], obj.Data)
 	offset += Data_slen
+	writeBinaryString(data[offset:], obj.TraceID)
+	offset += 4 + len(obj.TraceID)
+	writeBinaryString(data[offset:], obj.SpanID)
+	offset += 4 + len(obj.SpanID)
 
 	return offset
 }
@@ -2136,7 +2654,8 @@ func GetSizeClientMessage(obj *ClientMessage) int {
 		return 4
 	}
 
-	return 4 + obj.FromModule.GetSize() + 4 + len(obj.ClientConnID) + 2 + 4 + len(obj.Data)*1
+	return 4 + obj.FromModule.GetSize() + 4 + len(obj.ClientConnID) + 2 + 4 + len(obj.Data)*1 +
+		4 + len(obj.TraceID) + 4 + len(obj.SpanID)
 }
 
 func ReadMsgSROCRequestByBytes(indata []byte, obj *SROCRequest) (int, *SROCRequest) {
@@ -2197,24 +2716,64 @@ func ReadMsgSROCRequestByBytes(indata []byte, obj *SROCRequest) (int, *SROCReque
 	}
 	obj.NeedReturn = uint8(data[offset]) != 0
 	offset += 1
-
-	return endpos, obj
-}
-
-func WriteMsgSROCRequestByObj(data []byte, obj *SROCRequest) int {
-	if obj == nil {
-		binary.LittleEndian.PutUint32(data[0:4], 0)
-		return 4
+	if offset+4+len(obj.TraceID) > data__len {
+		return endpos, obj
 	}
-	objsize := obj.GetSize() - 4
-	offset := 0
-	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(objsize))
-	offset += 4
-	writeBinaryString(data[offset:], obj.FromModuleID)
-	offset += 4 + len(obj.FromModuleID)
-	writeBinaryString(data[offset:], obj.ToModuleID)
-	offset += 4 + len(obj.ToModuleID)
-	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.Seq))
+	obj.TraceID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.TraceID)
+	if offset+4+len(obj.SpanID) > data__len {
+		return endpos, obj
+	}
+	obj.SpanID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.SpanID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Token = binary.LittleEndian.Uint64(data[offset : offset+8])
+	offset += 8
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	obj.StreamWindow = int32(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if offset+4+len(obj.IdemKey) > data__len {
+		return endpos, obj
+	}
+	obj.IdemKey = readBinaryString(data[offset:])
+	offset += 4 + len(obj.IdemKey)
+	if offset+1 > data__len {
+		return endpos, obj
+	}
+	obj.Reminder = uint8(data[offset]) != 0
+	offset += 1
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.OrderSession = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.OrderSeq = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+
+	return endpos, obj
+}
+
+func WriteMsgSROCRequestByObj(data []byte, obj *SROCRequest) int {
+	if obj == nil {
+		binary.LittleEndian.PutUint32(data[0:4], 0)
+		return 4
+	}
+	objsize := obj.GetSize() - 4
+	offset := 0
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(objsize))
+	offset += 4
+	writeBinaryString(data[offset:], obj.FromModuleID)
+	offset += 4 + len(obj.FromModuleID)
+	writeBinaryString(data[offset:], obj.ToModuleID)
+	offset += 4 + len(obj.ToModuleID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.Seq))
 	offset += 8
 	writeBinaryString(data[offset:], obj.CallStr)
 	offset += 4 + len(obj.CallStr)
@@ -2229,6 +2788,22 @@ func WriteMsgSROCRequestByObj(data []byte, obj *SROCRequest) int {
 	offset += CallArg_slen
 	data[offset] = uint8(bool2int(obj.NeedReturn))
 	offset += 1
+	writeBinaryString(data[offset:], obj.TraceID)
+	offset += 4 + len(obj.TraceID)
+	writeBinaryString(data[offset:], obj.SpanID)
+	offset += 4 + len(obj.SpanID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], obj.Token)
+	offset += 8
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(obj.StreamWindow))
+	offset += 4
+	writeBinaryString(data[offset:], obj.IdemKey)
+	offset += 4 + len(obj.IdemKey)
+	data[offset] = uint8(bool2int(obj.Reminder))
+	offset += 1
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.OrderSession))
+	offset += 8
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.OrderSeq))
+	offset += 8
 
 	return offset
 }
@@ -2239,7 +2814,8 @@ func GetSizeSROCRequest(obj *SROCRequest) int {
 	}
 
 	return 4 + 4 + len(obj.FromModuleID) + 4 + len(obj.ToModuleID) + 8 + 4 + len(obj.CallStr) +
-		4 + len(obj.CallArg)*1 + 1
+		4 + len(obj.CallArg)*1 + 1 + 4 + len(obj.TraceID) + 4 + len(obj.SpanID) + 8 +
+		4 + 4 + len(obj.IdemKey) + 1 + 8 + 8
 }
 
 func ReadMsgSROCResponseByBytes(indata []byte, obj *SROCResponse) (int, *SROCResponse) {
@@ -2295,6 +2871,44 @@ func ReadMsgSROCResponseByBytes(indata []byte, obj *SROCResponse) (int, *SROCRes
 	}
 	obj.Error = readBinaryString(data[offset:])
 	offset += 4 + len(obj.Error)
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	obj.ErrCode = int32(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	ErrDetails_slen := binary.LittleEndian.Uint32(data[offset : offset+4])
+	offset += 4
+	if ErrDetails_slen != 0xffffffff {
+		obj.ErrDetails = make(map[string]string)
+		for i7i := uint32(0); i7i < ErrDetails_slen; i7i++ {
+			if offset+0 > data__len {
+				return endpos, obj
+			}
+			keyErrDetails := readBinaryString(data[offset:])
+			ErrDetails_kcatlen := len(keyErrDetails)
+			offset += ErrDetails_kcatlen + 4
+			if offset+2 > data__len {
+				return endpos, obj
+			}
+			valueErrDetails := readBinaryString(data[offset:])
+			ErrDetails_vcatlen := len(valueErrDetails)
+			offset += ErrDetails_vcatlen + 4
+			obj.ErrDetails[keyErrDetails] = valueErrDetails
+		}
+	}
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.OrderSession = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.OrderSeq = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
 
 	return endpos, obj
 }
@@ -2325,6 +2939,24 @@ func WriteMsgSROCResponseByObj(data []byte, obj *SROCResponse) int {
 	offset += ResData_slen
 	writeBinaryString(data[offset:], obj.Error)
 	offset += 4 + len(obj.Error)
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(obj.ErrCode))
+	offset += 4
+	if obj.ErrDetails == nil {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], 0xffffffff)
+	} else {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(len(obj.ErrDetails)))
+	}
+	offset += 4
+	for ErrDetailskey, ErrDetailsvalue := range obj.ErrDetails {
+		ErrDetails_kcatlen := writeBinaryString(data[offset:], ErrDetailskey)
+		offset += ErrDetails_kcatlen
+		ErrDetails_vcatlen := writeBinaryString(data[offset:], ErrDetailsvalue)
+		offset += ErrDetails_vcatlen
+	}
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.OrderSession))
+	offset += 8
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.OrderSeq))
+	offset += 8
 
 	return offset
 }
@@ -2333,9 +2965,14 @@ func GetSizeSROCResponse(obj *SROCResponse) int {
 	if obj == nil {
 		return 4
 	}
+	sizerelystring7 := 0
+	for ErrDetailsvalue, ErrDetailskey := range obj.ErrDetails {
+		sizerelystring7 += len(ErrDetailsvalue) + 4
+		sizerelystring7 += len(ErrDetailskey) + 4
+	}
 
 	return 4 + 4 + len(obj.FromModuleID) + 4 + len(obj.ToModuleID) + 8 + 4 + len(obj.ResData)*1 +
-		4 + len(obj.Error)
+		4 + len(obj.Error) + 4 + 4 + sizerelystring7 + 8 + 8
 }
 
 func ReadMsgSROCBindByBytes(indata []byte, obj *SROCBind) (int, *SROCBind) {
@@ -2379,13 +3016,38 @@ func ReadMsgSROCBindByBytes(indata []byte, obj *SROCBind) (int, *SROCBind) {
 	ObjIDs_slen := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
 	offset += 4
 	if ObjIDs_slen != 0xffffffff {
-		if offset+(ObjIDs_slen*0) > data__len {
-			return endpos, obj
-		}
 		obj.ObjIDs = make([]string, ObjIDs_slen)
 		for i4i := 0; ObjIDs_slen > i4i; i4i++ {
-			obj.ObjIDs[i4i] = string(readBinaryString(data[offset:]))
-			offset += 0
+			if offset+4 > data__len {
+				return endpos, obj
+			}
+			obj.ObjIDs[i4i] = readBinaryString(data[offset:])
+			offset += 4 + len(obj.ObjIDs[i4i])
+		}
+	}
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Epoch = binary.LittleEndian.Uint64(data[offset : offset+8])
+	offset += 8
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Version = binary.LittleEndian.Uint64(data[offset : offset+8])
+	offset += 8
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	Tokens_slen := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if Tokens_slen != 0xffffffff {
+		if offset+(Tokens_slen*8) > data__len {
+			return endpos, obj
+		}
+		obj.Tokens = make([]uint64, Tokens_slen)
+		for i7i := 0; Tokens_slen > i7i; i7i++ {
+			obj.Tokens[i7i] = uint64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+			offset += 8
 		}
 	}
 
@@ -2415,8 +3077,23 @@ func WriteMsgSROCBindByObj(data []byte, obj *SROCBind) int {
 	offset += 4
 	ObjIDs_slen := len(obj.ObjIDs)
 	for i4i := 0; ObjIDs_slen > i4i; i4i++ {
-		writeBinaryString(data[offset:offset+0], obj.ObjIDs[i4i])
-		offset += 0
+		writeBinaryString(data[offset:], obj.ObjIDs[i4i])
+		offset += 4 + len(obj.ObjIDs[i4i])
+	}
+	binary.LittleEndian.PutUint64(data[offset:offset+8], obj.Epoch)
+	offset += 8
+	binary.LittleEndian.PutUint64(data[offset:offset+8], obj.Version)
+	offset += 8
+	if obj.Tokens == nil {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], 0xffffffff)
+	} else {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(len(obj.Tokens)))
+	}
+	offset += 4
+	Tokens_slen := len(obj.Tokens)
+	for i7i := 0; Tokens_slen > i7i; i7i++ {
+		binary.LittleEndian.PutUint64(data[offset:offset+8], obj.Tokens[i7i])
+		offset += 8
 	}
 
 	return offset
@@ -2434,5 +3111,1452 @@ func GetSizeSROCBind(obj *SROCBind) int {
 		i4i++
 	}
 
-	return 4 + 4 + len(obj.HostModuleID) + 1 + 4 + len(obj.ObjType) + 4 + sizerelystring4
+	return 4 + 4 + len(obj.HostModuleID) + 1 + 4 + len(obj.ObjType) + 4 + sizerelystring4 +
+		8 + 8 + 4 + len(obj.Tokens)*8
+}
+
+func ReadMsgSROCMigrateByBytes(indata []byte, obj *SROCMigrate) (int, *SROCMigrate) {
+	offset := 0
+	if len(indata) < 4 {
+		return 0, nil
+	}
+	objsize := int(binary.LittleEndian.Uint32(indata))
+	offset += 4
+	if objsize == 0 {
+		return 4, nil
+	}
+	if obj == nil {
+		obj = &SROCMigrate{}
+	}
+	if offset+objsize > len(indata) {
+		return offset, obj
+	}
+	endpos := offset + objsize
+	data := indata[offset : offset+objsize]
+	offset = 0
+	data__len := len(data)
+	if offset+4+len(obj.FromModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.FromModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.FromModuleID)
+	if offset+4+len(obj.ToModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.ToModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ToModuleID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Seq = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+4+len(obj.ObjType) > data__len {
+		return endpos, obj
+	}
+	obj.ObjType = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ObjType)
+	if offset+4+len(obj.ObjID) > data__len {
+		return endpos, obj
+	}
+	obj.ObjID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ObjID)
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	State_slen := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if State_slen != 0xffffffff {
+		if offset+State_slen > data__len {
+			return endpos, obj
+		}
+		obj.State = make([]byte, State_slen)
+		copy(obj.State, data[offset:offset+State_slen])
+		offset += State_slen
+	}
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Token = binary.LittleEndian.Uint64(data[offset : offset+8])
+	offset += 8
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.OrderSession = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.OrderSeq = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+
+	return endpos, obj
+}
+
+func WriteMsgSROCMigrateByObj(data []byte, obj *SROCMigrate) int {
+	if obj == nil {
+		binary.LittleEndian.PutUint32(data[0:4], 0)
+		return 4
+	}
+	objsize := obj.GetSize() - 4
+	offset := 0
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(objsize))
+	offset += 4
+	writeBinaryString(data[offset:], obj.FromModuleID)
+	offset += 4 + len(obj.FromModuleID)
+	writeBinaryString(data[offset:], obj.ToModuleID)
+	offset += 4 + len(obj.ToModuleID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.Seq))
+	offset += 8
+	writeBinaryString(data[offset:], obj.ObjType)
+	offset += 4 + len(obj.ObjType)
+	writeBinaryString(data[offset:], obj.ObjID)
+	offset += 4 + len(obj.ObjID)
+	if obj.State == nil {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], 0xffffffff)
+	} else {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(len(obj.State)))
+	}
+	offset += 4
+	State_slen := len(obj.State)
+	copy(data[offset:offset+State_slen], obj.State)
+	offset += State_slen
+	binary.LittleEndian.PutUint64(data[offset:offset+8], obj.Token)
+	offset += 8
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.OrderSession))
+	offset += 8
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.OrderSeq))
+	offset += 8
+
+	return offset
+}
+
+func GetSizeSROCMigrate(obj *SROCMigrate) int {
+	if obj == nil {
+		return 4
+	}
+
+	return 4 + 4 + len(obj.FromModuleID) + 4 + len(obj.ToModuleID) + 8 + 4 + len(obj.ObjType) +
+		4 + len(obj.ObjID) + 4 + len(obj.State)*1 + 8 + 8 + 8
+}
+
+func ReadMsgSROCBindVersionByBytes(indata []byte, obj *SROCBindVersion) (int, *SROCBindVersion) {
+	offset := 0
+	if len(indata) < 4 {
+		return 0, nil
+	}
+	objsize := int(binary.LittleEndian.Uint32(indata))
+	offset += 4
+	if objsize == 0 {
+		return 4, nil
+	}
+	if obj == nil {
+		obj = &SROCBindVersion{}
+	}
+	if offset+objsize > len(indata) {
+		return offset, obj
+	}
+	endpos := offset + objsize
+	data := indata[offset : offset+objsize]
+	offset = 0
+	data__len := len(data)
+	if offset+4+len(obj.HostModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.HostModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.HostModuleID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Epoch = binary.LittleEndian.Uint64(data[offset : offset+8])
+	offset += 8
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Version = binary.LittleEndian.Uint64(data[offset : offset+8])
+	offset += 8
+
+	return endpos, obj
+}
+
+func WriteMsgSROCBindVersionByObj(data []byte, obj *SROCBindVersion) int {
+	if obj == nil {
+		binary.LittleEndian.PutUint32(data[0:4], 0)
+		return 4
+	}
+	objsize := obj.GetSize() - 4
+	offset := 0
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(objsize))
+	offset += 4
+	writeBinaryString(data[offset:], obj.HostModuleID)
+	offset += 4 + len(obj.HostModuleID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], obj.Epoch)
+	offset += 8
+	binary.LittleEndian.PutUint64(data[offset:offset+8], obj.Version)
+	offset += 8
+
+	return offset
+}
+
+func GetSizeSROCBindVersion(obj *SROCBindVersion) int {
+	if obj == nil {
+		return 4
+	}
+
+	return 4 + 4 + len(obj.HostModuleID) + 8 + 8
+}
+
+func ReadMsgSROCBindSyncReqByBytes(indata []byte, obj *SROCBindSyncReq) (int, *SROCBindSyncReq) {
+	offset := 0
+	if len(indata) < 4 {
+		return 0, nil
+	}
+	objsize := int(binary.LittleEndian.Uint32(indata))
+	offset += 4
+	if objsize == 0 {
+		return 4, nil
+	}
+	if obj == nil {
+		obj = &SROCBindSyncReq{}
+	}
+	if offset+objsize > len(indata) {
+		return offset, obj
+	}
+	endpos := offset + objsize
+	data := indata[offset : offset+objsize]
+	offset = 0
+	data__len := len(data)
+	if offset+4+len(obj.FromModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.FromModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.FromModuleID)
+	if offset+4+len(obj.HostModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.HostModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.HostModuleID)
+
+	return endpos, obj
+}
+
+func WriteMsgSROCBindSyncReqByObj(data []byte, obj *SROCBindSyncReq) int {
+	if obj == nil {
+		binary.LittleEndian.PutUint32(data[0:4], 0)
+		return 4
+	}
+	objsize := obj.GetSize() - 4
+	offset := 0
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(objsize))
+	offset += 4
+	writeBinaryString(data[offset:], obj.FromModuleID)
+	offset += 4 + len(obj.FromModuleID)
+	writeBinaryString(data[offset:], obj.HostModuleID)
+	offset += 4 + len(obj.HostModuleID)
+
+	return offset
+}
+
+func GetSizeSROCBindSyncReq(obj *SROCBindSyncReq) int {
+	if obj == nil {
+		return 4
+	}
+
+	return 4 + 4 + len(obj.FromModuleID) + 4 + len(obj.HostModuleID)
+}
+
+func ReadMsgSROCBindSyncEndByBytes(indata []byte, obj *SROCBindSyncEnd) (int, *SROCBindSyncEnd) {
+	offset := 0
+	if len(indata) < 4 {
+		return 0, nil
+	}
+	objsize := int(binary.LittleEndian.Uint32(indata))
+	offset += 4
+	if objsize == 0 {
+		return 4, nil
+	}
+	if obj == nil {
+		obj = &SROCBindSyncEnd{}
+	}
+	if offset+objsize > len(indata) {
+		return offset, obj
+	}
+	endpos := offset + objsize
+	data := indata[offset : offset+objsize]
+	offset = 0
+	data__len := len(data)
+	if offset+4+len(obj.HostModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.HostModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.HostModuleID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Epoch = binary.LittleEndian.Uint64(data[offset : offset+8])
+	offset += 8
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Version = binary.LittleEndian.Uint64(data[offset : offset+8])
+	offset += 8
+
+	return endpos, obj
+}
+
+func WriteMsgSROCBindSyncEndByObj(data []byte, obj *SROCBindSyncEnd) int {
+	if obj == nil {
+		binary.LittleEndian.PutUint32(data[0:4], 0)
+		return 4
+	}
+	objsize := obj.GetSize() - 4
+	offset := 0
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(objsize))
+	offset += 4
+	writeBinaryString(data[offset:], obj.HostModuleID)
+	offset += 4 + len(obj.HostModuleID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], obj.Epoch)
+	offset += 8
+	binary.LittleEndian.PutUint64(data[offset:offset+8], obj.Version)
+	offset += 8
+
+	return offset
+}
+
+func GetSizeSROCBindSyncEnd(obj *SROCBindSyncEnd) int {
+	if obj == nil {
+		return 4
+	}
+
+	return 4 + 4 + len(obj.HostModuleID) + 8 + 8
+}
+
+func ReadMsgSROCLocateReqByBytes(indata []byte, obj *SROCLocateReq) (int, *SROCLocateReq) {
+	offset := 0
+	if len(indata) < 4 {
+		return 0, nil
+	}
+	objsize := int(binary.LittleEndian.Uint32(indata))
+	offset += 4
+	if objsize == 0 {
+		return 4, nil
+	}
+	if obj == nil {
+		obj = &SROCLocateReq{}
+	}
+	if offset+objsize > len(indata) {
+		return offset, obj
+	}
+	endpos := offset + objsize
+	data := indata[offset : offset+objsize]
+	offset = 0
+	data__len := len(data)
+	if offset+4+len(obj.FromModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.FromModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.FromModuleID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Seq = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+4+len(obj.ObjType) > data__len {
+		return endpos, obj
+	}
+	obj.ObjType = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ObjType)
+	if offset+4+len(obj.ObjID) > data__len {
+		return endpos, obj
+	}
+	obj.ObjID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ObjID)
+
+	return endpos, obj
+}
+
+func WriteMsgSROCLocateReqByObj(data []byte, obj *SROCLocateReq) int {
+	if obj == nil {
+		binary.LittleEndian.PutUint32(data[0:4], 0)
+		return 4
+	}
+	objsize := obj.GetSize() - 4
+	offset := 0
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(objsize))
+	offset += 4
+	writeBinaryString(data[offset:], obj.FromModuleID)
+	offset += 4 + len(obj.FromModuleID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.Seq))
+	offset += 8
+	writeBinaryString(data[offset:], obj.ObjType)
+	offset += 4 + len(obj.ObjType)
+	writeBinaryString(data[offset:], obj.ObjID)
+	offset += 4 + len(obj.ObjID)
+
+	return offset
+}
+
+func GetSizeSROCLocateReq(obj *SROCLocateReq) int {
+	if obj == nil {
+		return 4
+	}
+
+	return 4 + 4 + len(obj.FromModuleID) + 8 + 4 + len(obj.ObjType) + 4 + len(obj.ObjID)
+}
+
+func ReadMsgSROCLocateResByBytes(indata []byte, obj *SROCLocateRes) (int, *SROCLocateRes) {
+	offset := 0
+	if len(indata) < 4 {
+		return 0, nil
+	}
+	objsize := int(binary.LittleEndian.Uint32(indata))
+	offset += 4
+	if objsize == 0 {
+		return 4, nil
+	}
+	if obj == nil {
+		obj = &SROCLocateRes{}
+	}
+	if offset+objsize > len(indata) {
+		return offset, obj
+	}
+	endpos := offset + objsize
+	data := indata[offset : offset+objsize]
+	offset = 0
+	data__len := len(data)
+	if offset+4+len(obj.FromModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.FromModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.FromModuleID)
+	if offset+4+len(obj.ToModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.ToModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ToModuleID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Seq = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+4+len(obj.ObjType) > data__len {
+		return endpos, obj
+	}
+	obj.ObjType = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ObjType)
+	if offset+4+len(obj.ObjID) > data__len {
+		return endpos, obj
+	}
+	obj.ObjID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ObjID)
+	if offset+4+len(obj.HostModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.HostModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.HostModuleID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Token = binary.LittleEndian.Uint64(data[offset : offset+8])
+	offset += 8
+	if offset+1 > data__len {
+		return endpos, obj
+	}
+	obj.Activatable = uint8(data[offset]) != 0
+	offset += 1
+
+	return endpos, obj
+}
+
+func WriteMsgSROCLocateResByObj(data []byte, obj *SROCLocateRes) int {
+	if obj == nil {
+		binary.LittleEndian.PutUint32(data[0:4], 0)
+		return 4
+	}
+	objsize := obj.GetSize() - 4
+	offset := 0
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(objsize))
+	offset += 4
+	writeBinaryString(data[offset:], obj.FromModuleID)
+	offset += 4 + len(obj.FromModuleID)
+	writeBinaryString(data[offset:], obj.ToModuleID)
+	offset += 4 + len(obj.ToModuleID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.Seq))
+	offset += 8
+	writeBinaryString(data[offset:], obj.ObjType)
+	offset += 4 + len(obj.ObjType)
+	writeBinaryString(data[offset:], obj.ObjID)
+	offset += 4 + len(obj.ObjID)
+	writeBinaryString(data[offset:], obj.HostModuleID)
+	offset += 4 + len(obj.HostModuleID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], obj.Token)
+	offset += 8
+	data[offset] = uint8(bool2int(obj.Activatable))
+	offset += 1
+
+	return offset
+}
+
+func GetSizeSROCLocateRes(obj *SROCLocateRes) int {
+	if obj == nil {
+		return 4
+	}
+
+	return 4 + 4 + len(obj.FromModuleID) + 4 + len(obj.ToModuleID) + 8 + 4 + len(obj.ObjType) +
+		4 + len(obj.ObjID) + 4 + len(obj.HostModuleID) + 8 + 1
+}
+
+func ReadMsgSROCMulticastByBytes(indata []byte, obj *SROCMulticast) (int, *SROCMulticast) {
+	offset := 0
+	if len(indata) < 4 {
+		return 0, nil
+	}
+	objsize := int(binary.LittleEndian.Uint32(indata))
+	offset += 4
+	if objsize == 0 {
+		return 4, nil
+	}
+	if obj == nil {
+		obj = &SROCMulticast{}
+	}
+	if offset+objsize > len(indata) {
+		return offset, obj
+	}
+	endpos := offset + objsize
+	data := indata[offset : offset+objsize]
+	offset = 0
+	data__len := len(data)
+	if offset+4+len(obj.FromModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.FromModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.FromModuleID)
+	if offset+4+len(obj.ToModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.ToModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ToModuleID)
+	if offset+4+len(obj.ObjType) > data__len {
+		return endpos, obj
+	}
+	obj.ObjType = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ObjType)
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	ObjIDs_slen := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if ObjIDs_slen != 0xffffffff {
+		obj.ObjIDs = make([]string, ObjIDs_slen)
+		for i4i := 0; ObjIDs_slen > i4i; i4i++ {
+			if offset+4 > data__len {
+				return endpos, obj
+			}
+			obj.ObjIDs[i4i] = readBinaryString(data[offset:])
+			offset += 4 + len(obj.ObjIDs[i4i])
+		}
+	}
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	Seqs_slen := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if Seqs_slen != 0xffffffff {
+		if offset+(Seqs_slen*8) > data__len {
+			return endpos, obj
+		}
+		obj.Seqs = make([]int64, Seqs_slen)
+		for i5i := 0; Seqs_slen > i5i; i5i++ {
+			obj.Seqs[i5i] = int64(int64(binary.LittleEndian.Uint64(data[offset : offset+8])))
+			offset += 8
+		}
+	}
+	if offset+4+len(obj.CallFunc) > data__len {
+		return endpos, obj
+	}
+	obj.CallFunc = readBinaryString(data[offset:])
+	offset += 4 + len(obj.CallFunc)
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	CallArg_slen := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if CallArg_slen != 0xffffffff {
+		if offset+CallArg_slen > data__len {
+			return endpos, obj
+		}
+		obj.CallArg = make([]byte, CallArg_slen)
+		copy(obj.CallArg, data[offset:offset+CallArg_slen])
+		offset += CallArg_slen
+	}
+	if offset+1 > data__len {
+		return endpos, obj
+	}
+	obj.NeedReturn = uint8(data[offset]) != 0
+	offset += 1
+	if offset+4+len(obj.TraceID) > data__len {
+		return endpos, obj
+	}
+	obj.TraceID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.TraceID)
+	if offset+4+len(obj.SpanID) > data__len {
+		return endpos, obj
+	}
+	obj.SpanID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.SpanID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.OrderSession = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.OrderSeq = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+
+	return endpos, obj
+}
+
+func WriteMsgSROCMulticastByObj(data []byte, obj *SROCMulticast) int {
+	if obj == nil {
+		binary.LittleEndian.PutUint32(data[0:4], 0)
+		return 4
+	}
+	objsize := obj.GetSize() - 4
+	offset := 0
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(objsize))
+	offset += 4
+	writeBinaryString(data[offset:], obj.FromModuleID)
+	offset += 4 + len(obj.FromModuleID)
+	writeBinaryString(data[offset:], obj.ToModuleID)
+	offset += 4 + len(obj.ToModuleID)
+	writeBinaryString(data[offset:], obj.ObjType)
+	offset += 4 + len(obj.ObjType)
+	if obj.ObjIDs == nil {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], 0xffffffff)
+	} else {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(len(obj.ObjIDs)))
+	}
+	offset += 4
+	ObjIDs_slen := len(obj.ObjIDs)
+	for i4i := 0; ObjIDs_slen > i4i; i4i++ {
+		writeBinaryString(data[offset:], obj.ObjIDs[i4i])
+		offset += 4 + len(obj.ObjIDs[i4i])
+	}
+	if obj.Seqs == nil {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], 0xffffffff)
+	} else {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(len(obj.Seqs)))
+	}
+	offset += 4
+	Seqs_slen := len(obj.Seqs)
+	for i5i := 0; Seqs_slen > i5i; i5i++ {
+		writeBinaryInt64(data[offset:offset+8], obj.Seqs[i5i])
+		offset += 8
+	}
+	writeBinaryString(data[offset:], obj.CallFunc)
+	offset += 4 + len(obj.CallFunc)
+	if obj.CallArg == nil {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], 0xffffffff)
+	} else {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(len(obj.CallArg)))
+	}
+	offset += 4
+	CallArg_slen := len(obj.CallArg)
+	copy(data[offset:offset+CallArg_slen], obj.CallArg)
+	offset += CallArg_slen
+	data[offset] = uint8(bool2int(obj.NeedReturn))
+	offset += 1
+	writeBinaryString(data[offset:], obj.TraceID)
+	offset += 4 + len(obj.TraceID)
+	writeBinaryString(data[offset:], obj.SpanID)
+	offset += 4 + len(obj.SpanID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.OrderSession))
+	offset += 8
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.OrderSeq))
+	offset += 8
+
+	return offset
+}
+
+func GetSizeSROCMulticast(obj *SROCMulticast) int {
+	if obj == nil {
+		return 4
+	}
+	sizerelystring4 := 0
+	i4i := 0
+	ObjIDs_slen := len(obj.ObjIDs)
+	for ObjIDs_slen > i4i {
+		sizerelystring4 += len(obj.ObjIDs[i4i]) + 4
+		i4i++
+	}
+
+	return 4 + 4 + len(obj.FromModuleID) + 4 + len(obj.ToModuleID) + 4 + len(obj.ObjType) + 4 + sizerelystring4 +
+		4 + len(obj.Seqs)*8 + 4 + len(obj.CallFunc) + 4 + len(obj.CallArg)*1 + 1 + 4 + len(obj.TraceID) +
+		4 + len(obj.SpanID) + 8 + 8
+}
+
+func ReadMsgSROCStreamFrameByBytes(indata []byte, obj *SROCStreamFrame) (int, *SROCStreamFrame) {
+	offset := 0
+	if len(indata) < 4 {
+		return 0, nil
+	}
+	objsize := int(binary.LittleEndian.Uint32(indata))
+	offset += 4
+	if objsize == 0 {
+		return 4, nil
+	}
+	if obj == nil {
+		obj = &SROCStreamFrame{}
+	}
+	if offset+objsize > len(indata) {
+		return offset, obj
+	}
+	endpos := offset + objsize
+	data := indata[offset : offset+objsize]
+	offset = 0
+	data__len := len(data)
+	if offset+4+len(obj.FromModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.FromModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.FromModuleID)
+	if offset+4+len(obj.ToModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.ToModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ToModuleID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Seq = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	Data_slen := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if Data_slen != 0xffffffff {
+		if offset+Data_slen > data__len {
+			return endpos, obj
+		}
+		obj.Data = make([]byte, Data_slen)
+		copy(obj.Data, data[offset:offset+Data_slen])
+		offset += Data_slen
+	}
+	if offset+1 > data__len {
+		return endpos, obj
+	}
+	obj.End = uint8(data[offset]) != 0
+	offset += 1
+	if offset+4+len(obj.Error) > data__len {
+		return endpos, obj
+	}
+	obj.Error = readBinaryString(data[offset:])
+	offset += 4 + len(obj.Error)
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	obj.ErrCode = int32(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	ErrDetails_slen := binary.LittleEndian.Uint32(data[offset : offset+4])
+	offset += 4
+	if ErrDetails_slen != 0xffffffff {
+		obj.ErrDetails = make(map[string]string)
+		for i8i := uint32(0); i8i < ErrDetails_slen; i8i++ {
+			if offset+0 > data__len {
+				return endpos, obj
+			}
+			keyErrDetails := readBinaryString(data[offset:])
+			ErrDetails_kcatlen := len(keyErrDetails)
+			offset += ErrDetails_kcatlen + 4
+			if offset+2 > data__len {
+				return endpos, obj
+			}
+			valueErrDetails := readBinaryString(data[offset:])
+			ErrDetails_vcatlen := len(valueErrDetails)
+			offset += ErrDetails_vcatlen + 4
+			obj.ErrDetails[keyErrDetails] = valueErrDetails
+		}
+	}
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.OrderSession = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.OrderSeq = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+
+	return endpos, obj
+}
+
+func WriteMsgSROCStreamFrameByObj(data []byte, obj *SROCStreamFrame) int {
+	if obj == nil {
+		binary.LittleEndian.PutUint32(data[0:4], 0)
+		return 4
+	}
+	objsize := obj.GetSize() - 4
+	offset := 0
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(objsize))
+	offset += 4
+	writeBinaryString(data[offset:], obj.FromModuleID)
+	offset += 4 + len(obj.FromModuleID)
+	writeBinaryString(data[offset:], obj.ToModuleID)
+	offset += 4 + len(obj.ToModuleID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.Seq))
+	offset += 8
+	if obj.Data == nil {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], 0xffffffff)
+	} else {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(len(obj.Data)))
+	}
+	offset += 4
+	Data_slen := len(obj.Data)
+	copy(data[offset:offset+Data_slen], obj.Data)
+	offset += Data_slen
+	data[offset] = uint8(bool2int(obj.End))
+	offset += 1
+	writeBinaryString(data[offset:], obj.Error)
+	offset += 4 + len(obj.Error)
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(obj.ErrCode))
+	offset += 4
+	if obj.ErrDetails == nil {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], 0xffffffff)
+	} else {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(len(obj.ErrDetails)))
+	}
+	offset += 4
+	for ErrDetailskey, ErrDetailsvalue := range obj.ErrDetails {
+		ErrDetails_kcatlen := writeBinaryString(data[offset:], ErrDetailskey)
+		offset += ErrDetails_kcatlen
+		ErrDetails_vcatlen := writeBinaryString(data[offset:], ErrDetailsvalue)
+		offset += ErrDetails_vcatlen
+	}
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.OrderSession))
+	offset += 8
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.OrderSeq))
+	offset += 8
+
+	return offset
+}
+
+func GetSizeSROCStreamFrame(obj *SROCStreamFrame) int {
+	if obj == nil {
+		return 4
+	}
+	sizerelystring8 := 0
+	for ErrDetailsvalue, ErrDetailskey := range obj.ErrDetails {
+		sizerelystring8 += len(ErrDetailsvalue) + 4
+		sizerelystring8 += len(ErrDetailskey) + 4
+	}
+
+	return 4 + 4 + len(obj.FromModuleID) + 4 + len(obj.ToModuleID) + 8 + 4 + len(obj.Data)*1 +
+		1 + 4 + len(obj.Error) + 4 + 4 + sizerelystring8 + 8 +
+		8
+}
+
+func ReadMsgSROCStreamCtrlByBytes(indata []byte, obj *SROCStreamCtrl) (int, *SROCStreamCtrl) {
+	offset := 0
+	if len(indata) < 4 {
+		return 0, nil
+	}
+	objsize := int(binary.LittleEndian.Uint32(indata))
+	offset += 4
+	if objsize == 0 {
+		return 4, nil
+	}
+	if obj == nil {
+		obj = &SROCStreamCtrl{}
+	}
+	if offset+objsize > len(indata) {
+		return offset, obj
+	}
+	endpos := offset + objsize
+	data := indata[offset : offset+objsize]
+	offset = 0
+	data__len := len(data)
+	if offset+4+len(obj.FromModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.FromModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.FromModuleID)
+	if offset+4+len(obj.ToModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.ToModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ToModuleID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Seq = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	obj.Credit = int32(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if offset+1 > data__len {
+		return endpos, obj
+	}
+	obj.Cancel = uint8(data[offset]) != 0
+	offset += 1
+
+	return endpos, obj
+}
+
+func WriteMsgSROCStreamCtrlByObj(data []byte, obj *SROCStreamCtrl) int {
+	if obj == nil {
+		binary.LittleEndian.PutUint32(data[0:4], 0)
+		return 4
+	}
+	objsize := obj.GetSize() - 4
+	offset := 0
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(objsize))
+	offset += 4
+	writeBinaryString(data[offset:], obj.FromModuleID)
+	offset += 4 + len(obj.FromModuleID)
+	writeBinaryString(data[offset:], obj.ToModuleID)
+	offset += 4 + len(obj.ToModuleID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.Seq))
+	offset += 8
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(obj.Credit))
+	offset += 4
+	data[offset] = uint8(bool2int(obj.Cancel))
+	offset += 1
+
+	return offset
+}
+
+func GetSizeSROCStreamCtrl(obj *SROCStreamCtrl) int {
+	if obj == nil {
+		return 4
+	}
+
+	return 4 + 4 + len(obj.FromModuleID) + 4 + len(obj.ToModuleID) + 8 + 4 +
+		1
+}
+
+func ReadMsgSROCActivateReqByBytes(indata []byte, obj *SROCActivateReq) (int, *SROCActivateReq) {
+	offset := 0
+	if len(indata) < 4 {
+		return 0, nil
+	}
+	objsize := int(binary.LittleEndian.Uint32(indata))
+	offset += 4
+	if objsize == 0 {
+		return 4, nil
+	}
+	if obj == nil {
+		obj = &SROCActivateReq{}
+	}
+	if offset+objsize > len(indata) {
+		return offset, obj
+	}
+	endpos := offset + objsize
+	data := indata[offset : offset+objsize]
+	offset = 0
+	data__len := len(data)
+	if offset+4+len(obj.FromModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.FromModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.FromModuleID)
+	if offset+4+len(obj.ToModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.ToModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ToModuleID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Seq = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+4+len(obj.ObjType) > data__len {
+		return endpos, obj
+	}
+	obj.ObjType = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ObjType)
+	if offset+4+len(obj.ObjID) > data__len {
+		return endpos, obj
+	}
+	obj.ObjID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ObjID)
+
+	return endpos, obj
+}
+
+func WriteMsgSROCActivateReqByObj(data []byte, obj *SROCActivateReq) int {
+	if obj == nil {
+		binary.LittleEndian.PutUint32(data[0:4], 0)
+		return 4
+	}
+	objsize := obj.GetSize() - 4
+	offset := 0
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(objsize))
+	offset += 4
+	writeBinaryString(data[offset:], obj.FromModuleID)
+	offset += 4 + len(obj.FromModuleID)
+	writeBinaryString(data[offset:], obj.ToModuleID)
+	offset += 4 + len(obj.ToModuleID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.Seq))
+	offset += 8
+	writeBinaryString(data[offset:], obj.ObjType)
+	offset += 4 + len(obj.ObjType)
+	writeBinaryString(data[offset:], obj.ObjID)
+	offset += 4 + len(obj.ObjID)
+
+	return offset
+}
+
+func GetSizeSROCActivateReq(obj *SROCActivateReq) int {
+	if obj == nil {
+		return 4
+	}
+
+	return 4 + 4 + len(obj.FromModuleID) + 4 + len(obj.ToModuleID) + 8 + 4 + len(obj.ObjType) +
+		4 + len(obj.ObjID)
+}
+
+func ReadMsgSROCActivateResByBytes(indata []byte, obj *SROCActivateRes) (int, *SROCActivateRes) {
+	offset := 0
+	if len(indata) < 4 {
+		return 0, nil
+	}
+	objsize := int(binary.LittleEndian.Uint32(indata))
+	offset += 4
+	if objsize == 0 {
+		return 4, nil
+	}
+	if obj == nil {
+		obj = &SROCActivateRes{}
+	}
+	if offset+objsize > len(indata) {
+		return offset, obj
+	}
+	endpos := offset + objsize
+	data := indata[offset : offset+objsize]
+	offset = 0
+	data__len := len(data)
+	if offset+4+len(obj.FromModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.FromModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.FromModuleID)
+	if offset+4+len(obj.ToModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.ToModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ToModuleID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Seq = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+4+len(obj.ObjType) > data__len {
+		return endpos, obj
+	}
+	obj.ObjType = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ObjType)
+	if offset+4+len(obj.ObjID) > data__len {
+		return endpos, obj
+	}
+	obj.ObjID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ObjID)
+	if offset+4+len(obj.HostModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.HostModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.HostModuleID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Token = binary.LittleEndian.Uint64(data[offset : offset+8])
+	offset += 8
+	if offset+4+len(obj.Error) > data__len {
+		return endpos, obj
+	}
+	obj.Error = readBinaryString(data[offset:])
+	offset += 4 + len(obj.Error)
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	obj.ErrCode = int32(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+
+	return endpos, obj
+}
+
+func WriteMsgSROCActivateResByObj(data []byte, obj *SROCActivateRes) int {
+	if obj == nil {
+		binary.LittleEndian.PutUint32(data[0:4], 0)
+		return 4
+	}
+	objsize := obj.GetSize() - 4
+	offset := 0
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(objsize))
+	offset += 4
+	writeBinaryString(data[offset:], obj.FromModuleID)
+	offset += 4 + len(obj.FromModuleID)
+	writeBinaryString(data[offset:], obj.ToModuleID)
+	offset += 4 + len(obj.ToModuleID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.Seq))
+	offset += 8
+	writeBinaryString(data[offset:], obj.ObjType)
+	offset += 4 + len(obj.ObjType)
+	writeBinaryString(data[offset:], obj.ObjID)
+	offset += 4 + len(obj.ObjID)
+	writeBinaryString(data[offset:], obj.HostModuleID)
+	offset += 4 + len(obj.HostModuleID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], obj.Token)
+	offset += 8
+	writeBinaryString(data[offset:], obj.Error)
+	offset += 4 + len(obj.Error)
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(obj.ErrCode))
+	offset += 4
+
+	return offset
+}
+
+func GetSizeSROCActivateRes(obj *SROCActivateRes) int {
+	if obj == nil {
+		return 4
+	}
+
+	return 4 + 4 + len(obj.FromModuleID) + 4 + len(obj.ToModuleID) + 8 + 4 + len(obj.ObjType) +
+		4 + len(obj.ObjID) + 4 + len(obj.HostModuleID) + 8 + 4 + len(obj.Error) + 4
+}
+
+func ReadMsgSROCStatsReqByBytes(indata []byte, obj *SROCStatsReq) (int, *SROCStatsReq) {
+	offset := 0
+	if len(indata) < 4 {
+		return 0, nil
+	}
+	objsize := int(binary.LittleEndian.Uint32(indata))
+	offset += 4
+	if objsize == 0 {
+		return 4, nil
+	}
+	if obj == nil {
+		obj = &SROCStatsReq{}
+	}
+	if offset+objsize > len(indata) {
+		return offset, obj
+	}
+	endpos := offset + objsize
+	data := indata[offset : offset+objsize]
+	offset = 0
+	data__len := len(data)
+	if offset+4+len(obj.FromModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.FromModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.FromModuleID)
+	if offset+4+len(obj.ToModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.ToModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ToModuleID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Seq = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+4+len(obj.ObjType) > data__len {
+		return endpos, obj
+	}
+	obj.ObjType = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ObjType)
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	obj.SampleNum = int32(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+
+	return endpos, obj
+}
+
+func WriteMsgSROCStatsReqByObj(data []byte, obj *SROCStatsReq) int {
+	if obj == nil {
+		binary.LittleEndian.PutUint32(data[0:4], 0)
+		return 4
+	}
+	objsize := obj.GetSize() - 4
+	offset := 0
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(objsize))
+	offset += 4
+	writeBinaryString(data[offset:], obj.FromModuleID)
+	offset += 4 + len(obj.FromModuleID)
+	writeBinaryString(data[offset:], obj.ToModuleID)
+	offset += 4 + len(obj.ToModuleID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.Seq))
+	offset += 8
+	writeBinaryString(data[offset:], obj.ObjType)
+	offset += 4 + len(obj.ObjType)
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(obj.SampleNum))
+	offset += 4
+
+	return offset
+}
+
+func GetSizeSROCStatsReq(obj *SROCStatsReq) int {
+	if obj == nil {
+		return 4
+	}
+
+	return 4 + 4 + len(obj.FromModuleID) + 4 + len(obj.ToModuleID) + 8 + 4 + len(obj.ObjType) +
+		4
+}
+
+func ReadMsgSROCStatsResByBytes(indata []byte, obj *SROCStatsRes) (int, *SROCStatsRes) {
+	offset := 0
+	if len(indata) < 4 {
+		return 0, nil
+	}
+	objsize := int(binary.LittleEndian.Uint32(indata))
+	offset += 4
+	if objsize == 0 {
+		return 4, nil
+	}
+	if obj == nil {
+		obj = &SROCStatsRes{}
+	}
+	if offset+objsize > len(indata) {
+		return offset, obj
+	}
+	endpos := offset + objsize
+	data := indata[offset : offset+objsize]
+	offset = 0
+	data__len := len(data)
+	if offset+4+len(obj.FromModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.FromModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.FromModuleID)
+	if offset+4+len(obj.ToModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.ToModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ToModuleID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Seq = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	Stats_slen := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if Stats_slen != 0xffffffff {
+		if offset+Stats_slen > data__len {
+			return endpos, obj
+		}
+		obj.Stats = make([]byte, Stats_slen)
+		copy(obj.Stats, data[offset:offset+Stats_slen])
+		offset += Stats_slen
+	}
+
+	return endpos, obj
+}
+
+func WriteMsgSROCStatsResByObj(data []byte, obj *SROCStatsRes) int {
+	if obj == nil {
+		binary.LittleEndian.PutUint32(data[0:4], 0)
+		return 4
+	}
+	objsize := obj.GetSize() - 4
+	offset := 0
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(objsize))
+	offset += 4
+	writeBinaryString(data[offset:], obj.FromModuleID)
+	offset += 4 + len(obj.FromModuleID)
+	writeBinaryString(data[offset:], obj.ToModuleID)
+	offset += 4 + len(obj.ToModuleID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.Seq))
+	offset += 8
+	if obj.Stats == nil {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], 0xffffffff)
+	} else {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(len(obj.Stats)))
+	}
+	offset += 4
+	Stats_slen := len(obj.Stats)
+	copy(data[offset:offset+Stats_slen], obj.Stats)
+	offset += Stats_slen
+
+	return offset
+}
+
+func GetSizeSROCStatsRes(obj *SROCStatsRes) int {
+	if obj == nil {
+		return 4
+	}
+
+	return 4 + 4 + len(obj.FromModuleID) + 4 + len(obj.ToModuleID) + 8 + 4 + len(obj.Stats)*1
+}
+
+func ReadMsgSROCChunkByBytes(indata []byte, obj *SROCChunk) (int, *SROCChunk) {
+	offset := 0
+	if len(indata) < 4 {
+		return 0, nil
+	}
+	objsize := int(binary.LittleEndian.Uint32(indata))
+	offset += 4
+	if objsize == 0 {
+		return 4, nil
+	}
+	if obj == nil {
+		obj = &SROCChunk{}
+	}
+	if offset+objsize > len(indata) {
+		return offset, obj
+	}
+	endpos := offset + objsize
+	data := indata[offset : offset+objsize]
+	offset = 0
+	data__len := len(data)
+	if offset+4+len(obj.FromModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.FromModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.FromModuleID)
+	if offset+4+len(obj.ToModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.ToModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.ToModuleID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.TransferID = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+2 > data__len {
+		return endpos, obj
+	}
+	obj.MsgID = binary.LittleEndian.Uint16(data[offset : offset+2])
+	offset += 2
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.TotalSize = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.PayloadSize = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	obj.Index = int32(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	obj.Total = int32(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.Offset = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+4 > data__len {
+		return endpos, obj
+	}
+	Data_slen := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if Data_slen != 0xffffffff {
+		if offset+Data_slen > data__len {
+			return endpos, obj
+		}
+		obj.Data = make([]byte, Data_slen)
+		copy(obj.Data, data[offset:offset+Data_slen])
+		offset += Data_slen
+	}
+	if offset+4+len(obj.CallerModuleID) > data__len {
+		return endpos, obj
+	}
+	obj.CallerModuleID = readBinaryString(data[offset:])
+	offset += 4 + len(obj.CallerModuleID)
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.CallSeq = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.OrderSession = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+	if offset+8 > data__len {
+		return endpos, obj
+	}
+	obj.OrderSeq = int64(binary.LittleEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+
+	return endpos, obj
+}
+
+func WriteMsgSROCChunkByObj(data []byte, obj *SROCChunk) int {
+	if obj == nil {
+		binary.LittleEndian.PutUint32(data[0:4], 0)
+		return 4
+	}
+	objsize := obj.GetSize() - 4
+	offset := 0
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(objsize))
+	offset += 4
+	writeBinaryString(data[offset:], obj.FromModuleID)
+	offset += 4 + len(obj.FromModuleID)
+	writeBinaryString(data[offset:], obj.ToModuleID)
+	offset += 4 + len(obj.ToModuleID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.TransferID))
+	offset += 8
+	binary.LittleEndian.PutUint16(data[offset:offset+2], obj.MsgID)
+	offset += 2
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.TotalSize))
+	offset += 8
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.PayloadSize))
+	offset += 8
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(obj.Index))
+	offset += 4
+	binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(obj.Total))
+	offset += 4
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.Offset))
+	offset += 8
+	if obj.Data == nil {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], 0xffffffff)
+	} else {
+		binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(len(obj.Data)))
+	}
+	offset += 4
+	Data_slen := len(obj.Data)
+	copy(data[offset:offset+Data_slen], obj.Data)
+	offset += Data_slen
+	writeBinaryString(data[offset:], obj.CallerModuleID)
+	offset += 4 + len(obj.CallerModuleID)
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.CallSeq))
+	offset += 8
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.OrderSession))
+	offset += 8
+	binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(obj.OrderSeq))
+	offset += 8
+
+	return offset
+}
+
+func GetSizeSROCChunk(obj *SROCChunk) int {
+	if obj == nil {
+		return 4
+	}
+
+	return 4 + 4 + len(obj.FromModuleID) + 4 + len(obj.ToModuleID) + 8 + 2 +
+		8 + 8 + 4 + 4 + 8 +
+		4 + len(obj.Data)*1 + 4 + len(obj.CallerModuleID) + 8 + 8 + 8
 }
@@ -0,0 +1,27 @@
+package servercomm
+
+import (
+	"context"
+
+	"github.com/liasece/micserver/trace"
+)
+
+// 获取携带了该消息调用链追踪信息的 context ，处理该消息时发起的ROC调用及模块间消息
+// 使用该 context 即可延续调用链
+func (this *ModuleMessage) Context() context.Context {
+	return trace.ContextWithSpanContext(context.Background(),
+		trace.SpanContext{
+			TraceID: this.TraceID,
+			SpanID:  this.SpanID,
+		})
+}
+
+// 获取携带了该消息调用链追踪信息的 context ，处理该消息时发起的ROC调用及模块间消息
+// 使用该 context 即可延续调用链
+func (this *ClientMessage) Context() context.Context {
+	return trace.ContextWithSpanContext(context.Background(),
+		trace.SpanContext{
+			TraceID: this.TraceID,
+			SpanID:  this.SpanID,
+		})
+}
@@ -337,6 +337,15 @@ func writeBinaryInt8(data []byte, num int8) {\n\
     // 大端模式\n\
     data[0] = byte(num)\n\
 }\n\n\
+func writeBinaryInt16(data []byte, num int16) {\n\
+    binary.LittleEndian.PutUint16(data, uint16(num))\n\
+}\n\n\
+func writeBinaryInt32(data []byte, num int32) {\n\
+    binary.LittleEndian.PutUint32(data, uint32(num))\n\
+}\n\n\
+func writeBinaryInt64(data []byte, num int64) {\n\
+    binary.LittleEndian.PutUint64(data, uint64(num))\n\
+}\n\n\
 func readBinaryBool(data []byte) bool {\n\
     // 大端模式\n\
     num := int8(0)\n\
@@ -619,6 +628,27 @@ def getgoslice(typestr,jsonname,fieldnum):
                     copy(data[offset:offset+'+jsonname+'_slen], obj.'+jsonname+')\n\
                     offset += '+jsonname+'_slen\n\
                     '
+            elif subtype == 'string':
+                # 字符串为变长类型，每个元素需要按自身的长度移动偏移
+                read += '\
+                    if '+jsonname+'_slen != 0xffffffff {\n\
+                        obj.'+jsonname+' = make('+typestr+','+jsonname+'_slen)\n\
+                        for i'+str(fieldnum)+'i := 0; '+jsonname+'_slen > i'+str(fieldnum)+'i; i'+str(fieldnum)+'i++ {\n\
+                            if offset + 4 > data__len {\n\
+                                return endpos,obj\n\
+                            }\n\
+                            obj.'+jsonname+'[i'+str(fieldnum)+'i] = readBinaryString(data[offset:])\n\
+                            offset += 4 + len(obj.'+jsonname+'[i'+str(fieldnum)+'i])\n\
+                        }\n\
+                    }\n\
+                    '
+                send += '\
+                    '+jsonname+'_slen := len(obj.'+jsonname+')\n\
+                    for i'+str(fieldnum)+'i := 0; '+jsonname+'_slen > i'+str(fieldnum)+'i; i'+str(fieldnum)+'i++ {\n\
+                        writeBinaryString(data[offset:],obj.'+jsonname+'[i'+str(fieldnum)+'i])\n\
+                        offset += 4 + len(obj.'+jsonname+'[i'+str(fieldnum)+'i])\n\
+                    }\n\
+                    '
             else:
                 read += '\
                     if '+jsonname+'_slen != 0xffffffff {\n\
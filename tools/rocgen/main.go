@@ -0,0 +1,448 @@
+/*
+rocgen 根据 Go 接口声明生成 ROC 调用的客户端桩代码以及服务端适配器。
+生成的代码使用 rocutil/codec 的二进制格式编码参数及返回值，不使用反射。
+
+使用方法，在接口所在的文件中添加：
+
+	//go:generate go run github.com/liasece/micserver/tools/rocgen -type Room
+
+将生成 room_roc.go ，其中包括：
+
+	RoomROCClient  客户端桩代码，通过 roc.IROCServer 发起调用
+	RoomROCAdapter 服务端适配器，实现了 roc.IObj 接口，将调用转发给接口的实现
+
+生成规则：
+
+	没有返回值的方法，客户端使用 ROCCallNR 发起调用，并返回一个 error ；
+	有返回值的方法，客户端使用 ROCCallBlock 阻塞调用；
+	如果方法的第一个参数是 context.Context ，则分别使用 ROCCallNRContext 及
+	ROCCallContext ，该参数不会被传输，服务端适配器将传入调用路径的 context ，
+	其中携带了调用链追踪信息；
+	客户端方法总是以 error 作为最后一个返回值，接口方法的最后一个返回值是 error 时，
+	服务端返回的该错误将作为客户端方法的错误返回；
+	客户端方法沿用接口中的参数名，没有参数名或参数名与生成的代码冲突时
+	使用 a0 、 a1 等名称。
+
+参数及返回值仅支持基础类型、 string 及 []byte 。
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 支持的类型对应的 codec.BinaryWriter / codec.BinaryReader 方法后缀
+var _gTypeMethods = map[string]string{
+	"bool":    "Bool",
+	"int8":    "Int8",
+	"uint8":   "Uint8",
+	"byte":    "Uint8",
+	"int16":   "Int16",
+	"uint16":  "Uint16",
+	"int32":   "Int32",
+	"uint32":  "Uint32",
+	"int64":   "Int64",
+	"uint64":  "Uint64",
+	"int":     "Int",
+	"uint":    "Uint",
+	"float32": "Float32",
+	"float64": "Float64",
+	"string":  "String",
+	"[]byte":  "Bytes",
+}
+
+// 一个参数或返回值
+type field struct {
+	name   string
+	typ    string
+	method string
+}
+
+// 生成的代码中使用的标识符，参数不能使用这些名称
+var _gReservedNames = map[string]bool{
+	"this": true, "ctx": true, "path": true, "arg": true, "funcName": true,
+	"w": true, "r": true, "res": true, "err": true,
+	"roc": true, "codec": true, "context": true,
+}
+
+// 接口中的一个方法
+type method struct {
+	name       string
+	hasContext bool
+	params     []*field
+	results    []*field
+	returnErr  bool
+}
+
+func main() {
+	typeName := flag.String("type", "", "interface type name")
+	input := flag.String("input", "", "input file, default $GOFILE")
+	output := flag.String("output", "", "output file, default <type>_roc.go")
+	flag.Parse()
+
+	if *typeName == "" {
+		fatal("-type must be set")
+	}
+	if *input == "" {
+		*input = os.Getenv("GOFILE")
+	}
+	if *input == "" {
+		fatal("-input must be set when not run by go generate")
+	}
+	if *output == "" {
+		*output = filepath.Join(filepath.Dir(*input),
+			strings.ToLower(*typeName)+"_roc.go")
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, *input, nil, 0)
+	if err != nil {
+		fatal("parse %s: %s", *input, err.Error())
+	}
+	methods, err := loadInterface(file, *typeName)
+	if err != nil {
+		fatal("%s", err.Error())
+	}
+	src, err := generate(file.Name.Name, *typeName, methods)
+	if err != nil {
+		fatal("%s", err.Error())
+	}
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		fatal("write %s: %s", *output, err.Error())
+	}
+}
+
+func fatal(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "rocgen: "+format+"\n", args...)
+	os.Exit(1)
+}
+
+// 从文件中找到目标接口的声明，并解析其中的方法
+func loadInterface(file *ast.File, typeName string) ([]*method, error) {
+	var iface *ast.InterfaceType
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != typeName {
+			return true
+		}
+		if it, ok := spec.Type.(*ast.InterfaceType); ok {
+			iface = it
+		}
+		return false
+	})
+	if iface == nil {
+		return nil, fmt.Errorf("interface %s not found", typeName)
+	}
+	res := make([]*method, 0)
+	for _, m := range iface.Methods.List {
+		ftype, ok := m.Type.(*ast.FuncType)
+		if !ok || len(m.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded interfaces are not supported",
+				typeName)
+		}
+		newMethod, err := loadMethod(m.Names[0].Name, ftype)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, newMethod)
+	}
+	return res, nil
+}
+
+// 解析一个方法的参数及返回值
+func loadMethod(name string, ftype *ast.FuncType) (*method, error) {
+	res := &method{
+		name: name,
+	}
+	params, names := flattenFields(ftype.Params)
+	results, _ := flattenFields(ftype.Results)
+	// 返回值使用 rN 命名，参数名不能与其相同
+	resultNames := make(map[string]bool)
+	for i := range results {
+		resultNames[fmt.Sprintf("r%d", i)] = true
+	}
+	used := make(map[string]bool)
+	for _, n := range names {
+		used[n] = true
+	}
+	for i, expr := range params {
+		typ := exprString(expr)
+		if i == 0 && typ == "context.Context" {
+			res.hasContext = true
+			continue
+		}
+		// 沿用接口中的参数名，没有参数名或与生成的代码冲突时使用 aN
+		paramName := names[i]
+		if paramName == "" || paramName == "_" ||
+			_gReservedNames[paramName] || resultNames[paramName] {
+			for n := len(res.params); ; n++ {
+				paramName = fmt.Sprintf("a%d", n)
+				if !used[paramName] && !resultNames[paramName] {
+					break
+				}
+			}
+			used[paramName] = true
+		}
+		f, err := newField(name, paramName, typ)
+		if err != nil {
+			return nil, err
+		}
+		res.params = append(res.params, f)
+	}
+	for i, expr := range results {
+		typ := exprString(expr)
+		if i == len(results)-1 && typ == "error" {
+			res.returnErr = true
+			continue
+		}
+		f, err := newField(name, fmt.Sprintf("r%d", i), typ)
+		if err != nil {
+			return nil, err
+		}
+		res.results = append(res.results, f)
+	}
+	return res, nil
+}
+
+func newField(funcName string, name string, typ string) (*field, error) {
+	m, ok := _gTypeMethods[typ]
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported type %s", funcName, typ)
+	}
+	return &field{
+		name:   name,
+		typ:    typ,
+		method: m,
+	}, nil
+}
+
+// 将参数列表展开为每个参数一项，同时返回每个参数的名称，没有名称时为空
+func flattenFields(list *ast.FieldList) ([]ast.Expr, []string) {
+	res := make([]ast.Expr, 0)
+	names := make([]string, 0)
+	if list == nil {
+		return res, names
+	}
+	for _, f := range list.List {
+		if len(f.Names) == 0 {
+			res = append(res, f.Type)
+			names = append(names, "")
+			continue
+		}
+		for _, ident := range f.Names {
+			res = append(res, f.Type)
+			names = append(names, ident.Name)
+		}
+	}
+	return res, names
+}
+
+func exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	format.Node(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
+
+// 生成代码
+func generate(pkg string, typeName string, methods []*method) ([]byte,
+	error) {
+	var buf bytes.Buffer
+	p := func(format string, args ...interface{}) {
+		fmt.Fprintf(&buf, format+"\n", args...)
+	}
+	needContext := false
+	for _, m := range methods {
+		if m.hasContext {
+			needContext = true
+		}
+	}
+
+	p("// Code generated by rocgen. DO NOT EDIT.")
+	p("")
+	p("package %s", pkg)
+	p("")
+	p("import (")
+	if needContext {
+		p("\"context\"")
+		p("")
+	}
+	p("\"github.com/liasece/micserver/roc\"")
+	// 没有方法的接口不需要编解码参数
+	if len(methods) > 0 {
+		p("\"github.com/liasece/micserver/rocutil/codec\"")
+	}
+	p(")")
+	p("")
+
+	client := typeName + "ROCClient"
+	p("// %s 的ROC客户端", typeName)
+	p("type %s struct {", client)
+	p("server roc.IROCServer")
+	p("objType roc.ROCObjType")
+	p("objID string")
+	p("}")
+	p("")
+	p("// 构造一个调用目标ROC对象的 %s", client)
+	p("func New%s(server roc.IROCServer, objType roc.ROCObjType, "+
+		"objID string) *%s {", client, client)
+	p("return &%s{server: server, objType: objType, objID: objID}", client)
+	p("}")
+	for _, m := range methods {
+		p("")
+		genClientMethod(p, client, m)
+	}
+
+	adapter := typeName + "ROCAdapter"
+	p("")
+	p("// %s 的ROC服务端适配器，实现了 roc.IObj 接口", typeName)
+	p("type %s struct {", adapter)
+	p("Impl %s", typeName)
+	p("ObjType roc.ROCObjType")
+	p("ObjID string")
+	p("}")
+	p("")
+	p("// 构造一个将ROC调用转发给 impl 的 %s", adapter)
+	p("func New%s(impl %s, objType roc.ROCObjType, objID string) *%s {",
+		adapter, typeName, adapter)
+	p("return &%s{Impl: impl, ObjType: objType, ObjID: objID}", adapter)
+	p("}")
+	p("")
+	p("// 获取ROC对象的类型")
+	p("func (this *%s) GetROCObjType() roc.ROCObjType {", adapter)
+	p("return this.ObjType")
+	p("}")
+	p("")
+	p("// 获取ROC对象的ID")
+	p("func (this *%s) GetROCObjID() string {", adapter)
+	p("return this.ObjID")
+	p("}")
+	p("")
+	p("// 受到ROC调用时调用")
+	p("func (this *%s) OnROCCall(path *roc.ROCPath, arg []byte) ([]byte, "+
+		"error) {", adapter)
+	if len(methods) > 0 {
+		p("r := codec.NewBinaryReader(arg)")
+	}
+	p("switch funcName := path.Move(); funcName {")
+	for _, m := range methods {
+		genAdapterCase(p, m)
+	}
+	p("default:")
+	p("return nil, roc.NewError(roc.ErrCodeUnknownFunc, " +
+		"\"unknown function name:\"+funcName)")
+	p("}")
+	p("}")
+
+	return format.Source(buf.Bytes())
+}
+
+// 生成客户端方法
+func genClientMethod(p func(string, ...interface{}), client string,
+	m *method) {
+	params := make([]string, 0)
+	if m.hasContext {
+		params = append(params, "ctx context.Context")
+	}
+	for _, f := range m.params {
+		params = append(params, f.name+" "+f.typ)
+	}
+	results := make([]string, 0)
+	for _, f := range m.results {
+		results = append(results, f.name+" "+f.typ)
+	}
+	results = append(results, "err error")
+
+	p("// 调用目标ROC对象的 %s 方法", m.name)
+	p("func (this *%s) %s(%s) (%s) {", client, m.name,
+		strings.Join(params, ", "), strings.Join(results, ", "))
+	p("w := &codec.BinaryWriter{}")
+	for _, f := range m.params {
+		p("w.Write%s(%s)", f.method, f.name)
+	}
+	p("path := roc.O(this.objType, this.objID).F(%q)", m.name)
+	if len(m.results) == 0 && !m.returnErr {
+		if m.hasContext {
+			p("err = this.server.ROCCallNRContext(ctx, path, w.Bytes())")
+		} else {
+			p("err = this.server.ROCCallNR(path, w.Bytes())")
+		}
+		p("return")
+		p("}")
+		return
+	}
+	call := "this.server.ROCCallBlock(path, w.Bytes())"
+	if m.hasContext {
+		call = "this.server.ROCCallContext(ctx, path, w.Bytes())"
+	}
+	if len(m.results) == 0 {
+		p("_, err = %s", call)
+		p("return")
+		p("}")
+		return
+	}
+	p("res, err := %s", call)
+	p("if err != nil {")
+	p("return")
+	p("}")
+	p("r := codec.NewBinaryReader(res)")
+	for _, f := range m.results {
+		p("%s = r.Read%s()", f.name, f.method)
+	}
+	p("err = r.Err()")
+	p("return")
+	p("}")
+}
+
+// 生成服务端适配器中一个方法的分支
+func genAdapterCase(p func(string, ...interface{}), m *method) {
+	p("case %q:", m.name)
+	args := make([]string, 0)
+	if m.hasContext {
+		args = append(args, "path.Context()")
+	}
+	for _, f := range m.params {
+		p("%s := r.Read%s()", f.name, f.method)
+		args = append(args, f.name)
+	}
+	p("if err := r.Err(); err != nil {")
+	p("return nil, err")
+	p("}")
+	results := make([]string, 0)
+	for _, f := range m.results {
+		results = append(results, f.name)
+	}
+	if m.returnErr {
+		results = append(results, "err")
+	}
+	call := fmt.Sprintf("this.Impl.%s(%s)", m.name, strings.Join(args, ", "))
+	if len(results) == 0 {
+		p("%s", call)
+		p("return nil, nil")
+		return
+	}
+	p("%s := %s", strings.Join(results, ", "), call)
+	if m.returnErr {
+		p("if err != nil {")
+		p("return nil, err")
+		p("}")
+	}
+	if len(m.results) == 0 {
+		p("return nil, nil")
+		return
+	}
+	p("w := &codec.BinaryWriter{}")
+	for _, f := range m.results {
+		p("w.Write%s(%s)", f.method, f.name)
+	}
+	p("return w.Bytes(), nil")
+}
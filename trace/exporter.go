@@ -0,0 +1,87 @@
+package trace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 一个已结束的片段的数据
+type SpanData struct {
+	TraceID  string            `json:"trace_id"`
+	SpanID   string            `json:"span_id"`
+	ParentID string            `json:"parent_id,omitempty"`
+	Name     string            `json:"name"`
+	ModuleID string            `json:"module_id"`
+	Start    time.Time         `json:"start"`
+	Duration time.Duration     `json:"duration_ns"`
+	Attrs    map[string]string `json:"attrs,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// 片段导出器，ExportSpan 会在结束片段的协程中被调用，需要是并发安全的
+type Exporter interface {
+	ExportSpan(data *SpanData)
+}
+
+var (
+	_gExporter      Exporter
+	_gExporterMutex sync.RWMutex
+)
+
+// 设置本进程的片段导出器，为 nil 时不再导出片段，也不会开始新的调用链，
+// 但其他模块传递过来的追踪信息仍然会被继续传递
+func SetExporter(exporter Exporter) {
+	_gExporterMutex.Lock()
+	defer _gExporterMutex.Unlock()
+	_gExporter = exporter
+}
+
+// 获取本进程的片段导出器
+func GetExporter() Exporter {
+	_gExporterMutex.RLock()
+	defer _gExporterMutex.RUnlock()
+	return _gExporter
+}
+
+// 将片段以每行一个 JSON 对象的格式追加写入文件的导出器
+type FileExporter struct {
+	file  *os.File
+	mutex sync.Mutex
+}
+
+// 构造一个写入目标文件的 FileExporter ，文件不存在时创建
+func NewFileExporter(path string) (*FileExporter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND,
+		0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileExporter{
+		file: file,
+	}, nil
+}
+
+// 导出一个片段
+func (this *FileExporter) ExportSpan(data *SpanData) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	b = append(b, '\n')
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.file.Write(b)
+}
+
+// 关闭文件
+func (this *FileExporter) Close() error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.file.Close()
+}
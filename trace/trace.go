@@ -0,0 +1,144 @@
+/*
+跨模块的调用链追踪，追踪信息随客户端消息、模块间消息及ROC调用在模块间传递，
+每个模块记录自己处理的部分，通过 SetExporter 设置的导出器输出，离线后可以根据
+TraceID 及 ParentID 重建完整的调用树。
+*/
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// 调用链中一个片段的标识
+type SpanContext struct {
+	TraceID string
+	SpanID  string
+}
+
+// 是否是有效的标识
+func (this SpanContext) IsValid() bool {
+	return this.TraceID != "" && this.SpanID != ""
+}
+
+type spanContextKey struct{}
+
+// 返回携带了目标片段标识的 context
+func ContextWithSpanContext(ctx context.Context,
+	sc SpanContext) context.Context {
+	if !sc.IsValid() {
+		return ctx
+	}
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// 获取 context 中携带的片段标识，不存在时返回无效的标识
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if ctx == nil {
+		return SpanContext{}
+	}
+	if sc, ok := ctx.Value(spanContextKey{}).(SpanContext); ok {
+		return sc
+	}
+	return SpanContext{}
+}
+
+// 调用链中的一个片段，记录了一次处理的开始时间及耗时，
+// 为 nil 时所有方法都不做任何事
+type Span struct {
+	data  SpanData
+	mutex sync.Mutex
+	ended bool
+}
+
+// 开始一个片段，ctx 中携带了片段标识时作为其子片段，否则在设置了导出器时开始一个
+// 新的调用链，都不满足时返回 nil 及原本的 ctx 。
+// 返回的 ctx 携带了新片段的标识
+func StartSpan(ctx context.Context, moduleID string,
+	name string) (context.Context, *Span) {
+	return StartSpanWithParent(ctx, moduleID, name,
+		SpanContextFromContext(ctx))
+}
+
+// 同 StartSpan ，使用其他模块传递过来的父片段标识，例如消息中携带的 TraceID
+// 及 SpanID
+func StartSpanWithParent(ctx context.Context, moduleID string, name string,
+	parent SpanContext) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	traceID := parent.TraceID
+	if !parent.IsValid() {
+		if GetExporter() == nil {
+			return ctx, nil
+		}
+		traceID = newID(16)
+	}
+	span := &Span{
+		data: SpanData{
+			TraceID:  traceID,
+			SpanID:   newID(8),
+			ParentID: parent.SpanID,
+			Name:     name,
+			ModuleID: moduleID,
+			Start:    time.Now(),
+		},
+	}
+	return ContextWithSpanContext(ctx, span.SpanContext()), span
+}
+
+// 获取片段的标识
+func (this *Span) SpanContext() SpanContext {
+	if this == nil {
+		return SpanContext{}
+	}
+	return SpanContext{
+		TraceID: this.data.TraceID,
+		SpanID:  this.data.SpanID,
+	}
+}
+
+// 为片段添加一个属性
+func (this *Span) SetAttr(key string, value string) {
+	if this == nil {
+		return
+	}
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.data.Attrs == nil {
+		this.data.Attrs = make(map[string]string)
+	}
+	this.data.Attrs[key] = value
+}
+
+// 结束片段并导出，err 不为 nil 时记录为片段的错误，重复调用无效
+func (this *Span) Finish(err error) {
+	if this == nil {
+		return
+	}
+	this.mutex.Lock()
+	if this.ended {
+		this.mutex.Unlock()
+		return
+	}
+	this.ended = true
+	this.data.Duration = time.Since(this.data.Start)
+	if err != nil {
+		this.data.Error = err.Error()
+	}
+	data := this.data
+	this.mutex.Unlock()
+	if exporter := GetExporter(); exporter != nil {
+		exporter.ExportSpan(&data)
+	}
+}
+
+// 生成 n 字节的随机ID
+func newID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,146 @@
+package hash
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// 一致性哈希环的默认虚拟节点数
+const DefaultRingReplicas = 160
+
+// 带虚拟节点的一致性哈希环，节点增减时只有少量的键会改变所属的节点，
+// 可以并发使用
+type Ring struct {
+	replicas int
+	// 已排序的虚拟节点的哈希值，以及虚拟节点所属的节点
+	hashes []uint32
+	owners map[uint32]string
+	nodes  map[string]struct{}
+	mutex  sync.RWMutex
+}
+
+// 构造一个一致性哈希环，每个节点有 replicas 个虚拟节点，不大于0时为
+// DefaultRingReplicas
+func NewRing(replicas int) *Ring {
+	if replicas <= 0 {
+		replicas = DefaultRingReplicas
+	}
+	return &Ring{
+		replicas: replicas,
+		owners:   make(map[uint32]string),
+		nodes:    make(map[string]struct{}),
+	}
+}
+
+// 虚拟节点的哈希值
+func (this *Ring) vnodeHash(node string, i int) uint32 {
+	return GetStringHash(node + "#" + strconv.Itoa(i))
+}
+
+// 添加节点，返回是否有节点被添加
+func (this *Ring) Add(nodes ...string) bool {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	changed := false
+	for _, node := range nodes {
+		if _, ok := this.nodes[node]; ok {
+			continue
+		}
+		this.nodes[node] = struct{}{}
+		for i := 0; i < this.replicas; i++ {
+			h := this.vnodeHash(node, i)
+			// 哈希冲突时由较小的节点取得该虚拟节点，与节点加入的顺序无关
+			if owner, ok := this.owners[h]; ok {
+				if node < owner {
+					this.owners[h] = node
+				}
+				continue
+			}
+			this.owners[h] = node
+			this.hashes = append(this.hashes, h)
+		}
+		changed = true
+	}
+	if changed {
+		sort.Slice(this.hashes, func(i, j int) bool {
+			return this.hashes[i] < this.hashes[j]
+		})
+	}
+	return changed
+}
+
+// 删除节点，返回是否有节点被删除
+func (this *Ring) Remove(nodes ...string) bool {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	changed := false
+	for _, node := range nodes {
+		if _, ok := this.nodes[node]; !ok {
+			continue
+		}
+		delete(this.nodes, node)
+		changed = true
+	}
+	if !changed {
+		return false
+	}
+	// 重建剩余节点的虚拟节点，保证冲突的虚拟节点归属于剩余节点中较小的节点
+	this.hashes = this.hashes[:0]
+	this.owners = make(map[uint32]string)
+	for node := range this.nodes {
+		for i := 0; i < this.replicas; i++ {
+			h := this.vnodeHash(node, i)
+			if owner, ok := this.owners[h]; ok {
+				if node < owner {
+					this.owners[h] = node
+				}
+				continue
+			}
+			this.owners[h] = node
+			this.hashes = append(this.hashes, h)
+		}
+	}
+	sort.Slice(this.hashes, func(i, j int) bool {
+		return this.hashes[i] < this.hashes[j]
+	})
+	return true
+}
+
+// 获取键所属的节点，即哈希环上顺时针方向第一个虚拟节点所属的节点，
+// 环为空时返回空字符串
+func (this *Ring) Get(key string) string {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	if len(this.hashes) == 0 {
+		return ""
+	}
+	h := GetStringHash(key)
+	i := sort.Search(len(this.hashes), func(i int) bool {
+		return this.hashes[i] >= h
+	})
+	if i == len(this.hashes) {
+		i = 0
+	}
+	return this.owners[this.hashes[i]]
+}
+
+// 判断节点是否在环中
+func (this *Ring) Has(node string) bool {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	_, ok := this.nodes[node]
+	return ok
+}
+
+// 获取环中所有的节点，按节点名排序
+func (this *Ring) Nodes() []string {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	res := make([]string, 0, len(this.nodes))
+	for node := range this.nodes {
+		res = append(res, node)
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,130 @@
+package hash
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestRingEmpty(t *testing.T) {
+	r := NewRing(0)
+	if got := r.Get("key"); got != "" {
+		t.Fatalf("empty ring Get = %q, want empty", got)
+	}
+	if r.Remove("a") {
+		t.Fatalf("Remove on empty ring reports changed")
+	}
+	r.Add("a")
+	r.Remove("a")
+	if got := r.Get("key"); got != "" {
+		t.Fatalf("ring Get after removing all nodes = %q, want empty", got)
+	}
+}
+
+func TestRingAddRemove(t *testing.T) {
+	tests := []struct {
+		name    string
+		add     []string
+		remove  []string
+		changed bool
+		nodes   []string
+	}{
+		{"add", []string{"b", "a"}, nil, true, []string{"a", "b"}},
+		{"add dup", []string{"a", "a"}, nil, true, []string{"a"}},
+		{"remove", []string{"a", "b"}, []string{"a"}, true, []string{"b"}},
+		{"remove missing", []string{"a"}, []string{"c"}, false,
+			[]string{"a"}},
+		{"remove empty node", []string{"a"}, []string{""}, false,
+			[]string{"a"}},
+	}
+	for _, tt := range tests {
+		r := NewRing(10)
+		r.Add(tt.add...)
+		changed := r.Remove(tt.remove...)
+		if tt.remove != nil && changed != tt.changed {
+			t.Errorf("%s: Remove changed = %v, want %v", tt.name, changed,
+				tt.changed)
+		}
+		if !reflect.DeepEqual(r.Nodes(), tt.nodes) {
+			t.Errorf("%s: Nodes = %q, want %q", tt.name, r.Nodes(), tt.nodes)
+		}
+		for _, n := range tt.nodes {
+			if !r.Has(n) {
+				t.Errorf("%s: Has(%q) = false", tt.name, n)
+			}
+		}
+	}
+}
+
+func TestRingPlacement(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas int
+		nodes    []string
+	}{
+		{"one node", 0, []string{"gate1"}},
+		{"default replicas", 0, []string{"gate1", "gate2", "gate3"}},
+		{"few replicas", 1, []string{"gate1", "gate2", "gate3", "gate4"}},
+	}
+	for _, tt := range tests {
+		// 节点加入的顺序不影响键的归属
+		r1 := NewRing(tt.replicas)
+		r1.Add(tt.nodes...)
+		r2 := NewRing(tt.replicas)
+		for i := len(tt.nodes) - 1; i >= 0; i-- {
+			r2.Add(tt.nodes[i])
+		}
+		// 先加入再移除的节点不影响键的归属
+		r3 := NewRing(tt.replicas)
+		r3.Add(append([]string{"extra"}, tt.nodes...)...)
+		r3.Remove("extra")
+		for i := 0; i < 1000; i++ {
+			key := fmt.Sprintf("obj%d", i)
+			got := r1.Get(key)
+			if !r1.Has(got) {
+				t.Fatalf("%s: Get(%q) = %q, not a node", tt.name, key, got)
+			}
+			if r2.Get(key) != got || r3.Get(key) != got {
+				t.Fatalf("%s: Get(%q) = %q/%q/%q, want same node", tt.name,
+					key, got, r2.Get(key), r3.Get(key))
+			}
+		}
+	}
+}
+
+func TestRingMinimalMovement(t *testing.T) {
+	r := NewRing(0)
+	r.Add("gate1", "gate2", "gate3")
+	before := make(map[string]string)
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("obj%d", i)
+		before[key] = r.Get(key)
+	}
+	// 加入节点时，只有移动到新节点的键改变归属
+	r.Add("gate4")
+	moved := 0
+	for key, node := range before {
+		got := r.Get(key)
+		if got != node {
+			if got != "gate4" {
+				t.Fatalf("Get(%q) moved from %q to %q", key, node, got)
+			}
+			moved++
+		}
+	}
+	if moved == 0 || moved > 500 {
+		t.Fatalf("%d of 1000 keys moved to new node", moved)
+	}
+	// 移除节点时，只有原本属于该节点的键改变归属
+	r.Remove("gate4")
+	r.Remove("gate2")
+	for key, node := range before {
+		got := r.Get(key)
+		if node != "gate2" && got != node {
+			t.Fatalf("Get(%q) moved from %q to %q", key, node, got)
+		}
+		if got == "gate2" {
+			t.Fatalf("Get(%q) = removed node", key)
+		}
+	}
+}
@@ -81,9 +81,23 @@ type TimerManager struct {
 	timerList            sync.Map
 	timeTriggerChan      chan *Timer
 	timeTriggerChanMutex sync.Mutex
+	execChan             chan func()
 	hasKilled            bool
 }
 
+// 定时器协程初始化的检查
+func (this *TimerManager) checkInit() {
+	if this.timeTriggerChan == nil {
+		this.timeTriggerChanMutex.Lock()
+		if this.timeTriggerChan == nil {
+			this.execChan = make(chan func(), 100)
+			this.timeTriggerChan = make(chan *Timer, 100)
+			go this.goSelectTimer()
+		}
+		this.timeTriggerChanMutex.Unlock()
+	}
+}
+
 // limitTimes 限制了这个定时任务重复执行的次数，如果为 0 ，那么将不限制
 // 其执行的次数。
 // 如果 engross 为 true，那么这个 timer 的执行将独占一个协程
@@ -96,15 +110,7 @@ func (this *TimerManager) RegTimer(duration time.Duration, limitTimes int64,
 		timeDuration: duration,
 		limitTimes:   limitTimes,
 	}
-	// 定时器初始化的检查
-	if this.timeTriggerChan == nil {
-		this.timeTriggerChanMutex.Lock()
-		if this.timeTriggerChan == nil {
-			this.timeTriggerChan = make(chan *Timer, 100)
-			go this.goSelectTimer()
-		}
-		this.timeTriggerChanMutex.Unlock()
-	}
+	this.checkInit()
 	// 注册定时器
 	resi, _ := this.timerList.LoadOrStore(duration, make([]*Timer, 0))
 	if res, ok := resi.([]*Timer); ok {
@@ -131,10 +137,21 @@ func (this *TimerManager) KillRegister() {
 		}
 		return true
 	})
+	this.checkInit()
 	this.hasKilled = true
 	this.timeTriggerChan <- nil
 }
 
+// 在定时器协程中执行 f ，与非独占的定时器回调串行执行，可以作为
+// roc.Executor 接收ROC异步调用的回调。管理器关闭后 f 将不会被执行
+func (this *TimerManager) Execute(f func()) {
+	if this.hasKilled {
+		return
+	}
+	this.checkInit()
+	this.execChan <- f
+}
+
 func (this *TimerManager) goSelectTimer() {
 	for !this.hasKilled {
 		select {
@@ -142,6 +159,8 @@ func (this *TimerManager) goSelectTimer() {
 			if t != nil {
 				t.cb(t.timeDuration)
 			}
+		case f := <-this.execChan:
+			f()
 		}
 	}
 }